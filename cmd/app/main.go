@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
@@ -8,6 +9,7 @@ import (
 
 	"sport-manager/internal/auth"
 	"sport-manager/internal/handler"
+	"sport-manager/internal/mailer"
 	"sport-manager/internal/repository"
 	"sport-manager/internal/service"
 	"sport-manager/pkg/config"
@@ -40,63 +42,281 @@ func main() {
 	}
 	defer db.Close()
 
+	// Ключи подписи JWT (несколько ключей, выбор по kid)
+	keySet, err := auth.LoadKeySet(cfg)
+	if err != nil {
+		log.Fatalf("Ошибка загрузки ключей JWT: %v", err)
+	}
+
+	// Режим сессии веб-интерфейса: токены в теле ответа (bearer) или в HttpOnly-cookie
+	sessionCookies, err := auth.NewSessionCookies(cfg)
+	if err != nil {
+		log.Fatalf("Ошибка настройки сессий: %v", err)
+	}
+
+	// Почта для подтверждения email и сброса пароля
+	mail, err := mailer.New(cfg)
+	if err != nil {
+		log.Fatalf("Ошибка настройки почты: %v", err)
+	}
+
 	// 3. ИНИЦИАЛИЗАЦИЯ СЛОЕВ (Dependency Injection)
 	// Инициализируем репозитории (работа с БД)
 	authRepo := repository.NewAuthRepository(db)
+	tokenRepo := repository.NewTokenRepository(db)
 	athleteRepo := repository.NewAthleteRepository(db)
 	competitionRepo := repository.NewCompetitionRepository(db)
 	participationRepo := repository.NewParticipationRepository(db)
+	resultRepo := repository.NewResultRepository(db)
+	rankRepo := repository.NewRankRepository(db)
+	entryStandardRepo := repository.NewEntryStandardRepository(db)
+	clubRepo := repository.NewClubRepository(db)
+	startListRepo := repository.NewStartListRepository(db)
+	drawRepo := repository.NewDrawRepository(db)
+	weighInRepo := repository.NewWeighInRepository(db)
+	roleRepo := repository.NewRoleRepository(db)
+	loginAttemptRepo := repository.NewLoginAttemptRepository(db)
+	auditRepo := repository.NewAuditRepository(db)
+	mfaRepo := repository.NewMFARepository(db)
+	settingsRepo := repository.NewSettingsRepository(db)
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
+	identityRepo := repository.NewIdentityRepository(db)
+	versionRepo := repository.NewVersionRepository(db)
+	searchRepo := repository.NewSearchRepository(db)
 
 	// Инициализируем сервисы (бизнес-логика)
-	authService := service.NewAuthService(authRepo, cfg)
+	roleService := service.NewRoleService(roleRepo, authRepo)
+	accountMailer := service.NewAccountMailer(mail, tokenRepo, cfg.AppBaseURL)
+	// LoginGuard считает неудачные входы и временно блокирует перебор паролей
+	loginGuard := service.NewLoginGuard(loginAttemptRepo, auditRepo, cfg)
+	// MFAService проверяет коды TOTP и политику обязательной 2FA для администраторов и организаторов
+	mfaService := service.NewMFAService(mfaRepo, authRepo, settingsRepo, roleService, auditRepo)
+	// Вход через провайдера федерации (OpenID Connect) работает наряду со входом по паролю
+	oidcProvider := auth.NewOIDCProvider(cfg)
+	authService := service.NewAuthService(
+		authRepo, tokenRepo, roleService, accountMailer, loginGuard, mfaService, oidcProvider, identityRepo, keySet, cfg,
+	)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo, auditRepo)
+	// Журнал аудита пишут репозитории в транзакциях изменений, сервис только читает и проверяет его
+	auditService := service.NewAuditService(auditRepo)
+	historyService := service.NewHistoryService(versionRepo)
+	searchService := service.NewSearchService(searchRepo)
+	userService := service.NewUserService(authRepo, tokenRepo, roleService, accountMailer, loginGuard)
 	athleteService := service.NewAthleteService(athleteRepo)
-	competitionService := service.NewCompetitionService(competitionRepo)
-	// ParticipationService зависит от нескольких репозиториев для проверки существования записей
-	participationService := service.NewParticipationService(participationRepo, athleteRepo, competitionRepo)
+	competitionService := service.NewCompetitionService(competitionRepo, entryStandardRepo)
+	// ParticipationService зависит от нескольких репозиториев для проверки существования записей,
+	// отборочных нормативов и подтверждения заявочных результатов
+	participationService := service.NewParticipationService(
+		participationRepo, athleteRepo, competitionRepo, entryStandardRepo, resultRepo, weighInRepo,
+	)
+	// ResultService после сохранения результата проверяет разрядные нормативы
+	resultService := service.NewResultService(resultRepo, rankRepo)
+	rankService := service.NewRankService(rankRepo)
+	clubService := service.NewClubService(clubRepo)
+	drawService := service.NewDrawService(drawRepo, competitionRepo)
+	startListService := service.NewStartListService(startListRepo, competitionRepo, entryStandardRepo, drawService)
+	weighInService := service.NewWeighInService(weighInRepo, participationRepo, competitionRepo)
+
+	// Окончательное удаление записей, мягко удаленных дольше срока хранения
+	if cfg.PurgeInterval > 0 {
+		purgeJob := service.NewPurgeJob(participationRepo, athleteRepo, competitionRepo, cfg.SoftDeleteRetention)
+		go purgeJob.Run(context.Background(), cfg.PurgeInterval)
+	}
 
 	// Инициализируем хендлеры (обработка HTTP запросов)
-	authHandler := handler.NewAuthHandler(authService)
+	authHandler := handler.NewAuthHandler(authService, sessionCookies)
 	athleteHandler := handler.NewAthleteHandler(athleteService)
 	competitionHandler := handler.NewCompetitionHandler(competitionService)
 	participationHandler := handler.NewParticipationHandler(participationService)
+	resultHandler := handler.NewResultHandler(resultService)
+	rankHandler := handler.NewRankHandler(rankService)
+	clubHandler := handler.NewClubHandler(clubService)
+	startListHandler := handler.NewStartListHandler(startListService)
+	drawHandler := handler.NewDrawHandler(drawService)
+	weighInHandler := handler.NewWeighInHandler(weighInService)
+	roleHandler := handler.NewRoleHandler(roleService)
+	userHandler := handler.NewUserHandler(userService)
+	mfaHandler := handler.NewMFAHandler(mfaService)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyService)
+	auditHandler := handler.NewAuditHandler(auditService)
+	historyHandler := handler.NewHistoryHandler(historyService)
+	searchHandler := handler.NewSearchHandler(searchService)
 
 	// 4. НАСТРОЙКА РОУТИНГА
 	router := mux.NewRouter()
 
+	// Публичные ключи для локальной проверки токенов другими сервисами
+	router.HandleFunc("/.well-known/jwks.json", authHandler.JWKS).Methods("GET")
+
 	// Группа для API v1
 	api := router.PathPrefix("/api/v1").Subrouter()
+	// IP клиента для журнала аудита (на защищенных маршрутах AuthMiddleware добавит автора)
+	api.Use(auth.AuditContext)
 
 	// --- Публичные маршруты ---
 	api.HandleFunc("/auth/login", authHandler.Login).Methods("POST")
+	api.HandleFunc("/auth/login/2fa", authHandler.LoginSecondFactor).Methods("POST")
+	api.HandleFunc("/auth/oidc/login", authHandler.OIDCLogin).Methods("GET")
+	api.HandleFunc("/auth/oidc/callback", authHandler.OIDCCallback).Methods("GET")
+	api.HandleFunc("/auth/oidc/token", authHandler.ExchangeOIDCCode).Methods("POST")
 	api.HandleFunc("/auth/register", authHandler.Register).Methods("POST")
+	api.HandleFunc("/auth/refresh", authHandler.Refresh).Methods("POST")
+	api.HandleFunc("/auth/verify-email", authHandler.VerifyEmail).Methods("GET", "POST")
+	api.HandleFunc("/auth/password-reset", authHandler.RequestPasswordReset).Methods("POST")
+	api.HandleFunc("/auth/password-reset/confirm", authHandler.ConfirmPasswordReset).Methods("POST")
 	api.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("OK"))
 	}).Methods("GET")
 
-	// --- Защищенные маршруты (JWT Middleware) ---
+	// --- Защищенные маршруты (JWT в заголовке или cookie, либо API-ключ в X-API-Key) ---
 	protected := api.PathPrefix("").Subrouter()
-	protected.Use(auth.AuthMiddleware(cfg))
+	protected.Use(auth.AuthMiddleware(keySet, tokenRepo, apiKeyRepo))
+	// Пока пароль не сменен (например, у администратора из начальной миграции) или не подключена
+	// обязательная 2FA, доступны только эти пути
+	protected.Use(auth.RequireAccountSetup(
+		"/api/v1/me", "/api/v1/me/password", "/api/v1/auth/logout",
+		"/api/v1/me/2fa/enroll", "/api/v1/me/2fa/confirm",
+	))
+
+	protected.HandleFunc("/auth/logout", authHandler.Logout).Methods("POST")
+
+	// Личный профиль
+	protected.HandleFunc("/me", userHandler.GetMe).Methods("GET")
+	protected.HandleFunc("/me", userHandler.UpdateMe).Methods("PUT")
+	protected.HandleFunc("/me/password", authHandler.ChangePassword).Methods("POST")
+	protected.HandleFunc("/me/verify-email", authHandler.ResendVerification).Methods("POST")
+
+	// Двухфакторная аутентификация (TOTP)
+	protected.HandleFunc("/me/2fa/enroll", mfaHandler.Enroll).Methods("POST")
+	protected.HandleFunc("/me/2fa/confirm", mfaHandler.Confirm).Methods("POST")
+	protected.HandleFunc("/me/2fa", mfaHandler.Disable).Methods("DELETE")
+	protected.HandleFunc("/me/2fa/recovery-codes", mfaHandler.RegenerateRecoveryCodes).Methods("POST")
+
+	// Привязка входа через провайдера OIDC
+	protected.HandleFunc("/me/oidc/link", authHandler.LinkOIDC).Methods("POST")
+	protected.HandleFunc("/me/identities", authHandler.ListIdentities).Methods("GET")
+	protected.HandleFunc("/me/identities/{id}", authHandler.UnlinkIdentity).Methods("DELETE")
+
+	// Права проверяются в области ресурса: соревнования (по ID в пути или в теле) или клуба спортсмена
+	require := auth.RequirePermission
+	scopes := auth.NewScopeResolvers(participationRepo, athleteRepo)
+	byCompetition := auth.CompetitionFromPath("id")
+
+	// Учетные записи (только администратор)
+	protected.HandleFunc("/users", require(auth.PermUserManage, nil)(userHandler.ListUsers)).Methods("GET")
+	protected.HandleFunc("/users/{id}", require(auth.PermUserManage, nil)(userHandler.GetUser)).Methods("GET")
+	protected.HandleFunc("/users/{id}", require(auth.PermUserManage, nil)(userHandler.UpdateUser)).Methods("PUT")
+	protected.HandleFunc("/users/{id}", require(auth.PermUserManage, nil)(userHandler.DeleteUser)).Methods("DELETE")
+	protected.HandleFunc("/users/{id}/password", require(auth.PermUserManage, nil)(userHandler.ResetPassword)).Methods("POST")
+	protected.HandleFunc("/users/{id}/unlock", require(auth.PermUserManage, nil)(userHandler.UnlockUser)).Methods("POST")
+	protected.HandleFunc("/lockouts", require(auth.PermUserManage, nil)(userHandler.ListLockouts)).Methods("GET")
+	protected.HandleFunc("/lockouts", require(auth.PermUserManage, nil)(userHandler.ClearLockout)).Methods("DELETE")
+	protected.HandleFunc("/users/{id}/2fa", require(auth.PermUserManage, nil)(mfaHandler.ResetUser)).Methods("DELETE")
+	protected.HandleFunc("/security/settings", require(auth.PermUserManage, nil)(mfaHandler.GetSecuritySettings)).Methods("GET")
+	protected.HandleFunc("/security/settings", require(auth.PermUserManage, nil)(mfaHandler.UpdateSecuritySettings)).Methods("PUT")
+
+	// API-ключи для хронометража и табло (только администратор)
+	protected.HandleFunc("/api-keys", require(auth.PermUserManage, nil)(apiKeyHandler.ListKeys)).Methods("GET")
+	protected.HandleFunc("/api-keys", require(auth.PermUserManage, nil)(apiKeyHandler.CreateKey)).Methods("POST")
+	protected.HandleFunc("/api-keys/{id}", require(auth.PermUserManage, nil)(apiKeyHandler.RevokeKey)).Methods("DELETE")
+
+	// Журнал аудита изменений (только администратор)
+	protected.HandleFunc("/audit", require(auth.PermUserManage, nil)(auditHandler.ListAudit)).Methods("GET")
+	protected.HandleFunc("/audit/verify", require(auth.PermUserManage, nil)(auditHandler.VerifyAudit)).Methods("GET")
+
+	// Роли пользователей: права на выдачу проверяет RoleService
+	protected.HandleFunc("/users/{id}/roles", roleHandler.ListRoles).Methods("GET")
+	protected.HandleFunc("/users/{id}/roles", roleHandler.AssignRole).Methods("POST")
+	protected.HandleFunc("/users/{id}/roles/{roleId}", roleHandler.RevokeRole).Methods("DELETE")
+
+	// Поиск по спортсменам, соревнованиям и клубам (подсказки при вводе)
+	protected.HandleFunc("/search", searchHandler.Search).Methods("GET")
 
 	// Спортсмены
 	protected.HandleFunc("/athletes", athleteHandler.ListAllAthletes).Methods("GET")
 	protected.HandleFunc("/athletes/{id}", athleteHandler.GetAthleteByID).Methods("GET")
-	protected.HandleFunc("/athletes", auth.AdminOnly(athleteHandler.CreateAthlete)).Methods("POST")
-	protected.HandleFunc("/athletes/{id}", auth.AdminOnly(athleteHandler.UpdateAthlete)).Methods("PUT")
-	protected.HandleFunc("/athletes/{id}", auth.AdminOnly(athleteHandler.DeleteAthlete)).Methods("DELETE")
+	protected.HandleFunc("/athletes", require(auth.PermAthleteEdit, auth.ClubFromBody("club_id"))(athleteHandler.CreateAthlete)).Methods("POST")
+	// Изменение проверяется и в текущем клубе спортсмена, и в клубе, где он окажется после изменения
+	protected.HandleFunc("/athletes/{id}", require(auth.PermAthleteEdit, scopes.Athlete("id"))(
+		require(auth.PermAthleteEdit, auth.ClubFromBody("club_id"))(athleteHandler.UpdateAthlete))).Methods("PUT")
+	protected.HandleFunc("/athletes/{id}", require(auth.PermAthleteEdit, scopes.Athlete("id"))(
+		require(auth.PermAthleteEdit, scopes.PatchedAthlete("id"))(athleteHandler.PatchAthlete))).Methods("PATCH")
+	protected.HandleFunc("/athletes/{id}", require(auth.PermAthleteEdit, scopes.Athlete("id"))(athleteHandler.DeleteAthlete)).Methods("DELETE")
+	// История версий содержит личные данные (дату рождения, адрес)
+	protected.HandleFunc("/athletes/{id}/history", require(auth.PermAthleteViewPrivate, scopes.Athlete("id"))(historyHandler.AthleteHistory)).Methods("GET")
+	protected.HandleFunc("/athletes/{id}/history/diff", require(auth.PermAthleteViewPrivate, scopes.Athlete("id"))(historyHandler.AthleteDiff)).Methods("GET")
+	protected.HandleFunc("/athletes/{id}/restore", require(auth.PermAthleteEdit, scopes.Athlete("id"))(athleteHandler.RestoreAthlete)).Methods("POST")
+	// Окончательное удаление (вместе с участиями и результатами) — отдельное действие администратора
+	protected.HandleFunc("/athletes/{id}/purge", require(auth.PermUserManage, nil)(athleteHandler.PurgeAthlete)).Methods("DELETE")
 
 	// Соревнования
 	protected.HandleFunc("/competitions", competitionHandler.ListCompetitions).Methods("GET")
 	protected.HandleFunc("/competitions/{id}", competitionHandler.GetCompetition).Methods("GET")
-	protected.HandleFunc("/competitions", auth.AdminOnly(competitionHandler.CreateCompetition)).Methods("POST")
-	protected.HandleFunc("/competitions/{id}", auth.AdminOnly(competitionHandler.UpdateCompetition)).Methods("PUT")
-	protected.HandleFunc("/competitions/{id}", auth.AdminOnly(competitionHandler.DeleteCompetition)).Methods("DELETE")
+	protected.HandleFunc("/competitions", require(auth.PermCompetitionEdit, nil)(competitionHandler.CreateCompetition)).Methods("POST")
+	protected.HandleFunc("/competitions/{id}", require(auth.PermCompetitionEdit, byCompetition)(competitionHandler.UpdateCompetition)).Methods("PUT")
+	protected.HandleFunc("/competitions/{id}", require(auth.PermCompetitionEdit, byCompetition)(competitionHandler.PatchCompetition)).Methods("PATCH")
+	protected.HandleFunc("/competitions/{id}", require(auth.PermCompetitionEdit, byCompetition)(competitionHandler.DeleteCompetition)).Methods("DELETE")
+	protected.HandleFunc("/competitions/{id}/history", historyHandler.CompetitionHistory).Methods("GET")
+	protected.HandleFunc("/competitions/{id}/history/diff", historyHandler.CompetitionDiff).Methods("GET")
+	protected.HandleFunc("/competitions/{id}/restore", require(auth.PermCompetitionEdit, byCompetition)(competitionHandler.RestoreCompetition)).Methods("POST")
+	protected.HandleFunc("/competitions/{id}/purge", require(auth.PermUserManage, nil)(competitionHandler.PurgeCompetition)).Methods("DELETE")
+	protected.HandleFunc("/competitions/{id}/entry-standards", competitionHandler.ListEntryStandards).Methods("GET")
+	protected.HandleFunc("/competitions/{id}/entry-standards", require(auth.PermCompetitionEdit, byCompetition)(competitionHandler.CreateEntryStandard)).Methods("POST")
+	protected.HandleFunc("/competitions/{id}/entry-standards/{standardId}", require(auth.PermCompetitionEdit, byCompetition)(competitionHandler.DeleteEntryStandard)).Methods("DELETE")
+	protected.HandleFunc("/competitions/{id}/heats", participationHandler.SeedHeats).Methods("GET")
+
+	// Стартовые номера и стартовые протоколы
+	protected.HandleFunc("/competitions/{id}/bib-settings", startListHandler.GetBibSettings).Methods("GET")
+	protected.HandleFunc("/competitions/{id}/bib-settings", require(auth.PermCompetitionEdit, byCompetition)(startListHandler.SaveBibSettings)).Methods("PUT")
+	protected.HandleFunc("/competitions/{id}/bibs", require(auth.PermCompetitionEdit, byCompetition)(startListHandler.AssignBibs)).Methods("POST")
+	protected.HandleFunc("/competitions/{id}/start-list", startListHandler.GetStartList).Methods("GET")
+	protected.HandleFunc("/competitions/{id}/start-list", require(auth.PermCompetitionEdit, byCompetition)(startListHandler.GenerateStartList)).Methods("POST")
+
+	// Жеребьевки: хеш зерна публикуется заранее, результат может проверить любой пользователь
+	protected.HandleFunc("/competitions/{id}/draws", drawHandler.ListDraws).Methods("GET")
+	protected.HandleFunc("/competitions/{id}/draws", require(auth.PermCompetitionEdit, byCompetition)(drawHandler.CommitDraw)).Methods("POST")
+	protected.HandleFunc("/draws/replay", drawHandler.ReplayDraw).Methods("POST")
+	protected.HandleFunc("/draws/{id}", drawHandler.GetDraw).Methods("GET")
+	protected.HandleFunc("/draws/{id}/verify", drawHandler.VerifyDraw).Methods("GET")
+
+	// Весовые категории и взвешивание
+	protected.HandleFunc("/competitions/{id}/weight-classes", weighInHandler.ListClasses).Methods("GET")
+	protected.HandleFunc("/competitions/{id}/weight-classes", require(auth.PermCompetitionEdit, byCompetition)(weighInHandler.CreateClass)).Methods("POST")
+	protected.HandleFunc("/competitions/{id}/weight-classes/{classId}", require(auth.PermCompetitionEdit, byCompetition)(weighInHandler.DeleteClass)).Methods("DELETE")
+	protected.HandleFunc("/competitions/{id}/weigh-in-rules", weighInHandler.GetRules).Methods("GET")
+	protected.HandleFunc("/competitions/{id}/weigh-in-rules", require(auth.PermCompetitionEdit, byCompetition)(weighInHandler.SaveRules)).Methods("PUT")
+	protected.HandleFunc("/participations/{id}/weigh-ins", weighInHandler.ListWeighIns).Methods("GET")
+	protected.HandleFunc("/participations/{id}/weigh-ins", require(auth.PermWeighInRecord, scopes.Participation("id"))(weighInHandler.RecordWeighIn)).Methods("POST")
+
+	// Клубы
+	protected.HandleFunc("/clubs", clubHandler.ListClubs).Methods("GET")
+	protected.HandleFunc("/clubs", require(auth.PermClubEdit, nil)(clubHandler.CreateClub)).Methods("POST")
 
 	// Участие (регистрация атлетов на турниры)
 	protected.HandleFunc("/participations", participationHandler.ListParticipations).Methods("GET")
-	protected.HandleFunc("/participations", auth.AdminOnly(participationHandler.CreateParticipation)).Methods("POST")
-	protected.HandleFunc("/participations/{id}/place", auth.AdminOnly(participationHandler.UpdatePlace)).Methods("PUT")
-	protected.HandleFunc("/participations/{id}", auth.AdminOnly(participationHandler.DeleteParticipation)).Methods("DELETE")
+	protected.HandleFunc("/participations", require(auth.PermParticipationManage, scopes.ParticipationFromBody())(participationHandler.CreateParticipation)).Methods("POST")
+	protected.HandleFunc("/participations/{id}/place", require(auth.PermResultEnter, scopes.Participation("id"))(participationHandler.UpdatePlace)).Methods("PUT")
+	protected.HandleFunc("/participations/{id}", require(auth.PermParticipationManage, scopes.Participation("id"))(participationHandler.DeleteParticipation)).Methods("DELETE")
+	protected.HandleFunc("/participations/{id}/restore", require(auth.PermParticipationManage, scopes.Participation("id"))(participationHandler.RestoreParticipation)).Methods("POST")
+	protected.HandleFunc("/participations/{id}/purge", require(auth.PermUserManage, nil)(participationHandler.PurgeParticipation)).Methods("DELETE")
+
+	// Результаты
+	protected.HandleFunc("/participations/{id}/result", resultHandler.GetResult).Methods("GET")
+	protected.HandleFunc("/participations/{id}/result", require(auth.PermResultEnter, scopes.Participation("id"))(resultHandler.SaveResult)).Methods("PUT")
+	protected.HandleFunc("/participations/{id}/result", require(auth.PermResultEnter, scopes.Participation("id"))(resultHandler.PatchResult)).Methods("PATCH")
+
+	// Разряды: справочник, нормативы, предложения о присвоении и история
+	protected.HandleFunc("/ranks", rankHandler.ListRanks).Methods("GET")
+	protected.HandleFunc("/ranks", require(auth.PermRankEdit, nil)(rankHandler.CreateRank)).Methods("POST")
+	protected.HandleFunc("/ranks/{id}", require(auth.PermRankEdit, nil)(rankHandler.UpdateRank)).Methods("PUT")
+	protected.HandleFunc("/rank-standards", rankHandler.ListStandards).Methods("GET")
+	protected.HandleFunc("/rank-standards", require(auth.PermRankEdit, nil)(rankHandler.CreateStandard)).Methods("POST")
+	protected.HandleFunc("/rank-standards/{id}", require(auth.PermRankEdit, nil)(rankHandler.DeleteStandard)).Methods("DELETE")
+	protected.HandleFunc("/rank-proposals", require(auth.PermRankApprove, nil)(rankHandler.ListProposals)).Methods("GET")
+	protected.HandleFunc("/rank-proposals/{id}/approve", require(auth.PermRankApprove, nil)(rankHandler.ApproveProposal)).Methods("POST")
+	protected.HandleFunc("/rank-proposals/{id}/reject", require(auth.PermRankApprove, nil)(rankHandler.RejectProposal)).Methods("POST")
+	protected.HandleFunc("/athletes/{id}/ranks", rankHandler.AthleteHistory).Methods("GET")
 
 	// 5. РАЗДАЧА ФРОНТЕНДА
 	// Важно: Static файлы регистрируются ПОСЛЕ API, чтобы не перекрывать маршруты
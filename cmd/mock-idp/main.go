@@ -0,0 +1,252 @@
+// Команда mock-idp — минимальный провайдер OpenID Connect для локальной проверки входа через OIDC.
+// Не предназначен для реального использования: любой может войти под любым именем.
+//
+// Запуск:
+//
+//	go run ./cmd/mock-idp -groups federation-admins
+//
+// и в настройках приложения: OIDC_ISSUER=http://localhost:9090, OIDC_CLIENT_ID=sport-manager,
+// OIDC_CLIENT_SECRET=secret. На странице входа провайдера можно указать имя, email и группы.
+package main
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"html/template"
+	"log"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// authCode — выданный код авторизации и все, что нужно проверить при его обмене.
+type authCode struct {
+	clientID      string
+	redirectURI   string
+	nonce         string
+	codeChallenge string
+	username      string
+	email         string
+	groups        []string
+	expiresAt     time.Time
+}
+
+// provider хранит ключ подписи и выданные коды в памяти.
+type provider struct {
+	issuer       string
+	clientID     string
+	clientSecret string
+	groups       string
+	key          *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]*authCode
+}
+
+var loginPage = template.Must(template.New("login").Parse(`<!DOCTYPE html>
+<html lang="ru"><head><meta charset="UTF-8"><title>Mock IdP</title></head>
+<body style="font-family: sans-serif; max-width: 360px; margin: 60px auto;">
+<h3>Mock IdP — вход</h3>
+<form method="POST">
+    {{range $k, $v := .Params}}<input type="hidden" name="{{$k}}" value="{{index $v 0}}">{{end}}
+    <p><label>Имя пользователя<br><input name="username" value="ivan" required></label></p>
+    <p><label>Email<br><input name="email" value="ivan@federation.local" required></label></p>
+    <p><label>Группы (через запятую)<br><input name="groups" value="{{.Groups}}"></label></p>
+    <button type="submit">Войти</button>
+</form>
+</body></html>`))
+
+func main() {
+	addr := flag.String("addr", ":9090", "адрес HTTP-сервера")
+	issuer := flag.String("issuer", "http://localhost:9090", "issuer (публичный адрес провайдера)")
+	clientID := flag.String("client-id", "sport-manager", "client_id приложения")
+	clientSecret := flag.String("client-secret", "secret", "client_secret приложения (пусто — не проверяется)")
+	groups := flag.String("groups", "", "группы по умолчанию на странице входа")
+	flag.Parse()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		log.Fatalf("Ошибка генерации ключа: %v", err)
+	}
+
+	p := &provider{
+		issuer:       strings.TrimRight(*issuer, "/"),
+		clientID:     *clientID,
+		clientSecret: *clientSecret,
+		groups:       *groups,
+		key:          key,
+		codes:        make(map[string]*authCode),
+	}
+
+	http.HandleFunc("/.well-known/openid-configuration", p.discovery)
+	http.HandleFunc("/authorize", p.authorize)
+	http.HandleFunc("/token", p.token)
+	http.HandleFunc("/jwks", p.jwks)
+
+	log.Printf("=== Mock IdP запущен на %s (issuer %s) ===", *addr, p.issuer)
+	log.Fatal(http.ListenAndServe(*addr, nil))
+}
+
+// discovery отдает документ /.well-known/openid-configuration.
+func (p *provider) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                p.issuer,
+		"authorization_endpoint":                p.issuer + "/authorize",
+		"token_endpoint":                        p.issuer + "/token",
+		"jwks_uri":                              p.issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+// authorize показывает форму входа (GET) и выдает код авторизации (POST).
+func (p *provider) authorize(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		params := url.Values{}
+		for _, k := range []string{"client_id", "redirect_uri", "state", "nonce", "code_challenge", "code_challenge_method"} {
+			params.Set(k, r.Form.Get(k))
+		}
+		loginPage.Execute(w, map[string]interface{}{"Params": params, "Groups": p.groups})
+		return
+	}
+
+	if r.Form.Get("client_id") != p.clientID {
+		http.Error(w, "unknown client_id", http.StatusBadRequest)
+		return
+	}
+	if r.Form.Get("code_challenge") == "" || r.Form.Get("code_challenge_method") != "S256" {
+		http.Error(w, "PKCE (S256) is required", http.StatusBadRequest)
+		return
+	}
+
+	code := randomString()
+	var groups []string
+	for _, g := range strings.Split(r.Form.Get("groups"), ",") {
+		if g = strings.TrimSpace(g); g != "" {
+			groups = append(groups, g)
+		}
+	}
+
+	p.mu.Lock()
+	p.codes[code] = &authCode{
+		clientID:      p.clientID,
+		redirectURI:   r.Form.Get("redirect_uri"),
+		nonce:         r.Form.Get("nonce"),
+		codeChallenge: r.Form.Get("code_challenge"),
+		username:      r.Form.Get("username"),
+		email:         r.Form.Get("email"),
+		groups:        groups,
+		expiresAt:     time.Now().Add(time.Minute),
+	}
+	p.mu.Unlock()
+
+	q := url.Values{"code": {code}, "state": {r.Form.Get("state")}}
+	http.Redirect(w, r, r.Form.Get("redirect_uri")+"?"+q.Encode(), http.StatusFound)
+}
+
+// token обменивает код на ID-токен, проверяя клиента, redirect_uri и PKCE.
+func (p *provider) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.ParseForm() != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	clientID, secret, ok := r.BasicAuth()
+	if ok {
+		clientID, _ = url.QueryUnescape(clientID)
+		secret, _ = url.QueryUnescape(secret)
+	} else {
+		clientID, secret = r.Form.Get("client_id"), r.Form.Get("client_secret")
+	}
+	if clientID != p.clientID || (p.clientSecret != "" && secret != p.clientSecret) {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	// Код одноразовый: удаляем его сразу
+	p.mu.Lock()
+	c := p.codes[r.Form.Get("code")]
+	delete(p.codes, r.Form.Get("code"))
+	p.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.Form.Get("code_verifier")))
+	switch {
+	case r.Form.Get("grant_type") != "authorization_code", c == nil, time.Now().After(c.expiresAt),
+		c.redirectURI != r.Form.Get("redirect_uri"),
+		base64.RawURLEncoding.EncodeToString(sum[:]) != c.codeChallenge:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":                p.issuer,
+		"aud":                p.clientID,
+		"sub":                "mock|" + c.username,
+		"iat":                now.Unix(),
+		"exp":                now.Add(5 * time.Minute).Unix(),
+		"nonce":              c.nonce,
+		"email":              c.email,
+		"email_verified":     true,
+		"preferred_username": c.username,
+		"name":               c.username,
+		"groups":             c.groups,
+	})
+	token.Header["kid"] = "mock"
+	idToken, err := token.SignedString(p.key)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": randomString(),
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     idToken,
+	})
+}
+
+// jwks публикует открытый ключ подписи ID-токенов.
+func (p *provider) jwks(w http.ResponseWriter, r *http.Request) {
+	pub := p.key.PublicKey
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "mock",
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+func randomString() string {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
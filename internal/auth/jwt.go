@@ -1,6 +1,10 @@
 package auth
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"time"
 
@@ -11,36 +15,59 @@ import (
 )
 
 // Claims — структура полезной нагрузки токена.
-// Мы расширяем стандартные поля JWT (RegisteredClaims), добавляя имя и роль,
-// чтобы не лезть в базу данных при каждом запросе.
+// Мы расширяем стандартные поля JWT (RegisteredClaims), добавляя имя, глобальную роль
+// и роли с областью действия, чтобы не лезть в базу данных при каждой проверке прав.
 type Claims struct {
-	Username string `json:"username"`
-	Role     string `json:"role"`
+	UserID   int     `json:"uid"`
+	Username string  `json:"username"`
+	Role     string  `json:"role"`
+	Grants   []Grant `json:"grants,omitempty"`
+	// MustChangePassword ограничивает токен сменой пароля (см. RequireAccountSetup)
+	MustChangePassword bool `json:"pwd_change,omitempty"`
+	// MFASetupRequired ограничивает токен подключением 2FA, которую требует политика безопасности
+	MFASetupRequired bool `json:"mfa_setup,omitempty"`
+	// EmailVerified — без подтвержденного email права ролей не действуют (только просмотр)
+	EmailVerified bool `json:"email_verified,omitempty"`
+	// Language — язык сообщений API из профиля пользователя ('ru' или 'en')
+	Language string `json:"lang,omitempty"`
+	// APIKey заполняется, если запрос выполнен по API-ключу, а не по токену (в JWT не попадает)
+	APIKey *APIKeyGrant `json:"-"`
 	jwt.RegisteredClaims
 }
 
 // GenerateToken создает новый JWT-токен для пользователя.
-// Токен — это зашифрованная строка, которая подтверждает личность пользователя.
-func GenerateToken(user *repository.User, cfg *config.Config) (string, error) {
+// Токен подписывается активным асимметричным ключом; проверить его можно публичным ключом из JWKS.
+// mfaSetup — пользователь обязан подключить 2FA, прежде чем работать с API.
+func GenerateToken(user *repository.User, grants []Grant, mfaSetup bool, keys *KeySet, cfg *config.Config) (string, error) {
 	// Устанавливаем время жизни токена из конфигурации
 	expirationTime := time.Now().Add(cfg.JWTExpirationDuration)
 
+	// Уникальный идентификатор токена (jti) позволяет отозвать его до истечения срока
+	jti, err := randomHex(16)
+	if err != nil {
+		return "", err
+	}
+
 	// Заполняем данные (Payload)
 	claims := &Claims{
-		Username: user.Username,
-		Role:     user.Role, // Роль записывается в токен для работы Middleware
+		UserID:             user.ID,
+		Username:           user.Username,
+		Role:               user.Role, // Роль записывается в токен для работы Middleware
+		Grants:             grants,
+		MustChangePassword: user.MustChangePassword,
+		MFASetupRequired:   mfaSetup,
+		EmailVerified:      user.EmailVerified,
+		Language:           user.Language,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			Subject:   user.Username,
 			IssuedAt:  jwt.NewNumericDate(time.Now()), // Время выдачи токена
 		},
 	}
 
-	// Создаем токен с использованием алгоритма шифрования HS256
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	// Подписываем токен секретным ключом из конфига
-	tokenString, err := token.SignedString([]byte(cfg.JWTSecret))
+	// Подписываем токен активным ключом (RS256 или EdDSA), kid попадает в заголовок
+	tokenString, err := keys.sign(claims)
 	if err != nil {
 		return "", fmt.Errorf("ошибка подписи токена: %w", err)
 	}
@@ -49,18 +76,14 @@ func GenerateToken(user *repository.User, cfg *config.Config) (string, error) {
 }
 
 // ValidateToken проверяет подлинность токена и его срок годности.
-func ValidateToken(tokenString string, cfg *config.Config) (*Claims, error) {
+// Ключ проверки выбирается по kid, поэтому токены, подписанные предыдущим ключом, остаются действительными.
+func ValidateToken(tokenString string, keys *KeySet) (*Claims, error) {
 	claims := &Claims{}
 
-	// Парсим строку токена и проверяем подпись
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		// Проверка метода шифрования (защита от подмены алгоритма)
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("неожиданный метод подписи: %v", token.Header["alg"])
-		}
-		// Возвращаем секретный ключ для проверки подписи
-		return []byte(cfg.JWTSecret), nil
-	})
+	// Парсим строку токена и проверяем подпись; HMAC и "none" отклоняются сразу
+	token, err := jwt.ParseWithClaims(tokenString, claims, keys.verificationKey,
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}),
+	)
 
 	// Если токен поврежден или подпись не совпала
 	if err != nil {
@@ -74,3 +97,34 @@ func ValidateToken(tokenString string, cfg *config.Config) (*Claims, error) {
 
 	return claims, nil
 }
+
+// NewRefreshToken генерирует случайный refresh-токен.
+// Клиенту отдается token, в базе сохраняется только hash.
+func NewRefreshToken() (token, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", fmt.Errorf("ошибка генерации refresh-токена: %w", err)
+	}
+	token = base64.RawURLEncoding.EncodeToString(buf)
+	return token, HashToken(token), nil
+}
+
+// HashToken возвращает SHA-256 хеш токена в hex для хранения и поиска в базе.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewFamilyID генерирует идентификатор семейства refresh-токенов.
+func NewFamilyID() (string, error) {
+	return randomHex(16)
+}
+
+// randomHex возвращает n случайных байт в виде hex-строки.
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("ошибка генерации идентификатора: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
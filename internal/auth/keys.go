@@ -0,0 +1,203 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"sport-manager/pkg/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// signingKey — один ключ подписи. Private == nil означает, что ключ выведен из оборота:
+// им больше не подписывают, но выданные ранее токены продолжают проверяться.
+type signingKey struct {
+	kid     string
+	method  jwt.SigningMethod
+	private crypto.Signer
+	public  crypto.PublicKey
+}
+
+// KeySet хранит все ключи, которым доверяет сервис, и идентификатор (kid) ключа для подписи.
+// Ротация: новый ключ кладется в каталог и назначается активным, старый остается
+// (можно только публичную часть) до истечения последних подписанных им токенов.
+type KeySet struct {
+	keys   map[string]*signingKey
+	active string
+}
+
+// JWK — публичный ключ в формате RFC 7517.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`   // RSA: модуль
+	E   string `json:"e,omitempty"`   // RSA: публичная экспонента
+	Crv string `json:"crv,omitempty"` // OKP/EC: кривая
+	X   string `json:"x,omitempty"`   // OKP: публичный ключ; EC: координата X
+	Y   string `json:"y,omitempty"`   // EC: координата Y (ключи внешних провайдеров OIDC)
+}
+
+// JWKS — набор публичных ключей для /.well-known/jwks.json.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// LoadKeySet загружает ключи из каталога JWT_KEYS_DIR.
+// Каждый файл *.pem — один ключ, имя файла без расширения — его kid.
+// Поддерживаются закрытые ключи RSA (PKCS#1/PKCS#8) и Ed25519 (PKCS#8), а также
+// публичные ключи (PKIX) для проверки токенов, подписанных выведенными ключами.
+// Если каталог не задан, генерируется временный Ed25519-ключ (только для разработки).
+func LoadKeySet(cfg *config.Config) (*KeySet, error) {
+	ks := &KeySet{keys: make(map[string]*signingKey)}
+
+	if cfg.JWTKeysDir == "" {
+		log.Println("ВНИМАНИЕ: JWT_KEYS_DIR не задан, используется временный ключ — после перезапуска токены станут недействительны")
+		_, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка генерации ключа подписи: %w", err)
+		}
+		ks.keys["dev"] = &signingKey{kid: "dev", method: jwt.SigningMethodEdDSA, private: priv, public: priv.Public()}
+		ks.active = "dev"
+		return ks, nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(cfg.JWTKeysDir, "*.pem"))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения каталога ключей: %w", err)
+	}
+	for _, file := range files {
+		kid := strings.TrimSuffix(filepath.Base(file), ".pem")
+		key, err := loadKey(file, kid)
+		if err != nil {
+			return nil, err
+		}
+		ks.keys[kid] = key
+	}
+
+	// Активный ключ задается явно; если закрытый ключ единственный — он и активный
+	ks.active = cfg.JWTActiveKID
+	if ks.active == "" {
+		for kid, key := range ks.keys {
+			if key.private == nil {
+				continue
+			}
+			if ks.active != "" {
+				return nil, fmt.Errorf("в каталоге несколько закрытых ключей, укажите JWT_ACTIVE_KID")
+			}
+			ks.active = kid
+		}
+	}
+
+	active, ok := ks.keys[ks.active]
+	if !ok || active.private == nil {
+		return nil, fmt.Errorf("не найден закрытый ключ для подписи токенов (kid '%s')", ks.active)
+	}
+
+	log.Printf("Загружено ключей JWT: %d, активный kid: %s", len(ks.keys), ks.active)
+	return ks, nil
+}
+
+// loadKey разбирает PEM-файл с закрытым или публичным ключом.
+func loadKey(file, kid string) (*signingKey, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения ключа %s: %w", file, err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("файл %s не содержит PEM-блока", file)
+	}
+
+	var parsed interface{}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("неподдерживаемый тип PEM-блока '%s' в %s", block.Type, file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка разбора ключа %s: %w", file, err)
+	}
+
+	key := &signingKey{kid: kid}
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.method, key.private, key.public = jwt.SigningMethodRS256, k, &k.PublicKey
+	case *rsa.PublicKey:
+		key.method, key.public = jwt.SigningMethodRS256, k
+	case ed25519.PrivateKey:
+		key.method, key.private, key.public = jwt.SigningMethodEdDSA, k, k.Public()
+	case ed25519.PublicKey:
+		key.method, key.public = jwt.SigningMethodEdDSA, k
+	default:
+		return nil, fmt.Errorf("ключ %s: поддерживаются только RSA и Ed25519", file)
+	}
+	return key, nil
+}
+
+// sign подписывает claims активным ключом и проставляет kid в заголовок.
+func (ks *KeySet) sign(claims jwt.Claims) (string, error) {
+	key := ks.keys[ks.active]
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.kid
+	return token.SignedString(key.private)
+}
+
+// verificationKey выбирает ключ проверки по kid из заголовка токена.
+// Алгоритм токена обязан совпадать с алгоритмом ключа (защита от подмены алгоритма).
+func (ks *KeySet) verificationKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := ks.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("неизвестный ключ подписи: %q", kid)
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("неожиданный метод подписи: %v", token.Header["alg"])
+	}
+	return key.public, nil
+}
+
+// JWKS возвращает публичные части всех ключей, включая выведенные из оборота.
+func (ks *KeySet) JWKS() JWKS {
+	kids := make([]string, 0, len(ks.keys))
+	for kid := range ks.keys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	set := JWKS{Keys: make([]JWK, 0, len(kids))}
+	for _, kid := range kids {
+		key := ks.keys[kid]
+		jwk := JWK{Kid: kid, Use: "sig", Alg: key.method.Alg()}
+		switch pub := key.public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
@@ -2,10 +2,14 @@ package auth
 
 import (
 	"context"
+	"log"
+	"net"
 	"net/http"
 	"strings"
 
-	"sport-manager/pkg/config"
+	"sport-manager/internal/i18n"
+	"sport-manager/internal/problem"
+	"sport-manager/internal/repository"
 )
 
 // ContextKey — специальный тип для ключей контекста.
@@ -15,39 +19,86 @@ type ContextKey string
 const (
 	ContextKeyRole     ContextKey = "userRole"
 	ContextKeyUsername ContextKey = "userUsername"
+	ContextKeyUserID   ContextKey = "userID"
+	ContextKeyClaims   ContextKey = "tokenClaims" // *Claims целиком (нужны jti и срок для выхода)
 )
 
 // AuthMiddleware — основной фильтр (посредник), который проверяет JWT токен.
 // Он выполняется ДО того, как запрос попадет в хендлер.
-func AuthMiddleware(cfg *config.Config) func(http.Handler) http.Handler {
+// Помимо подписи и срока проверяется denylist отозванных токенов по jti.
+// Машинные клиенты вместо токена передают API-ключ в заголовке X-API-Key,
+// веб-интерфейс в режиме cookie-сессии — access-токен в cookie sm_access.
+func AuthMiddleware(keys *KeySet, tokens *repository.TokenRepository, apiKeys *repository.APIKeyRepository) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// 1. Извлекаем заголовок Authorization
-			authHeader := r.Header.Get("Authorization")
-			if authHeader == "" {
-				http.Error(w, "Требуется авторизация", http.StatusUnauthorized)
-				return
-			}
+			var claims *Claims
 
-			// 2. Проверяем формат заголовка (должен быть: Bearer <token>)
-			parts := strings.Split(authHeader, " ")
-			if len(parts) != 2 || parts[0] != "Bearer" {
-				http.Error(w, "Неверный формат токена", http.StatusUnauthorized)
-				return
-			}
-			tokenString := parts[1]
+			if apiKey := r.Header.Get(APIKeyHeader); apiKey != "" {
+				// API-ключ: права ограничены набором ключа, denylist токенов не нужен
+				c, err := authenticateAPIKey(r.Context(), apiKeys, apiKey)
+				if err != nil {
+					problem.Write(w, r, http.StatusUnauthorized, problem.CodeTokenInvalid, "API-ключ недействителен")
+					return
+				}
+				claims = c
+			} else {
+				// 1. Извлекаем токен: из заголовка Authorization или, в режиме cookie-сессии, из HttpOnly-cookie
+				tokenString, fromCookie := "", false
+				if authHeader := r.Header.Get("Authorization"); authHeader != "" {
+					// 2. Проверяем формат заголовка (должен быть: Bearer <token>)
+					parts := strings.Split(authHeader, " ")
+					if len(parts) != 2 || parts[0] != "Bearer" {
+						problem.Write(w, r, http.StatusUnauthorized, problem.CodeTokenInvalid, "Неверный формат токена")
+						return
+					}
+					tokenString = parts[1]
+				} else if cookie, err := r.Cookie(AccessCookieName); err == nil && cookie.Value != "" {
+					tokenString, fromCookie = cookie.Value, true
+				} else {
+					problem.Write(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Требуется авторизация")
+					return
+				}
 
-			// 3. Валидируем токен (проверка подписи и срока годности)
-			claims, err := ValidateToken(tokenString, cfg)
-			if err != nil {
-				http.Error(w, "Токен недействителен или просрочен", http.StatusUnauthorized)
-				return
+				// Cookie браузер отправит и с чужого сайта, поэтому изменяющие запросы
+				// по cookie обязаны нести CSRF-токен (заголовок Authorization чужой сайт подставить не может)
+				if fromCookie && !isSafeMethod(r.Method) && !ValidCSRF(r) {
+					problem.Write(w, r, http.StatusForbidden, problem.CodeCSRFInvalid, "CSRF-токен отсутствует или неверен")
+					return
+				}
+
+				// 3. Валидируем токен (проверка подписи и срока годности)
+				c, err := ValidateToken(tokenString, keys)
+				if err != nil {
+					problem.Write(w, r, http.StatusUnauthorized, problem.CodeTokenInvalid, "Токен недействителен или просрочен")
+					return
+				}
+
+				// 4. Проверяем, не отозван ли токен (выход из системы)
+				revoked, err := tokens.IsAccessTokenRevoked(r.Context(), c.ID)
+				if err != nil {
+					log.Printf("Ошибка проверки отзыва токена: %v", err)
+					problem.Write(w, r, http.StatusInternalServerError, problem.CodeInternal, "Ошибка проверки токена")
+					return
+				}
+				if revoked {
+					problem.Write(w, r, http.StatusUnauthorized, problem.CodeTokenRevoked, "Токен отозван")
+					return
+				}
+				claims = c
 			}
 
-			// 4. Передаем данные пользователя дальше по цепочке через Context.
+			// 5. Передаем данные пользователя дальше по цепочке через Context.
 			// Контекст позволяет хендлерам узнать, кто делает запрос, не перечитывая токен.
 			ctx := context.WithValue(r.Context(), ContextKeyRole, claims.Role)
 			ctx = context.WithValue(ctx, ContextKeyUsername, claims.Username)
+			ctx = context.WithValue(ctx, ContextKeyUserID, claims.UserID)
+			ctx = context.WithValue(ctx, ContextKeyClaims, claims)
+			// Автор изменений для журнала аудита, который репозитории пишут в транзакции изменения
+			ctx = repository.WithAuditActor(ctx, claims.Username, remoteIP(r))
+			// Язык из профиля важнее Accept-Language: браузер шлет заголовок сам, а профиль выбран пользователем
+			if claims.Language != "" {
+				ctx = i18n.WithLanguage(ctx, claims.Language)
+			}
 
 			// Передаем управление следующему обработчику с обновленным контекстом
 			next.ServeHTTP(w, r.WithContext(ctx))
@@ -55,20 +106,73 @@ func AuthMiddleware(cfg *config.Config) func(http.Handler) http.Handler {
 	}
 }
 
-// AdminOnly — Middleware для разграничения прав доступа (RBAC).
-// Он пропускает запрос дальше только в том случае, если у пользователя роль "admin".
-func AdminOnly(next http.HandlerFunc) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		// Извлекаем роль, которую ранее сохранил AuthMiddleware
-		role, ok := r.Context().Value(ContextKeyRole).(string)
-
-		// Если роли нет или она не админская — возвращаем 403 Forbidden
-		if !ok || role != "admin" {
-			http.Error(w, "Доступ запрещен: требуются права администратора", http.StatusForbidden)
-			return
-		}
+// AuditContext — Middleware для публичных маршрутов: изменения, сделанные без входа
+// (регистрация, подтверждение email, сброс пароля), попадают в журнал аудита с IP клиента.
+func AuditContext(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := repository.WithAuditActor(r.Context(), "", remoteIP(r))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// remoteIP возвращает адрес клиента без порта.
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// RequireAccountSetup — Middleware обязательной настройки учетной записи.
+// Пока в токене стоит флаг смены пароля или подключения 2FA, доступны только перечисленные пути
+// (профиль, смена пароля, подключение 2FA и выход); остальные запросы получают 403.
+func RequireAccountSetup(allowedPaths ...string) func(http.Handler) http.Handler {
+	allowed := make(map[string]bool, len(allowedPaths))
+	for _, p := range allowedPaths {
+		allowed[p] = true
+	}
 
-		// Если всё в порядке, выполняем основной код хендлера
-		next.ServeHTTP(w, r)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, _ := r.Context().Value(ContextKeyClaims).(*Claims)
+			if claims != nil && !allowed[r.URL.Path] {
+				if claims.MustChangePassword {
+					problem.Write(w, r, http.StatusForbidden, problem.CodePasswordChangeRequired, "Необходимо сменить пароль (POST /api/v1/me/password)")
+					return
+				}
+				if claims.MFASetupRequired {
+					problem.Write(w, r, http.StatusForbidden, problem.CodeMFAEnrollmentRequired, "Необходимо подключить двухфакторную аутентификацию (POST /api/v1/me/2fa/enroll)")
+					return
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequirePermission — Middleware для проверки прав доступа.
+// Право проверяется в области ресурса, которую вычисляет resolve: так судья соревнования 12
+// может вводить результаты только на этом соревновании. Если область определить не удалось
+// (ресурс не найден), запрос пропускают только глобальные роли — хендлер сам вернет 404.
+func RequirePermission(perm Permission, resolve ScopeResolver) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			// Извлекаем данные токена, которые ранее сохранил AuthMiddleware
+			claims, _ := r.Context().Value(ContextKeyClaims).(*Claims)
+
+			// При ошибке резолвер возвращает то, что успел определить
+			var scope Scope
+			if resolve != nil {
+				scope, _ = resolve(r)
+			}
+
+			if !claims.HasPermission(perm, scope) {
+				problem.Write(w, r, http.StatusForbidden, problem.CodeForbidden, "Доступ запрещен: недостаточно прав (%s)", perm)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
 	}
 }
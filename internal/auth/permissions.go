@@ -0,0 +1,181 @@
+package auth
+
+// Permission — именованное право на действие в системе.
+// Маршруты проверяют права, а не название роли: набор прав роли можно менять, не трогая хендлеры.
+type Permission string
+
+const (
+	PermCompetitionEdit     Permission = "competition:edit"     // Соревнования, нормативы, номера, жеребьевки, стартовые протоколы
+	PermParticipationManage Permission = "participation:manage" // Заявка и снятие спортсменов
+	PermResultEnter         Permission = "result:enter"         // Ввод результатов и мест
+	PermWeighInRecord       Permission = "weigh-in:record"      // Протокол взвешивания
+	PermAthleteEdit         Permission = "athlete:edit"         // Создание и изменение карточек спортсменов
+	PermAthleteViewPrivate  Permission = "athlete:view-private" // Дата рождения и адрес спортсмена
+	PermClubEdit            Permission = "club:edit"
+	PermRankEdit            Permission = "rank:edit"    // Справочник разрядов и нормативы
+	PermRankApprove         Permission = "rank:approve" // Утверждение присвоения разрядов
+	PermRoleAssign          Permission = "role:assign"  // Назначение ролей другим пользователям
+	PermUserManage          Permission = "user:manage"  // Учетные записи: роли, блокировка, сброс пароля (только администратор)
+)
+
+// allPermissions — все права системы (для проверки прав, выдаваемых API-ключам).
+var allPermissions = []Permission{
+	PermCompetitionEdit, PermParticipationManage, PermResultEnter, PermWeighInRecord, PermAthleteEdit,
+	PermAthleteViewPrivate, PermClubEdit, PermRankEdit, PermRankApprove, PermRoleAssign, PermUserManage,
+}
+
+// Роли пользователей. Глобальная роль хранится в users.role, дополнительные роли
+// с областью действия (соревнование или клуб) — в user_roles.
+const (
+	RoleAdmin     = "admin"
+	RoleOrganiser = "organiser"
+	RoleJudge     = "judge"
+	RoleCoach     = "coach"
+	RoleAthlete   = "athlete"
+	RoleViewer    = "viewer"
+)
+
+// Области действия ролей.
+const (
+	ScopeGlobal      = ""
+	ScopeCompetition = "competition"
+	ScopeClub        = "club"
+)
+
+// rolePermissions сопоставляет роли набор прав. Администратору доступно все.
+var rolePermissions = map[string][]Permission{
+	RoleOrganiser: {
+		PermCompetitionEdit, PermParticipationManage, PermResultEnter, PermWeighInRecord,
+		PermAthleteEdit, PermAthleteViewPrivate, PermRoleAssign,
+	},
+	RoleJudge:   {PermResultEnter, PermWeighInRecord, PermAthleteViewPrivate},
+	RoleCoach:   {PermParticipationManage, PermAthleteEdit, PermAthleteViewPrivate},
+	RoleAthlete: {},
+	RoleViewer:  {},
+}
+
+// Grant — роль, выданная пользователю в пределах области (глобально, на соревнование или клуб).
+type Grant struct {
+	Role      string `json:"role"`
+	ScopeType string `json:"scope_type,omitempty"`
+	ScopeID   int    `json:"scope_id,omitempty"`
+}
+
+// Scope — ресурс, к которому относится запрос. Нулевые поля означают, что ресурс
+// не привязан к соревнованию или клубу; такие действия доступны только по глобальным ролям.
+type Scope struct {
+	CompetitionID int
+	ClubID        int
+}
+
+// IsValidRole сообщает, известна ли роль системе.
+func IsValidRole(role string) bool {
+	if role == RoleAdmin {
+		return true
+	}
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// IsValidPermission сообщает, известно ли право системе.
+func IsValidPermission(perm Permission) bool {
+	for _, p := range allPermissions {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// RoleHasPermission сообщает, входит ли право в набор прав роли.
+func RoleHasPermission(role string, perm Permission) bool {
+	if role == RoleAdmin {
+		return true
+	}
+	for _, p := range rolePermissions[role] {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPrivilegedRole сообщает, дает ли роль административные полномочия
+// (администратор или организатор). Для таких ролей политика может требовать 2FA.
+func IsPrivilegedRole(role string) bool {
+	return role == RoleAdmin || role == RoleOrganiser
+}
+
+// covers проверяет, распространяется ли выданная роль на ресурс.
+func (g Grant) covers(scope Scope) bool {
+	switch g.ScopeType {
+	case ScopeGlobal:
+		return true
+	case ScopeCompetition:
+		return scope.CompetitionID != 0 && g.ScopeID == scope.CompetitionID
+	case ScopeClub:
+		return scope.ClubID != 0 && g.ScopeID == scope.ClubID
+	}
+	return false
+}
+
+// HasPermission проверяет право пользователя на действие с ресурсом.
+// Учитываются глобальная роль из токена и все роли с подходящей областью.
+// Пока email не подтвержден, аккаунт ограничен просмотром: никакие права не действуют.
+// Запрос по API-ключу получает только права ключа.
+func (c *Claims) HasPermission(perm Permission, scope Scope) bool {
+	if c == nil {
+		return false
+	}
+	if c.APIKey != nil {
+		return c.APIKey.allows(perm, scope)
+	}
+	if !c.EmailVerified {
+		return false
+	}
+	if RoleHasPermission(c.Role, perm) {
+		return true
+	}
+	for _, g := range c.Grants {
+		if g.covers(scope) && RoleHasPermission(g.Role, perm) {
+			return true
+		}
+	}
+	return false
+}
+
+// CanDelegate проверяет, может ли пользователь выдать роль в указанной области:
+// нужно право role:assign в этой области и все права выдаваемой роли.
+// Так организатор соревнования назначает судей, но не может выдать больше прав, чем имеет сам.
+func (c *Claims) CanDelegate(g Grant) bool {
+	if c == nil || c.APIKey != nil || !c.EmailVerified {
+		return false
+	}
+	if c.Role == RoleAdmin {
+		return true
+	}
+	if g.Role == RoleAdmin {
+		return false
+	}
+
+	scope := Scope{}
+	switch g.ScopeType {
+	case ScopeCompetition:
+		scope.CompetitionID = g.ScopeID
+	case ScopeClub:
+		scope.ClubID = g.ScopeID
+	default:
+		// Глобальные роли выдает только администратор
+		return false
+	}
+
+	if !c.HasPermission(PermRoleAssign, scope) {
+		return false
+	}
+	for _, p := range rolePermissions[g.Role] {
+		if !c.HasPermission(p, scope) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,154 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+
+	"sport-manager/internal/repository"
+
+	"github.com/gorilla/mux"
+)
+
+// ScopeResolver определяет, к какому соревнованию или клубу относится запрос.
+// nil-резолвер означает, что действие не привязано к ресурсу и разрешено только глобальным ролям.
+type ScopeResolver func(r *http.Request) (Scope, error)
+
+// ScopeResolvers строит резолверы, которым для определения области нужна база данных
+// (например, участие → соревнование, спортсмен → клуб).
+type ScopeResolvers struct {
+	participations *repository.ParticipationRepository
+	athletes       *repository.AthleteRepository
+}
+
+// NewScopeResolvers создает набор резолверов областей.
+func NewScopeResolvers(
+	participations *repository.ParticipationRepository,
+	athletes *repository.AthleteRepository,
+) *ScopeResolvers {
+	return &ScopeResolvers{participations: participations, athletes: athletes}
+}
+
+// CompetitionFromPath берет ID соревнования из параметра пути.
+func CompetitionFromPath(name string) ScopeResolver {
+	return func(r *http.Request) (Scope, error) {
+		id, err := strconv.Atoi(mux.Vars(r)[name])
+		return Scope{CompetitionID: id}, err
+	}
+}
+
+// ClubFromBody берет ID клуба из поля JSON-тела запроса.
+func ClubFromBody(field string) ScopeResolver {
+	return func(r *http.Request) (Scope, error) {
+		id, err := intFromBody(r, field)
+		return Scope{ClubID: id}, err
+	}
+}
+
+// Participation определяет соревнование и клуб спортсмена по ID записи об участии из параметра пути.
+func (s *ScopeResolvers) Participation(name string) ScopeResolver {
+	return func(r *http.Request) (Scope, error) {
+		id, err := strconv.Atoi(mux.Vars(r)[name])
+		if err != nil {
+			return Scope{}, err
+		}
+		// Удаленные записи тоже: область нужна для их восстановления
+		p, err := s.participations.Find(r.Context(), id, true)
+		if err != nil {
+			return Scope{}, err
+		}
+		return s.participationScope(r, p.CompetitionID, p.AthleteID)
+	}
+}
+
+// ParticipationFromBody определяет соревнование и клуб спортсмена по полям
+// competition_id и athlete_id тела заявки: заявить спортсмена может и организатор
+// соревнования, и тренер клуба.
+func (s *ScopeResolvers) ParticipationFromBody() ScopeResolver {
+	return func(r *http.Request) (Scope, error) {
+		competitionID, err := intFromBody(r, "competition_id")
+		if err != nil {
+			return Scope{}, err
+		}
+		athleteID, err := intFromBody(r, "athlete_id")
+		if err != nil {
+			return Scope{}, err
+		}
+		return s.participationScope(r, competitionID, athleteID)
+	}
+}
+
+// participationScope дополняет соревнование клубом спортсмена.
+func (s *ScopeResolvers) participationScope(r *http.Request, competitionID, athleteID int) (Scope, error) {
+	scope := Scope{CompetitionID: competitionID}
+	a, err := s.athletes.Find(r.Context(), athleteID, true)
+	if err != nil {
+		return scope, err
+	}
+	scope.ClubID = a.ClubID
+	return scope, nil
+}
+
+// Athlete определяет клуб спортсмена по его ID из параметра пути.
+func (s *ScopeResolvers) Athlete(name string) ScopeResolver {
+	return func(r *http.Request) (Scope, error) {
+		id, err := strconv.Atoi(mux.Vars(r)[name])
+		if err != nil {
+			return Scope{}, err
+		}
+		a, err := s.athletes.Find(r.Context(), id, true)
+		if err != nil {
+			return Scope{}, err
+		}
+		return Scope{ClubID: a.ClubID}, nil
+	}
+}
+
+// PatchedAthlete определяет клуб, в котором спортсмен окажется после JSON Merge Patch:
+// club_id из тела (null — без клуба), а если поле не передано — текущий клуб.
+// Вместе с Athlete не дает тренеру перевести спортсмена в чужой клуб или вывести из клуба.
+func (s *ScopeResolvers) PatchedAthlete(name string) ScopeResolver {
+	return func(r *http.Request) (Scope, error) {
+		fields, err := bodyFields(r)
+		if err != nil {
+			return Scope{}, err
+		}
+		if _, ok := fields["club_id"]; ok {
+			return ClubFromBody("club_id")(r)
+		}
+		return s.Athlete(name)(r)
+	}
+}
+
+// intFromBody читает числовое поле из JSON-тела, оставляя тело доступным для хендлера.
+// Отсутствующее поле и null дают 0.
+func intFromBody(r *http.Request, field string) (int, error) {
+	fields, err := bodyFields(r)
+	if err != nil {
+		return 0, err
+	}
+	var id int
+	if raw, ok := fields[field]; ok {
+		if err := json.Unmarshal(raw, &id); err != nil {
+			return 0, err
+		}
+	}
+	return id, nil
+}
+
+// bodyFields разбирает JSON-объект тела запроса, оставляя тело доступным для хендлера.
+func bodyFields(r *http.Request) (map[string]json.RawMessage, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
@@ -0,0 +1,159 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"time"
+
+	"sport-manager/pkg/config"
+)
+
+// Режимы сессии веб-интерфейса (SESSION_MODE).
+const (
+	SessionModeBearer = "bearer"
+	SessionModeCookie = "cookie"
+)
+
+// Имена cookie сессии и заголовок CSRF-токена.
+// CSRF-токен лежит в cookie, доступной JavaScript, и должен дублироваться в заголовке (double-submit):
+// чужой сайт может заставить браузер отправить cookie, но не может прочитать их и выставить заголовок.
+const (
+	AccessCookieName  = "sm_access"
+	RefreshCookieName = "sm_refresh"
+	CSRFCookieName    = "sm_csrf"
+	CSRFHeader        = "X-CSRF-Token"
+)
+
+// Пути cookie: access-токен нужен всему API, refresh-токен — только продлению сессии и выходу,
+// state входа через OIDC — только возврату от провайдера.
+const (
+	accessCookiePath    = "/api"
+	refreshCookiePath   = "/api/v1/auth"
+	oidcStateCookiePath = "/api/v1/auth/oidc"
+)
+
+// OIDCStateCookieName — cookie, связывающая начатый вход через OIDC с браузером, в котором он начат.
+// Без нее злоумышленник мог бы подсунуть жертве ссылку возврата со своим state и кодом
+// и незаметно авторизовать ее под своей учетной записью (login CSRF).
+const OIDCStateCookieName = "sm_oidc_state"
+
+// SessionCookies выдает и удаляет cookie сессии. В режиме 'bearer' отключен (Enabled() == false).
+type SessionCookies struct {
+	enabled    bool
+	secure     bool
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+}
+
+// NewSessionCookies создает настройки cookie-сессии из конфигурации.
+func NewSessionCookies(cfg *config.Config) (*SessionCookies, error) {
+	switch cfg.SessionMode {
+	case SessionModeBearer, SessionModeCookie:
+	default:
+		return nil, fmt.Errorf("неизвестный режим сессии SESSION_MODE='%s' (допустимо 'bearer' или 'cookie')", cfg.SessionMode)
+	}
+	return &SessionCookies{
+		enabled:    cfg.SessionMode == SessionModeCookie,
+		secure:     cfg.CookieSecure,
+		accessTTL:  cfg.JWTExpirationDuration,
+		refreshTTL: cfg.RefreshTokenDuration,
+	}, nil
+}
+
+// Enabled сообщает, выдаются ли токены в cookie.
+func (c *SessionCookies) Enabled() bool {
+	return c != nil && c.enabled
+}
+
+// Set кладет пару токенов в HttpOnly-cookie и выпускает новый CSRF-токен, который и возвращает.
+func (c *SessionCookies) Set(w http.ResponseWriter, accessToken, refreshToken string) (string, error) {
+	csrf, err := randomHex(32)
+	if err != nil {
+		return "", err
+	}
+
+	c.set(w, AccessCookieName, accessToken, accessCookiePath, c.accessTTL, true)
+	c.set(w, RefreshCookieName, refreshToken, refreshCookiePath, c.refreshTTL, true)
+	c.set(w, CSRFCookieName, csrf, "/", c.refreshTTL, false)
+	return csrf, nil
+}
+
+// Clear удаляет cookie сессии (выход).
+func (c *SessionCookies) Clear(w http.ResponseWriter) {
+	c.set(w, AccessCookieName, "", accessCookiePath, -1, true)
+	c.set(w, RefreshCookieName, "", refreshCookiePath, -1, true)
+	c.set(w, CSRFCookieName, "", "/", -1, false)
+}
+
+// SetOIDCState запоминает state начатого входа через OIDC. Cookie выдается в любом режиме сессии.
+// SameSite=Lax: провайдер возвращает браузер межсайтовым переходом, и Strict-cookie при нем не отправится.
+func (c *SessionCookies) SetOIDCState(w http.ResponseWriter, state string, ttl time.Duration) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     OIDCStateCookieName,
+		Value:    state,
+		Path:     oidcStateCookiePath,
+		MaxAge:   int(ttl.Seconds()),
+		HttpOnly: true,
+		Secure:   c.secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// ConsumeOIDCState удаляет cookie со state и сообщает, совпадает ли state из адреса возврата с ней.
+func (c *SessionCookies) ConsumeOIDCState(w http.ResponseWriter, r *http.Request, state string) bool {
+	http.SetCookie(w, &http.Cookie{
+		Name:     OIDCStateCookieName,
+		Path:     oidcStateCookiePath,
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   c.secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+	cookie, err := r.Cookie(OIDCStateCookieName)
+	if err != nil || cookie.Value == "" || state == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(state)) == 1
+}
+
+// RefreshToken возвращает refresh-токен из cookie или пустую строку.
+func (c *SessionCookies) RefreshToken(r *http.Request) string {
+	if cookie, err := r.Cookie(RefreshCookieName); err == nil {
+		return cookie.Value
+	}
+	return ""
+}
+
+// set выставляет одну cookie; отрицательный ttl удаляет ее.
+func (c *SessionCookies) set(w http.ResponseWriter, name, value, path string, ttl time.Duration, httpOnly bool) {
+	cookie := &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		HttpOnly: httpOnly,
+		Secure:   c.secure,
+		SameSite: http.SameSiteStrictMode,
+	}
+	if ttl < 0 {
+		cookie.MaxAge = -1
+	} else {
+		cookie.MaxAge = int(ttl.Seconds())
+	}
+	http.SetCookie(w, cookie)
+}
+
+// ValidCSRF проверяет double-submit: заголовок X-CSRF-Token должен совпадать с cookie sm_csrf.
+func ValidCSRF(r *http.Request) bool {
+	cookie, err := r.Cookie(CSRFCookieName)
+	if err != nil || cookie.Value == "" {
+		return false
+	}
+	header := r.Header.Get(CSRFHeader)
+	return subtle.ConstantTimeCompare([]byte(header), []byte(cookie.Value)) == 1
+}
+
+// isSafeMethod — методы, которые не меняют данные и не требуют CSRF-токена.
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+// rfc6238Secret — ключ "12345678901234567890" из приложения B RFC 6238 в base32.
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestValidateTOTP(t *testing.T) {
+	// Контрольные значения RFC 6238 (SHA-1): шесть младших цифр восьмизначных кодов
+	vectors := []struct {
+		unix int64
+		code string
+		step int64
+	}{
+		{59, "287082", 1},
+		{1111111109, "081804", 37037036},
+		{1111111111, "050471", 37037037},
+		{1234567890, "005924", 41152263},
+		{2000000000, "279037", 66666666},
+		{20000000000, "353130", 666666666},
+	}
+	for _, v := range vectors {
+		step, ok := ValidateTOTP(rfc6238Secret, v.code, time.Unix(v.unix, 0))
+		if !ok || step != v.step {
+			t.Errorf("ValidateTOTP(%q) at %d = (%d, %v), want (%d, true)", v.code, v.unix, step, ok, v.step)
+		}
+	}
+}
+
+func TestValidateTOTPRejects(t *testing.T) {
+	at := time.Unix(59, 0)
+	tests := []struct {
+		name   string
+		secret string
+		code   string
+		now    time.Time
+		ok     bool
+	}{
+		{"секрет в нижнем регистре", "gezdgnbvgy3tqojqgezdgnbvgy3tqojq", "287082", at, true},
+		{"шаг назад в пределах допуска", rfc6238Secret, "287082", at.Add(totpPeriod * time.Second), true},
+		{"шаг вперед в пределах допуска", rfc6238Secret, "287082", at.Add(-totpPeriod * time.Second), true},
+		{"расхождение больше допуска", rfc6238Secret, "287082", at.Add(3 * totpPeriod * time.Second), false},
+		{"неверный код", rfc6238Secret, "287083", at, false},
+		{"восьмизначный код", rfc6238Secret, "94287082", at, false},
+		{"пустой код", rfc6238Secret, "", at, false},
+		{"секрет не в base32", "not-base32!", "287082", at, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := ValidateTOTP(tt.secret, tt.code, tt.now); ok != tt.ok {
+				t.Errorf("ValidateTOTP() ok = %v, want %v", ok, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package draw
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	mrand "math/rand/v2"
+)
+
+// SeedSize — размер зерна жеребьевки в байтах (ключ генератора ChaCha8).
+const SeedSize = 32
+
+// NewSeed генерирует криптографически стойкое зерно для новой жеребьевки.
+func NewSeed() ([]byte, error) {
+	seed := make([]byte, SeedSize)
+	if _, err := rand.Read(seed); err != nil {
+		return nil, fmt.Errorf("не удалось сгенерировать зерно жеребьевки: %w", err)
+	}
+	return seed, nil
+}
+
+// Commitment возвращает SHA-256 от зерна в hex. Хеш публикуется до жеребьевки,
+// а после раскрытия зерна любой может убедиться, что оно не подменялось.
+func Commitment(seed []byte) string {
+	sum := sha256.Sum256(seed)
+	return hex.EncodeToString(sum[:])
+}
+
+// DecodeSeed разбирает зерно из hex-строки.
+func DecodeSeed(s string) ([]byte, error) {
+	seed, err := hex.DecodeString(s)
+	if err != nil || len(seed) != SeedSize {
+		return nil, fmt.Errorf("зерно должно быть hex-строкой длиной %d байт", SeedSize)
+	}
+	return seed, nil
+}
+
+// Shuffle возвращает перестановку items, полностью определяемую зерном.
+// Используется генератор ChaCha8 и собственная реализация тасования Фишера — Йетса,
+// чтобы результат не зависел от версии стандартной библиотеки и мог быть воспроизведен.
+func Shuffle(seed []byte, items []int) []int {
+	var key [SeedSize]byte
+	copy(key[:], seed)
+	src := mrand.NewChaCha8(key)
+
+	out := make([]int, len(items))
+	copy(out, items)
+	for i := len(out) - 1; i > 0; i-- {
+		j := int(uniform(src, uint64(i+1)))
+		out[i], out[j] = out[j], out[i]
+	}
+	return out
+}
+
+// uniform возвращает равномерно распределенное число в [0, n) методом отбраковки,
+// чтобы избежать смещения, возникающего при простом взятии остатка.
+func uniform(src *mrand.ChaCha8, n uint64) uint64 {
+	limit := (math.MaxUint64 / n) * n
+	for {
+		if v := src.Uint64(); v < limit {
+			return v % n
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package draw
+
+import (
+	"slices"
+	"testing"
+)
+
+func testSeed(fill byte) []byte {
+	seed := make([]byte, SeedSize)
+	for i := range seed {
+		seed[i] = fill + byte(i)
+	}
+	return seed
+}
+
+func TestShuffleDeterministic(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+
+	// Зафиксированный результат: изменение алгоритма сделает старые жеребьевки непроверяемыми
+	want := []int{5, 4, 3, 6, 9, 10, 8, 1, 7, 2}
+	if got := Shuffle(testSeed(0), items); !slices.Equal(got, want) {
+		t.Errorf("Shuffle() = %v, want %v", got, want)
+	}
+	if a, b := Shuffle(testSeed(0), items), Shuffle(testSeed(0), items); !slices.Equal(a, b) {
+		t.Errorf("одно зерно дало разные перестановки: %v и %v", a, b)
+	}
+	if a, b := Shuffle(testSeed(0), items), Shuffle(testSeed(1), items); slices.Equal(a, b) {
+		t.Errorf("разные зерна дали одинаковую перестановку %v", a)
+	}
+}
+
+func TestShufflePermutation(t *testing.T) {
+	tests := []struct {
+		name  string
+		items []int
+	}{
+		{"пусто", []int{}},
+		{"один элемент", []int{42}},
+		{"повторы", []int{1, 1, 2, 2, 3}},
+		{"сто элементов", func() []int {
+			items := make([]int, 100)
+			for i := range items {
+				items[i] = i
+			}
+			return items
+		}()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := slices.Clone(tt.items)
+			got := Shuffle(testSeed(7), tt.items)
+			if !slices.Equal(tt.items, original) {
+				t.Errorf("Shuffle изменил исходный срез: %v", tt.items)
+			}
+			slices.Sort(got)
+			sorted := slices.Clone(original)
+			slices.Sort(sorted)
+			if !slices.Equal(got, sorted) {
+				t.Errorf("Shuffle() не является перестановкой %v", original)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"sport-manager/internal/repository"
+	"sport-manager/internal/service"
+
+	"github.com/gorilla/mux"
+)
+
+// APIKeyHandler обрабатывает управление API-ключами машинных клиентов
+type APIKeyHandler struct {
+	service *service.APIKeyService
+}
+
+// NewAPIKeyHandler создает новый экземпляр хендлера API-ключей
+func NewAPIKeyHandler(s *service.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{service: s}
+}
+
+// ListKeys обрабатывает GET /api/v1/api-keys
+func (h *APIKeyHandler) ListKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.service.List(r.Context())
+	if err != nil {
+		writeError(w, r, err, "Не удалось получить список API-ключей")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, keys)
+}
+
+// CreateKey обрабатывает POST /api/v1/api-keys
+// Тело: {"name": "Хронометраж, стадион", "permissions": ["result:enter"], "scope_type": "competition",
+// "scope_id": 7, "expires_at": "2026-12-31T00:00:00Z"}. Ключ в ответе показывается только один раз.
+func (h *APIKeyHandler) CreateKey(w http.ResponseWriter, r *http.Request) {
+	var k repository.APIKey
+	if err := json.NewDecoder(r.Body).Decode(&k); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Неверный формат данных")
+		return
+	}
+
+	created, err := h.service.Create(r.Context(), currentClaims(r), clientIP(r), &k)
+	if err != nil {
+		writeError(w, r, err, "Не удалось создать API-ключ")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusCreated, created)
+}
+
+// RevokeKey обрабатывает DELETE /api/v1/api-keys/{id}
+func (h *APIKeyHandler) RevokeKey(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID ключа")
+		return
+	}
+
+	if err := h.service.Revoke(r.Context(), currentClaims(r), clientIP(r), id); err != nil {
+		writeError(w, r, err, "Не удалось отозвать API-ключ")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]string{"status": "success"})
+}
@@ -3,9 +3,11 @@ package handler
 import (
 	"encoding/json"
 	"net/http"
+	"sport-manager/internal/auth"
 	"sport-manager/internal/repository"
 	"sport-manager/internal/service"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
@@ -31,19 +33,26 @@ func (h *AthleteHandler) CreateAthlete(w http.ResponseWriter, r *http.Request) {
 		BirthDate string `json:"birth_date"`
 		Gender    string `json:"gender"`
 		Address   string `json:"address"`
+		ClubID    int    `json:"club_id"`
 	}
 
 	// Декодируем JSON из тела запроса
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		writeErrorResponse(w, http.StatusBadRequest, "Неверный формат JSON")
+		writeErrorResponse(w, r, http.StatusBadRequest, "Неверный формат JSON")
 		return
 	}
 
-	// Преобразуем строковую дату в формат time.Time
-	birthDate, err := time.Parse(time.RFC3339Nano, input.BirthDate)
-	if err != nil {
-		writeErrorResponse(w, http.StatusBadRequest, "Неверный формат даты рождения")
-		return
+	// Преобразуем строковую дату в формат time.Time; пустую дату отклонит проверка в сервисе
+	var birthDate time.Time
+	if input.BirthDate != "" {
+		parsed, err := time.Parse(time.RFC3339Nano, input.BirthDate)
+		if err != nil {
+			writeError(w, r, &service.ValidationError{Fields: []service.FieldError{
+				{Field: "birth_date", Code: service.CodeInvalid, Message: "неверный формат даты рождения"},
+			}}, "")
+			return
+		}
+		birthDate = parsed
 	}
 
 	// Создаем объект модели для передачи в сервис
@@ -53,11 +62,12 @@ func (h *AthleteHandler) CreateAthlete(w http.ResponseWriter, r *http.Request) {
 		Gender:    input.Gender,
 		IsActive:  true, // По умолчанию спортсмен активен
 		Address:   input.Address,
+		ClubID:    input.ClubID,
 	}
 
 	// Вызываем бизнес-логику создания
 	if err := h.service.CreateAthlete(r.Context(), athlete); err != nil {
-		writeErrorResponse(w, http.StatusInternalServerError, "Ошибка при сохранении спортсмена")
+		writeError(w, r, err, "Ошибка при сохранении спортсмена")
 		return
 	}
 
@@ -68,75 +78,220 @@ func (h *AthleteHandler) CreateAthlete(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// ListAllAthletes обрабатывает GET /api/v1/athletes
-// Возвращает полный список спортсменов.
+// ListAllAthletes обрабатывает GET /api/v1/athletes?name=иван&gender=m&active=true&birth_year_from=2005&birth_year_to=2010&club_id=3&sort=-full_name&limit=50&cursor=...
+// Возвращает страницу списка спортсменов; администратор может добавить удаленных через ?include_deleted=true.
 func (h *AthleteHandler) ListAllAthletes(w http.ResponseWriter, r *http.Request) {
-	athletes, err := h.service.ListAll(r.Context())
+	include, allowed := includeDeleted(r)
+	if !allowed {
+		writeErrorResponse(w, r, http.StatusForbidden, "Удаленные записи доступны только администратору")
+		return
+	}
+
+	q := newQueryParams(r)
+	f := repository.AthleteFilter{
+		Name:           q.String("name"),
+		Gender:         q.String("gender"),
+		Active:         q.Bool("active"),
+		BirthYearFrom:  q.Int("birth_year_from"),
+		BirthYearTo:    q.Int("birth_year_to"),
+		ClubID:         q.Int("club_id"),
+		IncludeDeleted: include,
+	}
+	page := q.Page()
+	if err := q.Err(); err != nil {
+		writeError(w, r, err, "")
+		return
+	}
+
+	// Дата рождения — личные данные: без права их просмотра по ней нельзя ни отбирать, ни сортировать,
+	// иначе ее можно было бы вычислить по составу и порядку списка
+	byBirthDate := f.BirthYearFrom != 0 || f.BirthYearTo != 0 || strings.TrimPrefix(page.Sort, "-") == "birth_date"
+	if byBirthDate && !hasPermission(r, auth.PermAthleteViewPrivate, auth.Scope{}) {
+		writeErrorResponse(w, r, http.StatusForbidden, "Отбор и сортировка по дате рождения доступны только при праве просмотра личных данных")
+		return
+	}
+
+	athletes, info, err := h.service.List(r.Context(), f, page)
 	if err != nil {
-		writeErrorResponse(w, http.StatusInternalServerError, "Ошибка получения списка")
+		writeError(w, r, err, "Ошибка получения списка")
 		return
 	}
 
-	writeJSONResponse(w, http.StatusOK, athletes)
+	views := make([]interface{}, 0, len(athletes))
+	for i := range athletes {
+		views = append(views, athleteView(r, &athletes[i]))
+	}
+
+	writePage(w, r, "athletes", views, info)
 }
 
-// GetAthleteByID обрабатывает GET /api/v1/athletes/{id}
+// GetAthleteByID обрабатывает GET /api/v1/athletes/{id}[?as_of=2024-05-01]
 func (h *AthleteHandler) GetAthleteByID(w http.ResponseWriter, r *http.Request) {
 	// Извлекаем ID из параметров пути (URL)
 	vars := mux.Vars(r)
 	id, err := strconv.Atoi(vars["id"])
 	if err != nil {
-		writeErrorResponse(w, http.StatusBadRequest, "Некорректный ID")
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID")
 		return
 	}
 
-	athlete, err := h.service.GetByID(r.Context(), id)
+	// ?as_of= показывает спортсмена в том виде, в каком он был на указанную дату
+	at, asOf, err := parseAsOf(r)
 	if err != nil {
-		writeErrorResponse(w, http.StatusNotFound, "Спортсмен не найден")
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный as_of: ожидается дата ГГГГ-ММ-ДД или время RFC 3339")
 		return
 	}
 
-	writeJSONResponse(w, http.StatusOK, athlete)
+	include, allowed := includeDeleted(r)
+	if !allowed {
+		writeErrorResponse(w, r, http.StatusForbidden, "Удаленные записи доступны только администратору")
+		return
+	}
+
+	var athlete *repository.Athlete
+	if asOf {
+		athlete, err = h.service.GetAsOf(r.Context(), id, at)
+	} else {
+		athlete, err = h.service.GetByID(r.Context(), id, include)
+	}
+	if err != nil {
+		writeError(w, r, err, "Ошибка получения спортсмена")
+		return
+	}
+
+	writeVersioned(w, r, athlete.Version, athleteView(r, athlete))
 }
 
 // UpdateAthlete обрабатывает PUT /api/v1/athletes/{id}
+// Требует заголовок If-Match с ETag, полученным при чтении спортсмена.
 func (h *AthleteHandler) UpdateAthlete(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id, err := strconv.Atoi(vars["id"])
 	if err != nil {
-		writeErrorResponse(w, http.StatusBadRequest, "Некорректный ID")
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID")
+		return
+	}
+
+	version, ok := requireIfMatch(w, r)
+	if !ok {
 		return
 	}
 
 	var input repository.Athlete
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		writeErrorResponse(w, http.StatusBadRequest, "Ошибка парсинга данных")
+		writeErrorResponse(w, r, http.StatusBadRequest, "Ошибка парсинга данных")
 		return
 	}
 
 	input.ID = id
 
-	if err := h.service.Update(r.Context(), &input); err != nil {
-		writeErrorResponse(w, http.StatusInternalServerError, "Ошибка при обновлении")
+	if err := h.service.Update(r.Context(), &input, version); err != nil {
+		writeError(w, r, err, "Ошибка при обновлении")
 		return
 	}
 
-	writeJSONResponse(w, http.StatusOK, map[string]string{"status": "success"})
+	w.Header().Set("ETag", versionETag(input.Version))
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{"status": "success", "version": input.Version})
 }
 
-// DeleteAthlete обрабатывает DELETE /api/v1/athletes/{id}
+// PatchAthlete обрабатывает PATCH /api/v1/athletes/{id} (JSON Merge Patch, RFC 7396).
+// Меняет только переданные поля, например {"is_active": false}; требует If-Match.
+func (h *AthleteHandler) PatchAthlete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID")
+		return
+	}
+
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+	fields, ok := decodeMergePatch(w, r)
+	if !ok {
+		return
+	}
+
+	athlete, err := h.service.Patch(r.Context(), id, fields, version)
+	if err != nil {
+		writeError(w, r, err, "Ошибка при обновлении")
+		return
+	}
+
+	w.Header().Set("ETag", versionETag(athlete.Version))
+	writeJSONResponse(w, http.StatusOK, athleteView(r, athlete))
+}
+
+// DeleteAthlete обрабатывает DELETE /api/v1/athletes/{id} (с заголовком If-Match)
 func (h *AthleteHandler) DeleteAthlete(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id, err := strconv.Atoi(vars["id"])
 	if err != nil {
-		writeErrorResponse(w, http.StatusBadRequest, "Некорректный ID")
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID")
+		return
+	}
+
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id, version); err != nil {
+		writeError(w, r, err, "Не удалось удалить спортсмена")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]string{"status": "success"})
+}
+
+// RestoreAthlete обрабатывает POST /api/v1/athletes/{id}/restore
+func (h *AthleteHandler) RestoreAthlete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID")
 		return
 	}
 
-	if err := h.service.Delete(r.Context(), id); err != nil {
-		writeErrorResponse(w, http.StatusNotFound, "Не удалось удалить спортсмена")
+	if err := h.service.Restore(r.Context(), id); err != nil {
+		writeError(w, r, err, "Ошибка при восстановлении спортсмена")
 		return
 	}
 
 	writeJSONResponse(w, http.StatusOK, map[string]string{"status": "success"})
 }
+
+// PurgeAthlete обрабатывает DELETE /api/v1/athletes/{id}/purge
+// Безвозвратно удаляет ранее удаленного спортсмена вместе с его участиями и результатами.
+func (h *AthleteHandler) PurgeAthlete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID")
+		return
+	}
+
+	if err := h.service.Purge(r.Context(), id); err != nil {
+		writeError(w, r, err, "Ошибка при удалении спортсмена")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]string{"status": "success"})
+}
+
+// publicAthlete — карточка спортсмена без личных данных (даты рождения и адреса).
+type publicAthlete struct {
+	ID       int    `json:"id"`
+	FullName string `json:"full_name"`
+	Gender   string `json:"gender"`
+	IsActive bool   `json:"is_active"`
+	ClubID   int    `json:"club_id"`
+	Version  int    `json:"version"`
+}
+
+// athleteView возвращает полную карточку, если у пользователя есть право athlete:view-private
+// (глобально или в клубе спортсмена), и публичную — в остальных случаях.
+func athleteView(r *http.Request, a *repository.Athlete) interface{} {
+	if hasPermission(r, auth.PermAthleteViewPrivate, auth.Scope{ClubID: a.ClubID}) {
+		return a
+	}
+	return publicAthlete{ID: a.ID, FullName: a.FullName, Gender: a.Gender, IsActive: a.IsActive, ClubID: a.ClubID, Version: a.Version}
+}
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"sport-manager/internal/repository"
+	"sport-manager/internal/service"
+)
+
+// AuditHandler обрабатывает просмотр журнала аудита
+type AuditHandler struct {
+	service *service.AuditService
+}
+
+// NewAuditHandler создает новый экземпляр хендлера аудита
+func NewAuditHandler(s *service.AuditService) *AuditHandler {
+	return &AuditHandler{service: s}
+}
+
+// ListAudit обрабатывает GET /api/v1/audit?entity=athlete&entity_id=5&user=admin&from=...&to=...&limit=100
+// Время в параметрах from и to — в формате RFC 3339.
+func (h *AuditHandler) ListAudit(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	f := repository.AuditFilter{Entity: q.Get("entity"), Username: q.Get("user")}
+
+	var err error
+	if v := q.Get("entity_id"); v != "" {
+		if f.EntityID, err = strconv.Atoi(v); err != nil {
+			writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный entity_id")
+			return
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		if f.Limit, err = strconv.Atoi(v); err != nil {
+			writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный limit")
+			return
+		}
+	}
+	if v := q.Get("from"); v != "" {
+		if f.From, err = time.Parse(time.RFC3339, v); err != nil {
+			writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный from: ожидается время в формате RFC 3339")
+			return
+		}
+	}
+	if v := q.Get("to"); v != "" {
+		if f.To, err = time.Parse(time.RFC3339, v); err != nil {
+			writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный to: ожидается время в формате RFC 3339")
+			return
+		}
+	}
+
+	entries, err := h.service.List(r.Context(), f)
+	if err != nil {
+		writeError(w, r, err, "Не удалось получить журнал аудита")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, entries)
+}
+
+// VerifyAudit обрабатывает GET /api/v1/audit/verify
+func (h *AuditHandler) VerifyAudit(w http.ResponseWriter, r *http.Request) {
+	result, err := h.service.Verify(r.Context())
+	if err != nil {
+		writeError(w, r, err, "Не удалось проверить журнал аудита")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, result)
+}
@@ -2,8 +2,17 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
+	"net/url"
+	"sport-manager/internal/auth"
+	"sport-manager/internal/i18n"
+	"sport-manager/internal/repository"
 	"sport-manager/internal/service"
+	"strconv"
+
+	"github.com/gorilla/mux"
 )
 
 // --- СТРУКТУРЫ ДАННЫХ ДЛЯ ЗАПРОСОВ ---
@@ -19,56 +28,162 @@ type RegisterRequest struct {
 	Username string `json:"username"`
 	Email    string `json:"email"`
 	Password string `json:"password"`
+	Language string `json:"language"` // Язык писем: 'ru' или 'en' (по умолчанию из Accept-Language)
+}
+
+// RefreshRequest описывает входящие данные для продления сессии и выхода
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
 }
 
 // AuthHandler отвечает за обработку HTTP-запросов, связанных с аутентификацией
+// В режиме cookie-сессии токены выдаются в HttpOnly-cookie, а не в теле ответа.
 type AuthHandler struct {
 	service *service.AuthService
+	cookies *auth.SessionCookies
 }
 
 // NewAuthHandler создает новый экземпляр хендлера (Dependency Injection)
-func NewAuthHandler(s *service.AuthService) *AuthHandler {
-	return &AuthHandler{service: s}
+func NewAuthHandler(s *service.AuthService, cookies *auth.SessionCookies) *AuthHandler {
+	return &AuthHandler{service: s, cookies: cookies}
 }
 
 // --- МЕТОДЫ ОБРАБОТКИ ---
 
-// Login выполняет аутентификацию пользователя и выдает JWT токен
+// Login выполняет аутентификацию пользователя и выдает пару токенов (access + refresh)
 func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	var req LoginRequest
 	// Декодируем тело JSON-запроса в структуру
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.respondWithError(w, http.StatusBadRequest, "Некорректный формат запроса")
+		h.respondWithError(w, r, http.StatusBadRequest, "Некорректный формат запроса")
 		return
 	}
 
 	// Вызываем бизнес-логику из сервиса
-	token, err := h.service.Login(r.Context(), req.Username, req.Password)
+	tokens, challenge, err := h.service.Login(r.Context(), req.Username, req.Password, clientIP(r))
 	if err != nil {
+		// Временная блокировка после серии неудач: клиенту сообщается, когда повторить попытку
+		var locked *service.LockedError
+		if errors.As(err, &locked) {
+			w.Header().Set("Retry-After", strconv.Itoa(int(locked.RetryAfter.Seconds())+1))
+			h.respondWithError(w, r, http.StatusTooManyRequests, "Слишком много неудачных попыток входа, повторите позже")
+			return
+		}
 		// Если пароль неверный или пользователь не найден
-		h.respondWithError(w, http.StatusUnauthorized, "Неверный логин или пароль")
+		h.respondWithError(w, r, http.StatusUnauthorized, "Неверный логин или пароль")
 		return
 	}
 
-	// Возвращаем токен в случае успеха
-	h.respondWithJSON(w, http.StatusOK, map[string]string{
-		"access_token": token,
-		"status":       "success",
-	})
+	// Подключена 2FA: клиент должен запросить код и вызвать /auth/login/2fa
+	if challenge != nil {
+		h.respondWithJSON(w, http.StatusOK, map[string]interface{}{
+			"status":       "mfa_required",
+			"mfa_required": true,
+			"challenge":    challenge.Token,
+			"expires_in":   challenge.ExpiresIn,
+		})
+		return
+	}
+
+	// Возвращаем токены в случае успеха
+	h.respondWithTokens(w, r, tokens)
+}
+
+// LoginSecondFactor завершает вход с 2FA (POST /auth/login/2fa)
+// Тело: {"challenge": "...", "code": "123456"}; вместо кода из приложения можно передать резервный код.
+func (h *AuthHandler) LoginSecondFactor(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Challenge string `json:"challenge"`
+		Code      string `json:"code"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Challenge == "" || req.Code == "" {
+		h.respondWithError(w, r, http.StatusBadRequest, "Необходимо передать challenge и code")
+		return
+	}
+
+	tokens, err := h.service.CompleteLogin(r.Context(), req.Challenge, req.Code, clientIP(r))
+	if err != nil {
+		var locked *service.LockedError
+		if errors.As(err, &locked) {
+			w.Header().Set("Retry-After", strconv.Itoa(int(locked.RetryAfter.Seconds())+1))
+			h.respondWithError(w, r, http.StatusTooManyRequests, "Слишком много неудачных попыток входа, повторите позже")
+			return
+		}
+		writeError(w, r, err, "Ошибка сервера при авторизации")
+		return
+	}
+
+	h.respondWithTokens(w, r, tokens)
+}
+
+// Refresh обменивает refresh-токен на новую пару токенов (POST /auth/refresh)
+// В режиме cookie-сессии refresh-токен берется из cookie; такой запрос требует CSRF-токен.
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req RefreshRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.respondWithError(w, r, http.StatusBadRequest, "Некорректный формат запроса")
+			return
+		}
+	}
+	if req.RefreshToken == "" && h.cookies.Enabled() {
+		if req.RefreshToken = h.cookies.RefreshToken(r); req.RefreshToken != "" && !auth.ValidCSRF(r) {
+			h.respondWithError(w, r, http.StatusForbidden, "CSRF-токен отсутствует или неверен")
+			return
+		}
+	}
+	if req.RefreshToken == "" {
+		h.respondWithError(w, r, http.StatusBadRequest, "Необходимо передать refresh_token")
+		return
+	}
+
+	tokens, err := h.service.Refresh(r.Context(), req.RefreshToken)
+	if err != nil {
+		h.respondWithError(w, r, http.StatusUnauthorized, "Сессия недействительна, выполните вход заново")
+		return
+	}
+
+	h.respondWithTokens(w, r, tokens)
+}
+
+// Logout завершает текущую сессию (POST /auth/logout)
+// Текущий access-токен отзывается; refresh_token в теле запроса необязателен.
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	var req RefreshRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.respondWithError(w, r, http.StatusBadRequest, "Некорректный формат запроса")
+			return
+		}
+	}
+
+	if req.RefreshToken == "" && h.cookies.Enabled() {
+		req.RefreshToken = h.cookies.RefreshToken(r)
+	}
+
+	if err := h.service.Logout(r.Context(), currentClaims(r), req.RefreshToken); err != nil {
+		h.respondWithError(w, r, http.StatusBadRequest, "Не удалось завершить сессию")
+		return
+	}
+	if h.cookies.Enabled() {
+		h.cookies.Clear(w)
+	}
+
+	h.respondWithJSON(w, http.StatusOK, map[string]string{"status": "success"})
 }
 
 // Register создает нового пользователя в системе
 func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	var req RegisterRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.respondWithError(w, http.StatusBadRequest, "Некорректный формат запроса")
+		h.respondWithError(w, r, http.StatusBadRequest, "Некорректный формат запроса")
 		return
 	}
 
 	// Передаем данные в слой сервиса для регистрации
-	err := h.service.Register(r.Context(), req.Username, req.Email, req.Password)
+	err := h.service.Register(r.Context(), req.Username, req.Email, req.Password, requestLanguage(r, req.Language))
 	if err != nil {
-		h.respondWithError(w, http.StatusInternalServerError, "Ошибка при создании пользователя")
+		writeError(w, r, err, "Ошибка при создании пользователя")
 		return
 	}
 
@@ -76,11 +191,284 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	h.respondWithJSON(w, http.StatusCreated, map[string]string{"status": "success"})
 }
 
+// VerifyEmail подтверждает email по токену из письма
+// GET /auth/verify-email?token=... (переход по ссылке) или POST с телом {"token": "..."}
+func (h *AuthHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if r.Method == http.MethodPost {
+		var req struct {
+			Token string `json:"token"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.respondWithError(w, r, http.StatusBadRequest, "Некорректный формат запроса")
+			return
+		}
+		token = req.Token
+	}
+	if token == "" {
+		h.respondWithError(w, r, http.StatusBadRequest, "Необходимо передать token")
+		return
+	}
+
+	if err := h.service.VerifyEmail(r.Context(), token); err != nil {
+		writeError(w, r, err, "Не удалось подтвердить email")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, map[string]string{"status": "success"})
+}
+
+// ResendVerification повторно отправляет письмо подтверждения (POST /me/verify-email)
+func (h *AuthHandler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.ResendVerification(r.Context(), currentClaims(r)); err != nil {
+		writeError(w, r, err, "Не удалось отправить письмо подтверждения")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusAccepted, map[string]string{"status": "success"})
+}
+
+// RequestPasswordReset отправляет ссылку для сброса пароля (POST /auth/password-reset)
+// Ответ всегда одинаковый, чтобы по нему нельзя было проверить, зарегистрирован ли адрес.
+func (h *AuthHandler) RequestPasswordReset(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Email string `json:"email"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Email == "" {
+		h.respondWithError(w, r, http.StatusBadRequest, "Необходимо передать email")
+		return
+	}
+
+	h.service.RequestPasswordReset(r.Context(), req.Email)
+
+	h.respondWithJSON(w, http.StatusAccepted, map[string]string{
+		"status":  "success",
+		"message": i18n.T(i18n.FromRequest(r), "Если адрес зарегистрирован, на него отправлено письмо со ссылкой для сброса пароля"),
+	})
+}
+
+// ConfirmPasswordReset задает новый пароль по токену из письма (POST /auth/password-reset/confirm)
+func (h *AuthHandler) ConfirmPasswordReset(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Token       string `json:"token"`
+		NewPassword string `json:"new_password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Token == "" {
+		h.respondWithError(w, r, http.StatusBadRequest, "Необходимо передать token и new_password")
+		return
+	}
+
+	if err := h.service.ResetPassword(r.Context(), req.Token, req.NewPassword); err != nil {
+		writeError(w, r, err, "Не удалось сменить пароль")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, map[string]string{"status": "success"})
+}
+
+// ChangePassword меняет пароль текущего пользователя (POST /me/password)
+// Требует текущий пароль; в ответ выдается новая пара токенов, остальные сессии завершаются.
+func (h *AuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		CurrentPassword string `json:"current_password"`
+		NewPassword     string `json:"new_password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, r, http.StatusBadRequest, "Некорректный формат запроса")
+		return
+	}
+
+	tokens, err := h.service.ChangePassword(r.Context(), currentClaims(r), req.CurrentPassword, req.NewPassword)
+	if err != nil {
+		writeError(w, r, err, "Не удалось сменить пароль")
+		return
+	}
+
+	h.respondWithTokens(w, r, tokens)
+}
+
+// --- ВХОД ЧЕРЕЗ OPENID CONNECT ---
+
+// OIDCLogin перенаправляет браузер на страницу входа провайдера (GET /auth/oidc/login)
+// state запоминается в cookie: возврат от провайдера примется только в этом браузере.
+func (h *AuthHandler) OIDCLogin(w http.ResponseWriter, r *http.Request) {
+	authURL, state, err := h.service.StartOIDC(r.Context(), 0)
+	if err != nil {
+		h.redirectToLoginPage(w, r, url.Values{"error": {oidcErrorMessage(r, err)}})
+		return
+	}
+	h.cookies.SetOIDCState(w, state, service.OIDCLoginTTL)
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// OIDCCallback принимает возврат от провайдера (GET /auth/oidc/callback?state=...&code=...)
+// Результат передается странице входа во фрагменте адреса: фрагмент не уходит на сервер
+// и не попадает в логи прокси. Токены во фрагмент не кладутся: в режиме bearer странице
+// передается одноразовый код, который она обменивает на токены через POST /auth/oidc/token.
+func (h *AuthHandler) OIDCCallback(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	lang := i18n.FromRequest(r)
+	if !h.cookies.ConsumeOIDCState(w, r, q.Get("state")) {
+		h.redirectToLoginPage(w, r, url.Values{"error": {i18n.T(lang, "Вход через провайдера начат в другом браузере или устарел, начните заново")}})
+		return
+	}
+	if e := q.Get("error"); e != "" {
+		h.redirectToLoginPage(w, r, url.Values{"error": {i18n.Tf(lang, "Провайдер отклонил вход: %s", e)}})
+		return
+	}
+
+	code, challenge, err := h.service.CompleteOIDC(r.Context(), q.Get("state"), q.Get("code"))
+	if err != nil {
+		h.redirectToLoginPage(w, r, url.Values{"error": {oidcErrorMessage(r, err)}})
+		return
+	}
+
+	if challenge != nil {
+		h.redirectToLoginPage(w, r, url.Values{"mfa_challenge": {challenge.Token}})
+		return
+	}
+
+	// В режиме cookie-сессии код обменивается сразу: токены не покидают cookie, странице сообщается только режим
+	if h.cookies.Enabled() {
+		tokens, err := h.service.ExchangeOIDCCode(r.Context(), code)
+		if err != nil {
+			h.redirectToLoginPage(w, r, url.Values{"error": {oidcErrorMessage(r, err)}})
+			return
+		}
+		if _, err := h.cookies.Set(w, tokens.AccessToken, tokens.RefreshToken); err != nil {
+			h.redirectToLoginPage(w, r, url.Values{"error": {i18n.T(lang, "Ошибка сервера при авторизации")}})
+			return
+		}
+		h.redirectToLoginPage(w, r, url.Values{
+			"session":              {auth.SessionModeCookie},
+			"must_change_password": {strconv.FormatBool(tokens.MustChangePassword)},
+			"mfa_setup_required":   {strconv.FormatBool(tokens.MFASetupRequired)},
+		})
+		return
+	}
+	h.redirectToLoginPage(w, r, url.Values{"login_code": {code}})
+}
+
+// ExchangeOIDCCode выдает токены по одноразовому коду входа через OIDC (POST /auth/oidc/token)
+// Тело: {"code": "..."}; код действует минуту и только один раз.
+func (h *AuthHandler) ExchangeOIDCCode(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Code string `json:"code"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Code == "" {
+		h.respondWithError(w, r, http.StatusBadRequest, "Необходимо передать code")
+		return
+	}
+
+	tokens, err := h.service.ExchangeOIDCCode(r.Context(), req.Code)
+	if err != nil {
+		writeError(w, r, err, "Не удалось войти через провайдера")
+		return
+	}
+	h.respondWithTokens(w, r, tokens)
+}
+
+// LinkOIDC начинает привязку провайдера к текущей учетной записи (POST /me/oidc/link)
+// В ответе — адрес страницы входа провайдера, на который клиент должен перейти.
+func (h *AuthHandler) LinkOIDC(w http.ResponseWriter, r *http.Request) {
+	authURL, state, err := h.service.StartOIDC(r.Context(), currentClaims(r).UserID)
+	if err != nil {
+		writeError(w, r, err, "Не удалось войти через провайдера")
+		return
+	}
+	h.cookies.SetOIDCState(w, state, service.OIDCLoginTTL)
+	h.respondWithJSON(w, http.StatusOK, map[string]string{"authorization_url": authURL})
+}
+
+// ListIdentities возвращает привязанные внешние учетные записи (GET /me/identities)
+func (h *AuthHandler) ListIdentities(w http.ResponseWriter, r *http.Request) {
+	identities, err := h.service.ListIdentities(r.Context(), currentClaims(r))
+	if err != nil {
+		writeError(w, r, err, "Не удалось получить внешние учетные записи")
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, identities)
+}
+
+// UnlinkIdentity отвязывает внешнюю учетную запись (DELETE /me/identities/{id})
+func (h *AuthHandler) UnlinkIdentity(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.respondWithError(w, r, http.StatusBadRequest, "Некорректный ID")
+		return
+	}
+
+	if err := h.service.UnlinkIdentity(r.Context(), currentClaims(r), id); err != nil {
+		writeError(w, r, err, "Не удалось отвязать учетную запись")
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, map[string]string{"status": "success"})
+}
+
+// oidcErrorMessage возвращает текст ошибки входа через OIDC для пользователя на языке запроса.
+// Клиенту показываются только сообщения предметной области; внутренние ошибки
+// (в том числе текст репозитория и драйвера БД) уходят в лог.
+func oidcErrorMessage(r *http.Request, err error) string {
+	lang := i18n.FromRequest(r)
+	var de *repository.DomainError
+	if errors.As(err, &de) {
+		return i18n.Tf(lang, de.Format, de.Args...)
+	}
+	log.Printf("ERROR: Ошибка входа через OIDC: %v", err)
+	return i18n.T(lang, "Не удалось войти через провайдера")
+}
+
+// redirectToLoginPage возвращает браузер на страницу входа с результатом во фрагменте адреса
+func (h *AuthHandler) redirectToLoginPage(w http.ResponseWriter, r *http.Request, result url.Values) {
+	http.Redirect(w, r, "/login.html#"+result.Encode(), http.StatusFound)
+}
+
 // --- ВСПОМОГАТЕЛЬНЫЕ МЕТОДЫ (Утилиты для чистоты кода) ---
 
-// respondWithError упрощает отправку сообщений об ошибках в формате JSON
-func (h *AuthHandler) respondWithError(w http.ResponseWriter, code int, message string) {
-	h.respondWithJSON(w, code, map[string]string{"error": message})
+// JWKS отдает публичные ключи подписи (GET /.well-known/jwks.json)
+// Другие сервисы могут проверять наши токены локально, не зная закрытых ключей.
+func (h *AuthHandler) JWKS(w http.ResponseWriter, r *http.Request) {
+	// Набор меняется только при ротации, поэтому его можно кэшировать
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	h.respondWithJSON(w, http.StatusOK, h.service.JWKS())
+}
+
+// respondWithTokens отправляет пару токенов в едином формате
+// В режиме cookie-сессии токены уходят в HttpOnly-cookie, а в теле возвращается CSRF-токен.
+func (h *AuthHandler) respondWithTokens(w http.ResponseWriter, r *http.Request, tokens *service.TokenPair) {
+	if h.cookies.Enabled() {
+		csrf, err := h.cookies.Set(w, tokens.AccessToken, tokens.RefreshToken)
+		if err != nil {
+			log.Printf("Ошибка выдачи cookie сессии: %v", err)
+			h.respondWithError(w, r, http.StatusInternalServerError, "Ошибка сервера при авторизации")
+			return
+		}
+		h.respondWithJSON(w, http.StatusOK, map[string]interface{}{
+			"token_type":           auth.SessionModeCookie,
+			"csrf_token":           csrf,
+			"expires_in":           tokens.ExpiresIn,
+			"must_change_password": tokens.MustChangePassword,
+			"mfa_setup_required":   tokens.MFASetupRequired,
+			"status":               "success",
+		})
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token":         tokens.AccessToken,
+		"refresh_token":        tokens.RefreshToken,
+		"token_type":           tokens.TokenType,
+		"expires_in":           tokens.ExpiresIn,
+		"must_change_password": tokens.MustChangePassword,
+		"mfa_setup_required":   tokens.MFASetupRequired,
+		"status":               "success",
+	})
+}
+
+// respondWithError отвечает об ошибке входа в формате RFC 7807.
+// Ошибки аутентификации не типизированы: статус (401, 429 и т.п.) выбирает сам хендлер.
+func (h *AuthHandler) respondWithError(w http.ResponseWriter, r *http.Request, code int, message string) {
+	writeErrorResponse(w, r, code, message)
 }
 
 // respondWithJSON унифицирует отправку успешных ответов и установку заголовков
@@ -5,26 +5,24 @@ import (
 	"sport-manager/internal/auth"
 )
 
-// checkAdminRole — внутренняя вспомогательная функция.
-// Она достает роль пользователя из контекста запроса (куда её ранее положил AuthMiddleware).
-func checkAdminRole(r *http.Request) bool {
-	// r.Context().Value позволяет получить данные, привязанные к текущему запросу.
-	// Приводим интерфейс к строке через .(string)
-	role, ok := r.Context().Value(auth.ContextKeyRole).(string)
-	if !ok {
-		return false // Если токен был пустой или роль не указана
-	}
-	return role == "admin"
+// currentClaims достает данные токена, которые ранее положил в контекст AuthMiddleware.
+// Возвращает nil для неаутентифицированного запроса.
+func currentClaims(r *http.Request) *auth.Claims {
+	claims, _ := r.Context().Value(auth.ContextKeyClaims).(*auth.Claims)
+	return claims
+}
+
+// hasPermission проверяет право текущего пользователя в области ресурса.
+// Используется внутри хендлеров, когда от прав зависит не доступ, а состав ответа.
+func hasPermission(r *http.Request, perm auth.Permission, scope auth.Scope) bool {
+	return currentClaims(r).HasPermission(perm, scope)
 }
 
-// requireAdmin — функция-страж.
-// Используется внутри хендлеров, чтобы пресечь действия не-админов.
-func requireAdmin(w http.ResponseWriter, r *http.Request) bool {
-	if !checkAdminRole(r) {
-		// Если не админ — сразу отдаем статус 403 (Доступ запрещен)
-		http.Error(w, "Доступ запрещен. Это действие доступно только администраторам.", http.StatusForbidden)
-		return false
+// includeDeleted разбирает параметр ?include_deleted=true. Мягко удаленные записи видит только
+// администратор: allowed == false означает, что параметр передал пользователь без права user:manage.
+func includeDeleted(r *http.Request) (include bool, allowed bool) {
+	if r.URL.Query().Get("include_deleted") != "true" {
+		return false, true
 	}
-	// Если админ — возвращаем true, и хендлер продолжает работу
-	return true
+	return true, hasPermission(r, auth.PermUserManage, auth.Scope{})
 }
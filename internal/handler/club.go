@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"sport-manager/internal/repository"
+	"sport-manager/internal/service"
+)
+
+// ClubHandler обрабатывает запросы, связанные со спортивными клубами
+type ClubHandler struct {
+	service *service.ClubService
+}
+
+// NewClubHandler создает новый экземпляр хендлера клубов
+func NewClubHandler(s *service.ClubService) *ClubHandler {
+	return &ClubHandler{service: s}
+}
+
+// ListClubs обрабатывает GET /api/v1/clubs
+func (h *ClubHandler) ListClubs(w http.ResponseWriter, r *http.Request) {
+	clubs, err := h.service.ListAll(r.Context())
+	if err != nil {
+		writeError(w, r, err, "Не удалось получить список клубов")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, clubs)
+}
+
+// CreateClub обрабатывает POST /api/v1/clubs
+func (h *ClubHandler) CreateClub(w http.ResponseWriter, r *http.Request) {
+	var club repository.Club
+	if err := json.NewDecoder(r.Body).Decode(&club); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Неверный формат данных")
+		return
+	}
+
+	if err := h.service.Create(r.Context(), &club); err != nil {
+		writeError(w, r, err, "Не удалось создать клуб")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusCreated, club)
+}
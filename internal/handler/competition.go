@@ -5,7 +5,6 @@ import (
 	"log"
 	"net/http"
 	"strconv"
-	"strings"
 
 	"sport-manager/internal/repository"
 	"sport-manager/internal/service"
@@ -30,38 +29,52 @@ func (h *CompetitionHandler) CreateCompetition(w http.ResponseWriter, r *http.Re
 	// Декодируем входящий JSON
 	if err := json.NewDecoder(r.Body).Decode(&competition); err != nil {
 		log.Printf("ERROR: Competition JSON Decode failed: %v", err)
-		writeErrorResponse(w, http.StatusBadRequest, "Неверный формат тела запроса или даты. Ожидается RFC3339 (например, 2025-12-14T00:00:00Z)")
+		writeErrorResponse(w, r, http.StatusBadRequest, "Неверный формат тела запроса или даты. Ожидается RFC3339 (например, 2025-12-14T00:00:00Z)")
 		return
 	}
 
 	// Вызываем сервис для сохранения в БД
 	if err := h.service.Create(r.Context(), &competition); err != nil {
-		log.Printf("ERROR: Competition creation failed: %v", err)
-
-		if strings.Contains(err.Error(), "validation error") {
-			writeErrorResponse(w, http.StatusBadRequest, err.Error())
-			return
-		}
-		writeErrorResponse(w, http.StatusInternalServerError, "Ошибка при создании соревнования на сервере")
+		writeError(w, r, err, "Ошибка при создании соревнования на сервере")
 		return
 	}
 
 	writeJSONResponse(w, http.StatusCreated, competition)
 }
 
-// ListCompetitions возвращает список всех доступных соревнований
+// ListCompetitions возвращает страницу списка соревнований:
+// GET /api/v1/competitions?name=кубок&location=казань&date_from=2025-01-01&date_to=2025-12-31&level=national&sport_id=2&sort=start_date&limit=50&cursor=...
+// Администратор может добавить удаленные соревнования через ?include_deleted=true.
 func (h *CompetitionHandler) ListCompetitions(w http.ResponseWriter, r *http.Request) {
-	competitions, err := h.service.ListAll(r.Context())
+	include, allowed := includeDeleted(r)
+	if !allowed {
+		writeErrorResponse(w, r, http.StatusForbidden, "Удаленные записи доступны только администратору")
+		return
+	}
+
+	q := newQueryParams(r)
+	f := repository.CompetitionFilter{
+		Name:           q.String("name"),
+		Location:       q.String("location"),
+		DateFrom:       q.Date("date_from"),
+		DateTo:         q.Date("date_to"),
+		Level:          q.String("level"),
+		SportID:        q.Int("sport_id"),
+		IncludeDeleted: include,
+	}
+	page := q.Page()
+	if err := q.Err(); err != nil {
+		writeError(w, r, err, "")
+		return
+	}
+
+	competitions, info, err := h.service.List(r.Context(), f, page)
 	if err != nil {
-		log.Printf("ERROR: Failed to list competitions: %v", err)
-		writeErrorResponse(w, http.StatusInternalServerError, "Не удалось получить список соревнований")
+		writeError(w, r, err, "Не удалось получить список соревнований")
 		return
 	}
 
-	// Формируем ответ в формате, который ожидает фронтенд
-	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
-		"competitions": competitions,
-	})
+	writePage(w, r, "competitions", competitions, info)
 }
 
 // GetCompetition возвращает детальную информацию об одном соревновании по ID
@@ -69,73 +82,211 @@ func (h *CompetitionHandler) GetCompetition(w http.ResponseWriter, r *http.Reque
 	vars := mux.Vars(r)
 	id, err := strconv.Atoi(vars["id"])
 	if err != nil {
-		writeErrorResponse(w, http.StatusBadRequest, "Некорректный ID соревнования")
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID соревнования")
 		return
 	}
 
-	competition, err := h.service.GetByID(r.Context(), id)
+	// ?as_of= показывает соревнование в том виде, в каком оно было на указанную дату
+	at, asOf, err := parseAsOf(r)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			writeErrorResponse(w, http.StatusNotFound, "Соревнование не найдено")
-			return
-		}
-		log.Printf("ERROR: Failed to get competition: %v", err)
-		writeErrorResponse(w, http.StatusInternalServerError, "Ошибка при поиске соревнования")
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный as_of: ожидается дата ГГГГ-ММ-ДД или время RFC 3339")
 		return
 	}
 
-	writeJSONResponse(w, http.StatusOK, competition)
+	include, allowed := includeDeleted(r)
+	if !allowed {
+		writeErrorResponse(w, r, http.StatusForbidden, "Удаленные записи доступны только администратору")
+		return
+	}
+
+	var competition *repository.Competition
+	if asOf {
+		competition, err = h.service.GetAsOf(r.Context(), id, at)
+	} else {
+		competition, err = h.service.GetByID(r.Context(), id, include)
+	}
+	if err != nil {
+		writeError(w, r, err, "Ошибка при поиске соревнования")
+		return
+	}
+
+	writeVersioned(w, r, competition.Version, competition)
 }
 
-// UpdateCompetition обновляет данные существующего соревнования
+// UpdateCompetition обновляет данные существующего соревнования.
+// Требует заголовок If-Match с ETag, полученным при чтении соревнования.
 func (h *CompetitionHandler) UpdateCompetition(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id, err := strconv.Atoi(vars["id"])
 	if err != nil {
-		writeErrorResponse(w, http.StatusBadRequest, "Некорректный ID соревнования")
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID соревнования")
+		return
+	}
+
+	version, ok := requireIfMatch(w, r)
+	if !ok {
 		return
 	}
 
 	var competition repository.Competition
 	if err := json.NewDecoder(r.Body).Decode(&competition); err != nil {
-		writeErrorResponse(w, http.StatusBadRequest, "Неверный формат данных")
+		writeErrorResponse(w, r, http.StatusBadRequest, "Неверный формат данных")
 		return
 	}
 
 	competition.ID = id // Принудительно ставим ID из URL
 
-	if err := h.service.Update(r.Context(), &competition); err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			writeErrorResponse(w, http.StatusNotFound, "Соревнование не найдено")
-			return
-		}
-		log.Printf("ERROR: Competition update failed: %v", err)
-		writeErrorResponse(w, http.StatusInternalServerError, "Ошибка при обновлении данных")
+	if err := h.service.Update(r.Context(), &competition, version); err != nil {
+		writeError(w, r, err, "Ошибка при обновлении данных")
+		return
+	}
+
+	w.Header().Set("ETag", versionETag(competition.Version))
+	writeJSONResponse(w, http.StatusOK, competition)
+}
+
+// PatchCompetition частично обновляет соревнование (JSON Merge Patch, RFC 7396); требует If-Match.
+func (h *CompetitionHandler) PatchCompetition(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID соревнования")
+		return
+	}
+
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+	fields, ok := decodeMergePatch(w, r)
+	if !ok {
+		return
+	}
+
+	competition, err := h.service.Patch(r.Context(), id, fields, version)
+	if err != nil {
+		writeError(w, r, err, "Ошибка при обновлении данных")
 		return
 	}
 
+	w.Header().Set("ETag", versionETag(competition.Version))
 	writeJSONResponse(w, http.StatusOK, competition)
 }
 
-// DeleteCompetition удаляет соревнование из системы
+// DeleteCompetition удаляет соревнование из системы (с заголовком If-Match)
 func (h *CompetitionHandler) DeleteCompetition(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id, err := strconv.Atoi(vars["id"])
 	if err != nil {
-		writeErrorResponse(w, http.StatusBadRequest, "Некорректный ID соревнования")
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID соревнования")
 		return
 	}
 
-	if err := h.service.Delete(r.Context(), id); err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			writeErrorResponse(w, http.StatusNotFound, "Соревнование не найдено")
-			return
-		}
-		log.Printf("ERROR: Competition deletion failed: %v", err)
-		writeErrorResponse(w, http.StatusInternalServerError, "Не удалось удалить соревнование")
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id, version); err != nil {
+		writeError(w, r, err, "Не удалось удалить соревнование")
 		return
 	}
 
 	// Возвращаем статус 204 (успешно, без тела ответа)
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// RestoreCompetition восстанавливает удаленное соревнование
+// POST /api/v1/competitions/{id}/restore
+func (h *CompetitionHandler) RestoreCompetition(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID соревнования")
+		return
+	}
+
+	if err := h.service.Restore(r.Context(), id); err != nil {
+		writeError(w, r, err, "Ошибка при восстановлении соревнования")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]string{"status": "success"})
+}
+
+// PurgeCompetition безвозвратно удаляет ранее удаленное соревнование вместе с участиями и результатами
+// DELETE /api/v1/competitions/{id}/purge
+func (h *CompetitionHandler) PurgeCompetition(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID соревнования")
+		return
+	}
+
+	if err := h.service.Purge(r.Context(), id); err != nil {
+		writeError(w, r, err, "Ошибка при удалении соревнования")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListEntryStandards возвращает отборочные нормативы соревнования
+func (h *CompetitionHandler) ListEntryStandards(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID соревнования")
+		return
+	}
+
+	standards, err := h.service.ListEntryStandards(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err, "Не удалось получить отборочные нормативы")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, standards)
+}
+
+// CreateEntryStandard добавляет отборочный норматив к соревнованию
+func (h *CompetitionHandler) CreateEntryStandard(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID соревнования")
+		return
+	}
+
+	var standard repository.EntryStandard
+	if err := json.NewDecoder(r.Body).Decode(&standard); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Неверный формат данных")
+		return
+	}
+	standard.CompetitionID = id // Принудительно ставим ID из URL
+
+	if err := h.service.CreateEntryStandard(r.Context(), &standard); err != nil {
+		writeError(w, r, err, "Не удалось создать отборочный норматив")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusCreated, standard)
+}
+
+// DeleteEntryStandard удаляет отборочный норматив соревнования
+func (h *CompetitionHandler) DeleteEntryStandard(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID соревнования")
+		return
+	}
+	standardID, err := strconv.Atoi(vars["standardId"])
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID норматива")
+		return
+	}
+
+	if err := h.service.DeleteEntryStandard(r.Context(), id, standardID); err != nil {
+		writeError(w, r, err, "Не удалось удалить норматив")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
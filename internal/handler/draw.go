@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"sport-manager/internal/auth"
+	"sport-manager/internal/service"
+
+	"github.com/gorilla/mux"
+)
+
+// DrawHandler обрабатывает запросы на объявление, просмотр и проверку жеребьевок
+type DrawHandler struct {
+	service *service.DrawService
+}
+
+// NewDrawHandler создает новый экземпляр хендлера жеребьевок
+func NewDrawHandler(s *service.DrawService) *DrawHandler {
+	return &DrawHandler{service: s}
+}
+
+// CommitDraw обрабатывает POST /api/v1/competitions/{id}/draws
+// Создает жеребьевку и публикует хеш зерна. Тело: {"kind": "start_order"}
+func (h *DrawHandler) CommitDraw(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID соревнования")
+		return
+	}
+
+	var input struct {
+		Kind string `json:"kind"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Неверный формат данных")
+		return
+	}
+
+	username, _ := r.Context().Value(auth.ContextKeyUsername).(string)
+
+	d, err := h.service.Commit(r.Context(), id, input.Kind, username)
+	if err != nil {
+		writeError(w, r, err, "Не удалось объявить жеребьевку")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusCreated, d)
+}
+
+// ListDraws обрабатывает GET /api/v1/competitions/{id}/draws
+func (h *DrawHandler) ListDraws(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID соревнования")
+		return
+	}
+
+	draws, err := h.service.ListByCompetition(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err, "Не удалось получить список жеребьевок")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, draws)
+}
+
+// GetDraw обрабатывает GET /api/v1/draws/{id}
+func (h *DrawHandler) GetDraw(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID жеребьевки")
+		return
+	}
+
+	d, err := h.service.Get(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err, "Не удалось получить жеребьевку")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, d)
+}
+
+// VerifyDraw обрабатывает GET /api/v1/draws/{id}/verify
+// Повторно проводит жеребьевку по раскрытому зерну и сравнивает с сохраненным результатом.
+func (h *DrawHandler) VerifyDraw(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID жеребьевки")
+		return
+	}
+
+	report, err := h.service.Verify(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err, "Не удалось проверить жеребьевку")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, report)
+}
+
+// ReplayDraw обрабатывает POST /api/v1/draws/replay
+// Проводит жеребьевку по переданному зерну и входным данным. Тело: {"seed": "<hex>", "items": [..]}
+func (h *DrawHandler) ReplayDraw(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Seed  string `json:"seed"`
+		Items []int  `json:"items"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Неверный формат данных")
+		return
+	}
+
+	hash, result, err := h.service.Replay(input.Seed, input.Items)
+	if err != nil {
+		writeError(w, r, err, "Не удалось провести жеребьевку")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"seed_hash": hash,
+		"result":    result,
+	})
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"sport-manager/internal/i18n"
+	"sport-manager/internal/problem"
+	"sport-manager/internal/repository"
+	"sport-manager/internal/service"
+)
+
+// errorKinds сопоставляет сигнальные ошибки сервисов и репозиториев с HTTP-статусом и кодом ответа.
+var errorKinds = []struct {
+	kind   error
+	status int
+	code   string
+}{
+	{repository.ErrNotFound, http.StatusNotFound, problem.CodeNotFound},
+	{repository.ErrConflict, http.StatusConflict, problem.CodeConflict},
+	{service.ErrUnauthorized, http.StatusUnauthorized, problem.CodeUnauthorized},
+	{service.ErrForbidden, http.StatusForbidden, problem.CodeForbidden},
+	{repository.ErrVersionConflict, http.StatusPreconditionFailed, problem.CodeVersionConflict},
+	{repository.ErrVersionRequired, http.StatusPreconditionRequired, problem.CodePreconditionRequired},
+}
+
+// writeError — единственное место, где ошибка сервиса превращается в HTTP-ответ (RFC 7807):
+// ошибка валидации — 422 со списком полей, не найдено — 404, конфликт — 409, вход не подтвержден — 401,
+// нет прав — 403, устаревшая версия — 412, версия не передана — 428. Остальное считается сбоем сервера:
+// подробности уходят в лог, клиент получает 500 с нейтральным текстом fallback.
+// Сообщения переводятся на язык запроса (профиль пользователя или Accept-Language).
+func writeError(w http.ResponseWriter, r *http.Request, err error, fallback string) {
+	var ve *service.ValidationError
+	if errors.As(err, &ve) {
+		p := problem.New(r, http.StatusUnprocessableEntity, problem.CodeValidation, "Ошибка валидации данных")
+		p.Fields = ve.Localized(i18n.FromRequest(r))
+		p.Write(w)
+		return
+	}
+
+	for _, k := range errorKinds {
+		if !errors.Is(err, k.kind) {
+			continue
+		}
+		// Клиенту — сообщение предметной области без префиксов слоев ("service: ...")
+		var de *repository.DomainError
+		if errors.As(err, &de) {
+			problem.Write(w, r, k.status, k.code, de.Format, de.Args...)
+		} else {
+			problem.Write(w, r, k.status, k.code, k.kind.Error())
+		}
+		return
+	}
+
+	log.Printf("ERROR: %s: %v", fallback, err)
+	problem.Write(w, r, http.StatusInternalServerError, problem.CodeInternal, fallback)
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"sport-manager/internal/repository"
+)
+
+// versionETag формирует строгий ETag записи по номеру ее версии.
+func versionETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// writeVersioned отдает запись с ETag ее версии. Если клиент прислал тот же ETag
+// в If-None-Match, отвечает 304 без тела — так опрос изменений почти ничего не стоит.
+func writeVersioned(w http.ResponseWriter, r *http.Request, version int, data interface{}) {
+	tag := versionETag(version)
+	w.Header().Set("ETag", tag)
+	if etagMatches(r.Header.Get("If-None-Match"), tag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	writeJSONResponse(w, http.StatusOK, data)
+}
+
+// writeJSONWithETag отдает список со слабым ETag — хешем тела ответа.
+// У списка нет общего номера версии, но повторный опрос без изменений также получает 304.
+func writeJSONWithETag(w http.ResponseWriter, r *http.Request, data interface{}) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("CRITICAL: Failed to encode JSON response: %v", err)
+		writeErrorResponse(w, r, http.StatusInternalServerError, "Ошибка формирования ответа")
+		return
+	}
+	sum := sha256.Sum256(body)
+	tag := `W/"` + hex.EncodeToString(sum[:16]) + `"`
+
+	w.Header().Set("ETag", tag)
+	if etagMatches(r.Header.Get("If-None-Match"), tag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(append(body, '\n'))
+}
+
+// etagMatches сравнивает ETag со списком из If-None-Match (слабое сравнение, RFC 9110).
+func etagMatches(header, tag string) bool {
+	if header == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(tag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// parseIfMatch разбирает If-Match: возвращает ожидаемую версию записи,
+// repository.AnyVersion для '*' и present == false, если заголовка нет.
+// Слабый или чужой ETag не может совпасть ни с одной версией — это ошибка.
+func parseIfMatch(r *http.Request) (version int, present bool, err error) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" {
+		return 0, false, nil
+	}
+	if header == "*" {
+		return repository.AnyVersion, true, nil
+	}
+	if !strings.HasPrefix(header, `"`) || !strings.HasSuffix(header, `"`) || len(header) < 2 {
+		return 0, true, repository.ErrVersionConflict
+	}
+	version, err = strconv.Atoi(header[1 : len(header)-1])
+	if err != nil || version <= 0 {
+		return 0, true, repository.ErrVersionConflict
+	}
+	return version, true, nil
+}
+
+// requireIfMatch возвращает версию из обязательного заголовка If-Match.
+// Без заголовка отвечает 428, при неразборчивом ETag — 412; ok == false — ответ уже записан.
+func requireIfMatch(w http.ResponseWriter, r *http.Request) (version int, ok bool) {
+	version, present, err := parseIfMatch(r)
+	if !present {
+		writeError(w, r, repository.ErrVersionRequired, "")
+		return 0, false
+	}
+	if err != nil {
+		writeError(w, r, err, "")
+		return 0, false
+	}
+	return version, true
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"sport-manager/internal/repository"
+)
+
+func TestEtagMatches(t *testing.T) {
+	tag := `W/"abc"`
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{"", false},
+		{`W/"abc"`, true},
+		{`"abc"`, true}, // слабое сравнение не различает W/
+		{`"xyz", W/"abc"`, true},
+		{"*", true},
+		{`"xyz"`, false},
+		{`"ab"`, false},
+	}
+	for _, tt := range tests {
+		if got := etagMatches(tt.header, tag); got != tt.want {
+			t.Errorf("etagMatches(%q, %q) = %v, want %v", tt.header, tag, got, tt.want)
+		}
+	}
+}
+
+func TestParseIfMatch(t *testing.T) {
+	tests := []struct {
+		header  string
+		version int
+		present bool
+		err     error
+	}{
+		{"", 0, false, nil},
+		{"*", repository.AnyVersion, true, nil},
+		{`"3"`, 3, true, nil},
+		{` "12" `, 12, true, nil},
+		{`W/"3"`, 0, true, repository.ErrVersionConflict},
+		{`"abc"`, 0, true, repository.ErrVersionConflict},
+		{`"0"`, 0, true, repository.ErrVersionConflict},
+		{`"-1"`, 0, true, repository.ErrVersionConflict},
+		{`"`, 0, true, repository.ErrVersionConflict},
+		{`3`, 0, true, repository.ErrVersionConflict},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("PUT", "/api/v1/athletes/1", nil)
+		if tt.header != "" {
+			r.Header.Set("If-Match", tt.header)
+		}
+		version, present, err := parseIfMatch(r)
+		if version != tt.version || present != tt.present || !errors.Is(err, tt.err) {
+			t.Errorf("parseIfMatch(%q) = (%d, %v, %v), want (%d, %v, %v)",
+				tt.header, version, present, err, tt.version, tt.present, tt.err)
+		}
+	}
+}
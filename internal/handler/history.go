@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"sport-manager/internal/service"
+
+	"github.com/gorilla/mux"
+)
+
+// HistoryHandler обрабатывает просмотр истории версий спортсменов и соревнований
+type HistoryHandler struct {
+	service *service.HistoryService
+}
+
+// NewHistoryHandler создает новый экземпляр хендлера истории версий
+func NewHistoryHandler(s *service.HistoryService) *HistoryHandler {
+	return &HistoryHandler{service: s}
+}
+
+// AthleteHistory обрабатывает GET /api/v1/athletes/{id}/history
+func (h *HistoryHandler) AthleteHistory(w http.ResponseWriter, r *http.Request) {
+	h.history(w, r, "athlete")
+}
+
+// AthleteDiff обрабатывает GET /api/v1/athletes/{id}/history/diff?from=1&to=3
+func (h *HistoryHandler) AthleteDiff(w http.ResponseWriter, r *http.Request) {
+	h.diff(w, r, "athlete")
+}
+
+// CompetitionHistory обрабатывает GET /api/v1/competitions/{id}/history
+func (h *HistoryHandler) CompetitionHistory(w http.ResponseWriter, r *http.Request) {
+	h.history(w, r, "competition")
+}
+
+// CompetitionDiff обрабатывает GET /api/v1/competitions/{id}/history/diff?from=1&to=3
+func (h *HistoryHandler) CompetitionDiff(w http.ResponseWriter, r *http.Request) {
+	h.diff(w, r, "competition")
+}
+
+// history возвращает все версии записи с изменениями относительно предыдущей.
+func (h *HistoryHandler) history(w http.ResponseWriter, r *http.Request, entity string) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID")
+		return
+	}
+
+	entries, err := h.service.History(r.Context(), entity, id)
+	if err != nil {
+		writeError(w, r, err, "Не удалось получить историю изменений")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, entries)
+}
+
+// diff сравнивает две версии записи; без параметров — последнюю с предыдущей.
+func (h *HistoryHandler) diff(w http.ResponseWriter, r *http.Request, entity string) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID")
+		return
+	}
+
+	var from, to int
+	if v := r.URL.Query().Get("from"); v != "" {
+		if from, err = strconv.Atoi(v); err != nil {
+			writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный номер версии from")
+			return
+		}
+	}
+	if v := r.URL.Query().Get("to"); v != "" {
+		if to, err = strconv.Atoi(v); err != nil {
+			writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный номер версии to")
+			return
+		}
+	}
+
+	diff, err := h.service.Diff(r.Context(), entity, id, from, to)
+	if err != nil {
+		writeError(w, r, err, "Не удалось сравнить версии")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, diff)
+}
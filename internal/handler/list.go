@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"sport-manager/internal/repository"
+	"sport-manager/internal/service"
+)
+
+// queryParams разбирает параметры строки запроса списка. Ошибки формата накапливаются по полям,
+// чтобы клиент получил их все одним ответом 422, как при проверке тела запроса.
+type queryParams struct {
+	values url.Values
+	errs   []service.FieldError
+}
+
+func newQueryParams(r *http.Request) *queryParams {
+	return &queryParams{values: r.URL.Query()}
+}
+
+// String возвращает параметр без пробелов по краям.
+func (p *queryParams) String(name string) string {
+	return strings.TrimSpace(p.values.Get(name))
+}
+
+// Int возвращает целочисленный параметр (0, если он не передан).
+func (p *queryParams) Int(name string) int {
+	v := p.String(name)
+	if v == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		p.fail(name, "должно быть целым числом")
+	}
+	return n
+}
+
+// Bool возвращает логический параметр (nil, если он не передан).
+func (p *queryParams) Bool(name string) *bool {
+	v := p.String(name)
+	if v == "" {
+		return nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		p.fail(name, "должно быть true или false")
+		return nil
+	}
+	return &b
+}
+
+// Date возвращает параметр-дату в формате ГГГГ-ММ-ДД (нулевое время, если он не передан).
+func (p *queryParams) Date(name string) time.Time {
+	v := p.String(name)
+	if v == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse("2006-01-02", v)
+	if err != nil {
+		p.fail(name, "должно быть датой в формате ГГГГ-ММ-ДД")
+	}
+	return t
+}
+
+// Page возвращает общие параметры страницы: sort, limit и cursor.
+func (p *queryParams) Page() service.PageRequest {
+	return service.PageRequest{Sort: p.String("sort"), Limit: p.Int("limit"), Cursor: p.String("cursor")}
+}
+
+func (p *queryParams) fail(name, message string) {
+	p.errs = append(p.errs, service.FieldError{Field: name, Code: service.CodeInvalid, Message: message})
+}
+
+// Err возвращает ошибку валидации со всеми нарушениями формата или nil.
+func (p *queryParams) Err() error {
+	if len(p.errs) == 0 {
+		return nil
+	}
+	return &service.ValidationError{Fields: p.errs}
+}
+
+// writePage отвечает страницей списка: элементы под именем коллекции, общее число записей по фильтру
+// и ссылка на следующую страницу (в поле next и в заголовке Link), если она есть.
+func writePage(w http.ResponseWriter, r *http.Request, collection string, items interface{}, info *repository.PageInfo) {
+	body := map[string]interface{}{
+		collection: items,
+		"total":    info.Total,
+	}
+	if info.Next != nil {
+		q := r.URL.Query()
+		q.Set("cursor", info.Next.Encode())
+		next := r.URL.Path + "?" + q.Encode()
+		body["next"] = next
+		w.Header().Set("Link", "<"+next+`>; rel="next"`)
+	}
+	writeJSONWithETag(w, r, body)
+}
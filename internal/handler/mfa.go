@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"sport-manager/internal/service"
+
+	"github.com/gorilla/mux"
+)
+
+// MFAHandler обрабатывает подключение двухфакторной аутентификации и политику безопасности
+type MFAHandler struct {
+	service *service.MFAService
+}
+
+// NewMFAHandler создает новый экземпляр хендлера 2FA
+func NewMFAHandler(s *service.MFAService) *MFAHandler {
+	return &MFAHandler{service: s}
+}
+
+// mfaCodeRequest — код подтверждения из приложения (или резервный код)
+type mfaCodeRequest struct {
+	Code     string `json:"code"`
+	Password string `json:"password"` // Нужен только для отключения 2FA
+}
+
+// Enroll обрабатывает POST /api/v1/me/2fa/enroll
+// Возвращает секрет и otpauth://-ссылку для QR-кода. 2FA включится после подтверждения кодом.
+func (h *MFAHandler) Enroll(w http.ResponseWriter, r *http.Request) {
+	enrollment, err := h.service.Enroll(r.Context(), currentClaims(r))
+	if err != nil {
+		writeError(w, r, err, "Не удалось начать подключение 2FA")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, enrollment)
+}
+
+// Confirm обрабатывает POST /api/v1/me/2fa/confirm
+// Тело: {"code": "123456"}. В ответ — резервные коды, которые больше не будут показаны.
+// Если 2FA требовалась политикой, новый токен без ограничения выдается через /auth/refresh.
+func (h *MFAHandler) Confirm(w http.ResponseWriter, r *http.Request) {
+	var req mfaCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Code == "" {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Необходимо передать code")
+		return
+	}
+
+	codes, err := h.service.Confirm(r.Context(), currentClaims(r), clientIP(r), req.Code)
+	if err != nil {
+		writeError(w, r, err, "Не удалось подключить 2FA")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{"status": "success", "recovery_codes": codes})
+}
+
+// Disable обрабатывает DELETE /api/v1/me/2fa
+// Тело: {"password": "...", "code": "123456"}
+func (h *MFAHandler) Disable(w http.ResponseWriter, r *http.Request) {
+	var req mfaCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Code == "" {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Необходимо передать password и code")
+		return
+	}
+
+	if err := h.service.Disable(r.Context(), currentClaims(r), clientIP(r), req.Password, req.Code); err != nil {
+		writeError(w, r, err, "Не удалось отключить 2FA")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]string{"status": "success"})
+}
+
+// RegenerateRecoveryCodes обрабатывает POST /api/v1/me/2fa/recovery-codes
+// Тело: {"code": "123456"}. Старые резервные коды перестают действовать.
+func (h *MFAHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	var req mfaCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Code == "" {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Необходимо передать code")
+		return
+	}
+
+	codes, err := h.service.RegenerateRecoveryCodes(r.Context(), currentClaims(r), clientIP(r), req.Code)
+	if err != nil {
+		writeError(w, r, err, "Не удалось обновить резервные коды")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{"status": "success", "recovery_codes": codes})
+}
+
+// ResetUser обрабатывает DELETE /api/v1/users/{id}/2fa
+// Отключает 2FA пользователю, потерявшему устройство (только администратор).
+func (h *MFAHandler) ResetUser(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID пользователя")
+		return
+	}
+
+	if err := h.service.Reset(r.Context(), currentClaims(r), clientIP(r), id); err != nil {
+		writeError(w, r, err, "Не удалось сбросить 2FA")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]string{"status": "success"})
+}
+
+// GetSecuritySettings обрабатывает GET /api/v1/security/settings
+func (h *MFAHandler) GetSecuritySettings(w http.ResponseWriter, r *http.Request) {
+	policy, err := h.service.Policy(r.Context())
+	if err != nil {
+		writeError(w, r, err, "Не удалось получить настройки безопасности")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, policy)
+}
+
+// UpdateSecuritySettings обрабатывает PUT /api/v1/security/settings
+// Тело: {"require_2fa_privileged": true}
+func (h *MFAHandler) UpdateSecuritySettings(w http.ResponseWriter, r *http.Request) {
+	var policy service.SecurityPolicy
+	if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный формат запроса")
+		return
+	}
+
+	if err := h.service.UpdatePolicy(r.Context(), currentClaims(r), clientIP(r), &policy); err != nil {
+		writeError(w, r, err, "Не удалось сохранить настройки безопасности")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, policy)
+}
@@ -2,10 +2,10 @@ package handler
 
 import (
 	"encoding/json"
-	"fmt"
 	"net/http"
 	"strconv"
 
+	"sport-manager/internal/i18n"
 	"sport-manager/internal/repository"
 	"sport-manager/internal/service"
 
@@ -28,28 +28,49 @@ func (h *ParticipationHandler) CreateParticipation(w http.ResponseWriter, r *htt
 
 	// Декодируем ID атлета и соревнования из JSON
 	if err := json.NewDecoder(r.Body).Decode(&participation); err != nil {
-		writeErrorResponse(w, http.StatusBadRequest, "Некорректный формат данных в запросе")
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный формат данных в запросе")
 		return
 	}
 
 	// Поле Place по умолчанию будет 0 (результат еще не определен)
 	if err := h.service.Create(r.Context(), &participation); err != nil {
-		writeErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Ошибка при создании регистрации: %v", err))
+		writeError(w, r, err, "Ошибка при создании регистрации")
 		return
 	}
 
 	writeJSONResponse(w, http.StatusCreated, participation)
 }
 
-// ListParticipations возвращает список всех регистраций с именами атлетов и турниров
+// ListParticipations возвращает страницу списка регистраций с именами атлетов и турниров:
+// GET /api/v1/participations?competition_id=7&athlete_id=12&event=100m&sort=athlete_name&limit=50&cursor=...
+// Администратор может добавить удаленные записи через ?include_deleted=true.
 func (h *ParticipationHandler) ListParticipations(w http.ResponseWriter, r *http.Request) {
-	participations, err := h.service.ListAll(r.Context())
+	include, allowed := includeDeleted(r)
+	if !allowed {
+		writeErrorResponse(w, r, http.StatusForbidden, "Удаленные записи доступны только администратору")
+		return
+	}
+
+	q := newQueryParams(r)
+	f := repository.ParticipationFilter{
+		CompetitionID:  q.Int("competition_id"),
+		AthleteID:      q.Int("athlete_id"),
+		Event:          q.String("event"),
+		IncludeDeleted: include,
+	}
+	page := q.Page()
+	if err := q.Err(); err != nil {
+		writeError(w, r, err, "")
+		return
+	}
+
+	participations, info, err := h.service.List(r.Context(), f, page)
 	if err != nil {
-		writeErrorResponse(w, http.StatusInternalServerError, "Не удалось получить список регистраций")
+		writeError(w, r, err, "Не удалось получить список регистраций")
 		return
 	}
 
-	writeJSONResponse(w, http.StatusOK, participations)
+	writePage(w, r, "participations", participations, info)
 }
 
 // UpdatePlace обновляет занятое спортсменом место в рамках соревнования
@@ -57,7 +78,7 @@ func (h *ParticipationHandler) UpdatePlace(w http.ResponseWriter, r *http.Reques
 	vars := mux.Vars(r)
 	id, err := strconv.Atoi(vars["id"])
 	if err != nil {
-		writeErrorResponse(w, http.StatusBadRequest, "Некорректный ID записи участия")
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID записи участия")
 		return
 	}
 
@@ -67,18 +88,18 @@ func (h *ParticipationHandler) UpdatePlace(w http.ResponseWriter, r *http.Reques
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
-		writeErrorResponse(w, http.StatusBadRequest, "Необходимо указать корректное число в поле 'place'")
+		writeErrorResponse(w, r, http.StatusBadRequest, "Необходимо указать корректное число в поле 'place'")
 		return
 	}
 
 	// Обновляем только результат (место)
 	if err := h.service.UpdatePlace(r.Context(), id, requestBody.Place); err != nil {
-		writeErrorResponse(w, http.StatusInternalServerError, "Не удалось обновить результат")
+		writeError(w, r, err, "Не удалось обновить результат")
 		return
 	}
 
 	writeJSONResponse(w, http.StatusOK, map[string]string{
-		"message": "Результат успешно обновлен",
+		"message": i18n.T(i18n.FromRequest(r), "Результат успешно обновлен"),
 	})
 }
 
@@ -87,15 +108,76 @@ func (h *ParticipationHandler) DeleteParticipation(w http.ResponseWriter, r *htt
 	vars := mux.Vars(r)
 	id, err := strconv.Atoi(vars["id"])
 	if err != nil {
-		writeErrorResponse(w, http.StatusBadRequest, "Некорректный ID записи")
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID записи")
 		return
 	}
 
 	if err := h.service.Delete(r.Context(), id); err != nil {
-		writeErrorResponse(w, http.StatusInternalServerError, "Ошибка при удалении записи из системы")
+		writeError(w, r, err, "Ошибка при удалении записи из системы")
 		return
 	}
 
 	// Возвращаем 204 No Content (успех без тела ответа)
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// RestoreParticipation восстанавливает аннулированную регистрацию
+// POST /api/v1/participations/{id}/restore
+func (h *ParticipationHandler) RestoreParticipation(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID записи")
+		return
+	}
+
+	if err := h.service.Restore(r.Context(), id); err != nil {
+		writeError(w, r, err, "Ошибка при восстановлении записи")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]string{"status": "success"})
+}
+
+// PurgeParticipation безвозвратно удаляет аннулированную регистрацию вместе с результатом
+// DELETE /api/v1/participations/{id}/purge
+func (h *ParticipationHandler) PurgeParticipation(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID записи")
+		return
+	}
+
+	if err := h.service.Purge(r.Context(), id); err != nil {
+		writeError(w, r, err, "Ошибка при удалении записи")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SeedHeats распределяет участников дисциплины по забегам на основе заявочных результатов
+// GET /api/v1/competitions/{id}/heats?event=100m&lanes=8
+func (h *ParticipationHandler) SeedHeats(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID соревнования")
+		return
+	}
+
+	// По умолчанию — стандартная беговая дорожка на 8 дорожек
+	lanes := 8
+	if v := r.URL.Query().Get("lanes"); v != "" {
+		if lanes, err = strconv.Atoi(v); err != nil {
+			writeErrorResponse(w, r, http.StatusBadRequest, "Параметр 'lanes' должен быть числом")
+			return
+		}
+	}
+
+	heats, err := h.service.SeedHeats(r.Context(), id, r.URL.Query().Get("event"), lanes)
+	if err != nil {
+		writeError(w, r, err, "Не удалось распределить участников по забегам")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, heats)
+}
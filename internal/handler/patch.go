@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/http"
+)
+
+// mergePatchContentType — тип тела PATCH-запросов (JSON Merge Patch, RFC 7396).
+const mergePatchContentType = "application/merge-patch+json"
+
+// decodeMergePatch читает тело PATCH-запроса как JSON Merge Patch.
+// Принимается application/merge-patch+json и, для простых клиентов, application/json.
+// Патч должен быть объектом: замена ресурса целиком — это PUT. ok == false — ответ уже записан.
+func decodeMergePatch(w http.ResponseWriter, r *http.Request) (fields map[string]json.RawMessage, ok bool) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || (mediaType != mergePatchContentType && mediaType != "application/json") {
+		w.Header().Set("Accept-Patch", mergePatchContentType)
+		writeErrorResponse(w, r, http.StatusUnsupportedMediaType, "Ожидается тело типа %s", mergePatchContentType)
+		return nil, false
+	}
+
+	var body json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Неверный формат JSON")
+		return nil, false
+	}
+	if !bytes.HasPrefix(bytes.TrimSpace(body), []byte("{")) {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Патч должен быть JSON-объектом")
+		return nil, false
+	}
+	if err := json.Unmarshal(body, &fields); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Неверный формат JSON")
+		return nil, false
+	}
+	return fields, true
+}
@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"sport-manager/internal/auth"
+	"sport-manager/internal/repository"
+	"sport-manager/internal/service"
+
+	"github.com/gorilla/mux"
+)
+
+// RankHandler обрабатывает запросы, связанные с разрядами, нормативами и их присвоением
+type RankHandler struct {
+	service *service.RankService
+}
+
+// NewRankHandler создает новый экземпляр хендлера разрядов
+func NewRankHandler(s *service.RankService) *RankHandler {
+	return &RankHandler{service: s}
+}
+
+// --- СПРАВОЧНИК РАЗРЯДОВ ---
+
+// ListRanks обрабатывает GET /api/v1/ranks
+func (h *RankHandler) ListRanks(w http.ResponseWriter, r *http.Request) {
+	ranks, err := h.service.ListRanks(r.Context())
+	if err != nil {
+		writeError(w, r, err, "Не удалось получить список разрядов")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, ranks)
+}
+
+// CreateRank обрабатывает POST /api/v1/ranks
+func (h *RankHandler) CreateRank(w http.ResponseWriter, r *http.Request) {
+	var rank repository.Rank
+	if err := json.NewDecoder(r.Body).Decode(&rank); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Неверный формат данных")
+		return
+	}
+
+	if err := h.service.CreateRank(r.Context(), &rank); err != nil {
+		writeError(w, r, err, "Не удалось создать разряд")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusCreated, rank)
+}
+
+// UpdateRank обрабатывает PUT /api/v1/ranks/{id}
+func (h *RankHandler) UpdateRank(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID разряда")
+		return
+	}
+
+	var rank repository.Rank
+	if err := json.NewDecoder(r.Body).Decode(&rank); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Неверный формат данных")
+		return
+	}
+	rank.ID = id
+
+	if err := h.service.UpdateRank(r.Context(), &rank); err != nil {
+		writeError(w, r, err, "Не удалось обновить разряд")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, rank)
+}
+
+// --- НОРМАТИВЫ ---
+
+// ListStandards обрабатывает GET /api/v1/rank-standards
+func (h *RankHandler) ListStandards(w http.ResponseWriter, r *http.Request) {
+	standards, err := h.service.ListStandards(r.Context())
+	if err != nil {
+		writeError(w, r, err, "Не удалось получить список нормативов")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, standards)
+}
+
+// CreateStandard обрабатывает POST /api/v1/rank-standards
+func (h *RankHandler) CreateStandard(w http.ResponseWriter, r *http.Request) {
+	var standard repository.RankStandard
+	if err := json.NewDecoder(r.Body).Decode(&standard); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Неверный формат данных")
+		return
+	}
+
+	if err := h.service.CreateStandard(r.Context(), &standard); err != nil {
+		writeError(w, r, err, "Не удалось создать норматив")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusCreated, standard)
+}
+
+// DeleteStandard обрабатывает DELETE /api/v1/rank-standards/{id}
+func (h *RankHandler) DeleteStandard(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID норматива")
+		return
+	}
+
+	if err := h.service.DeleteStandard(r.Context(), id); err != nil {
+		writeError(w, r, err, "Не удалось удалить норматив")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// --- ПРЕДЛОЖЕНИЯ О ПРИСВОЕНИИ ---
+
+// ListProposals обрабатывает GET /api/v1/rank-proposals?status=pending
+func (h *RankHandler) ListProposals(w http.ResponseWriter, r *http.Request) {
+	proposals, err := h.service.ListProposals(r.Context(), r.URL.Query().Get("status"))
+	if err != nil {
+		writeError(w, r, err, "Не удалось получить список предложений")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, proposals)
+}
+
+// ApproveProposal обрабатывает POST /api/v1/rank-proposals/{id}/approve
+func (h *RankHandler) ApproveProposal(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID предложения")
+		return
+	}
+
+	// Имя администратора берем из контекста, куда его положил AuthMiddleware
+	username, _ := r.Context().Value(auth.ContextKeyUsername).(string)
+
+	awarded, err := h.service.ApproveProposal(r.Context(), id, username)
+	if err != nil {
+		writeError(w, r, err, "Не удалось утвердить предложение")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, awarded)
+}
+
+// RejectProposal обрабатывает POST /api/v1/rank-proposals/{id}/reject
+func (h *RankHandler) RejectProposal(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID предложения")
+		return
+	}
+
+	username, _ := r.Context().Value(auth.ContextKeyUsername).(string)
+
+	if err := h.service.RejectProposal(r.Context(), id, username); err != nil {
+		writeError(w, r, err, "Не удалось отклонить предложение")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]string{"status": "success"})
+}
+
+// AthleteHistory обрабатывает GET /api/v1/athletes/{id}/ranks
+func (h *RankHandler) AthleteHistory(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID")
+		return
+	}
+
+	history, err := h.service.AthleteHistory(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err, "Не удалось получить историю разрядов")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, history)
+}
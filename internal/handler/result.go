@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"sport-manager/internal/repository"
+	"sport-manager/internal/service"
+
+	"github.com/gorilla/mux"
+)
+
+// ResultHandler обрабатывает запросы на фиксацию результатов участников
+type ResultHandler struct {
+	service *service.ResultService
+}
+
+// NewResultHandler создает новый экземпляр хендлера результатов
+func NewResultHandler(s *service.ResultService) *ResultHandler {
+	return &ResultHandler{service: s}
+}
+
+// SaveResult обрабатывает PUT /api/v1/participations/{id}/result
+// Сохраняет результат и, если выполнен разрядный норматив, возвращает предложение о присвоении.
+// Первый результат создается без If-Match, изменение существующего требует ETag его версии.
+func (h *ResultHandler) SaveResult(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID записи участия")
+		return
+	}
+
+	var result repository.Result
+	if err := json.NewDecoder(r.Body).Decode(&result); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный формат данных в запросе")
+		return
+	}
+	result.ParticipationID = id // Принудительно ставим ID из URL
+
+	version, _, err := parseIfMatch(r) // Без заголовка version == 0: ожидается создание
+	if err != nil {
+		writeError(w, r, err, "")
+		return
+	}
+
+	proposal, err := h.service.Save(r.Context(), &result, version)
+	if err != nil {
+		writeError(w, r, err, "Не удалось сохранить результат")
+		return
+	}
+
+	w.Header().Set("ETag", versionETag(result.Version))
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"result":        result,
+		"rank_proposal": proposal, // null, если норматив не выполнен
+	})
+}
+
+// PatchResult обрабатывает PATCH /api/v1/participations/{id}/result (JSON Merge Patch, RFC 7396).
+// Меняет только переданные поля существующего результата; требует If-Match.
+func (h *ResultHandler) PatchResult(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID записи участия")
+		return
+	}
+
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+	fields, ok := decodeMergePatch(w, r)
+	if !ok {
+		return
+	}
+
+	result, proposal, err := h.service.Patch(r.Context(), id, fields, version)
+	if err != nil {
+		writeError(w, r, err, "Не удалось сохранить результат")
+		return
+	}
+
+	w.Header().Set("ETag", versionETag(result.Version))
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"result":        result,
+		"rank_proposal": proposal,
+	})
+}
+
+// GetResult обрабатывает GET /api/v1/participations/{id}/result
+func (h *ResultHandler) GetResult(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID записи участия")
+		return
+	}
+
+	result, err := h.service.GetByParticipationID(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err, "Не удалось получить результат")
+		return
+	}
+
+	writeVersioned(w, r, result.Version, result)
+}
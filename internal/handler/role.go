@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"sport-manager/internal/repository"
+	"sport-manager/internal/service"
+
+	"github.com/gorilla/mux"
+)
+
+// RoleHandler обрабатывает запросы на назначение ролей пользователям
+type RoleHandler struct {
+	service *service.RoleService
+}
+
+// NewRoleHandler создает новый экземпляр хендлера ролей
+func NewRoleHandler(s *service.RoleService) *RoleHandler {
+	return &RoleHandler{service: s}
+}
+
+// ListRoles обрабатывает GET /api/v1/users/{id}/roles
+func (h *RoleHandler) ListRoles(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID пользователя")
+		return
+	}
+
+	roles, err := h.service.List(r.Context(), currentClaims(r), userID)
+	if err != nil {
+		writeError(w, r, err, "Не удалось получить роли пользователя")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, roles)
+}
+
+// AssignRole обрабатывает POST /api/v1/users/{id}/roles
+// Тело: {"role": "judge", "scope_type": "competition", "scope_id": 12}
+func (h *RoleHandler) AssignRole(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID пользователя")
+		return
+	}
+
+	var ra repository.RoleAssignment
+	if err := json.NewDecoder(r.Body).Decode(&ra); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Неверный формат данных")
+		return
+	}
+	ra.UserID = userID
+
+	if err := h.service.Assign(r.Context(), currentClaims(r), &ra); err != nil {
+		writeError(w, r, err, "Не удалось назначить роль")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusCreated, ra)
+}
+
+// RevokeRole обрабатывает DELETE /api/v1/users/{id}/roles/{roleId}
+func (h *RoleHandler) RevokeRole(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID пользователя")
+		return
+	}
+	id, err := strconv.Atoi(vars["roleId"])
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID назначения")
+		return
+	}
+
+	if err := h.service.Revoke(r.Context(), currentClaims(r), userID, id); err != nil {
+		writeError(w, r, err, "Не удалось отозвать роль")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]string{"status": "success"})
+}
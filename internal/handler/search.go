@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"sport-manager/internal/service"
+)
+
+// SearchHandler обрабатывает поиск по спортсменам, соревнованиям и клубам
+type SearchHandler struct {
+	service *service.SearchService
+}
+
+// NewSearchHandler создает новый экземпляр хендлера поиска
+func NewSearchHandler(s *service.SearchService) *SearchHandler {
+	return &SearchHandler{service: s}
+}
+
+// Search обрабатывает GET /api/v1/search?q=ivanov&type=athlete,club&limit=10
+// Возвращает найденные записи по убыванию релевантности; подходит для подсказок при вводе.
+func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	q := newQueryParams(r)
+	var types []string
+	if v := q.String("type"); v != "" {
+		types = strings.Split(v, ",")
+	}
+	limit := q.Int("limit")
+	if err := q.Err(); err != nil {
+		writeError(w, r, err, "")
+		return
+	}
+
+	hits, err := h.service.Search(r.Context(), q.String("q"), types, limit)
+	if err != nil {
+		writeError(w, r, err, "Не удалось выполнить поиск")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{"results": hits})
+}
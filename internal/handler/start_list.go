@@ -0,0 +1,206 @@
+package handler
+
+import (
+	"encoding/json"
+	"html/template"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"sport-manager/internal/i18n"
+	"sport-manager/internal/repository"
+	"sport-manager/internal/service"
+
+	"github.com/gorilla/mux"
+)
+
+// startListTemplate — версия стартового протокола для печати на языке пользователя ($.Lang).
+// Сохранение в PDF выполняется средствами браузера («Печать» → «Сохранить как PDF»).
+var startListTemplate = template.Must(template.New("start_list").Funcs(template.FuncMap{
+	"clock": func(e repository.StartListEntry) string {
+		if e.StartTime == nil {
+			return ""
+		}
+		return e.StartTime.Format("15:04:05")
+	},
+	"t": i18n.T,
+	"date": func(lang string, t time.Time) string {
+		if lang == i18n.LangEN {
+			return t.Format("2 January 2006")
+		}
+		return t.Format("02.01.2006")
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head>
+    <meta charset="UTF-8">
+    <title>{{t .Lang "Стартовый протокол"}} — {{.Competition.Name}}</title>
+    <style>
+        body { font-family: 'Segoe UI', Arial, sans-serif; margin: 20px; }
+        h1 { font-size: 20px; margin-bottom: 4px; }
+        .meta { color: #555; margin-bottom: 15px; }
+        table { width: 100%; border-collapse: collapse; }
+        th, td { border: 1px solid #999; padding: 6px 8px; text-align: left; font-size: 13px; }
+        th { background: #eee; }
+        @media print { body { margin: 0; } @page { size: A4; margin: 15mm; } }
+    </style>
+</head>
+<body>
+    <h1>{{t .Lang "Стартовый протокол"}}: {{.Competition.Name}}</h1>
+    <div class="meta">{{.Competition.Location}}, {{date .Lang .Competition.StartDate}}{{if .Event}} — {{.Event}}{{end}}</div>
+    <table>
+        <tr><th>{{t .Lang "№ п/п"}}</th><th>{{t .Lang "Номер"}}</th><th>{{t .Lang "Спортсмен"}}</th><th>{{t .Lang "Клуб"}}</th><th>{{t .Lang "Категория"}}</th><th>{{t .Lang "Дисциплина"}}</th><th>{{t .Lang "Время старта"}}</th></tr>
+        {{range .Entries}}
+        <tr>
+            <td>{{if .StartOrder}}{{.StartOrder}}{{end}}</td>
+            <td>{{if .Bib}}{{.Bib}}{{end}}</td>
+            <td>{{.AthleteName}}</td>
+            <td>{{.Club}}</td>
+            <td>{{.Category}}</td>
+            <td>{{.Event}}</td>
+            <td>{{clock .}}</td>
+        </tr>
+        {{end}}
+    </table>
+</body>
+</html>`))
+
+// StartListHandler обрабатывает запросы на выдачу номеров и формирование стартовых протоколов
+type StartListHandler struct {
+	service *service.StartListService
+}
+
+// NewStartListHandler создает новый экземпляр хендлера стартовых протоколов
+func NewStartListHandler(s *service.StartListService) *StartListHandler {
+	return &StartListHandler{service: s}
+}
+
+// GetBibSettings обрабатывает GET /api/v1/competitions/{id}/bib-settings
+func (h *StartListHandler) GetBibSettings(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID соревнования")
+		return
+	}
+
+	settings, err := h.service.GetBibSettings(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err, "Не удалось получить настройки номеров")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, settings)
+}
+
+// SaveBibSettings обрабатывает PUT /api/v1/competitions/{id}/bib-settings
+func (h *StartListHandler) SaveBibSettings(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID соревнования")
+		return
+	}
+
+	var settings repository.BibSettings
+	if err := json.NewDecoder(r.Body).Decode(&settings); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Неверный формат данных")
+		return
+	}
+	settings.CompetitionID = id // Принудительно ставим ID из URL
+
+	if err := h.service.SaveBibSettings(r.Context(), &settings); err != nil {
+		writeError(w, r, err, "Не удалось сохранить настройки номеров")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, settings)
+}
+
+// AssignBibs обрабатывает POST /api/v1/competitions/{id}/bibs?reassign=true&draw_id=5
+// Параметр draw_id обязателен для режима 'random'.
+func (h *StartListHandler) AssignBibs(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID соревнования")
+		return
+	}
+
+	reassign := r.URL.Query().Get("reassign") == "true"
+	drawID := 0
+	if v := r.URL.Query().Get("draw_id"); v != "" {
+		if drawID, err = strconv.Atoi(v); err != nil {
+			writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID жеребьевки")
+			return
+		}
+	}
+
+	entries, err := h.service.AssignBibs(r.Context(), id, reassign, drawID)
+	if err != nil {
+		writeError(w, r, err, "Не удалось присвоить стартовые номера")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, entries)
+}
+
+// GenerateStartList обрабатывает POST /api/v1/competitions/{id}/start-list
+func (h *StartListHandler) GenerateStartList(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID соревнования")
+		return
+	}
+
+	var params service.StartListParams
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Неверный формат данных. Время ожидается в RFC3339")
+		return
+	}
+
+	entries, err := h.service.GenerateStartList(r.Context(), id, params)
+	if err != nil {
+		writeError(w, r, err, "Не удалось сформировать стартовый протокол")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, entries)
+}
+
+// GetStartList обрабатывает GET /api/v1/competitions/{id}/start-list?event=&format=html&lang=en
+// По умолчанию возвращает JSON, с format=html — страницу для печати. Язык страницы — из lang,
+// иначе из профиля пользователя или Accept-Language: протокол можно распечатать для иностранных гостей.
+func (h *StartListHandler) GetStartList(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID соревнования")
+		return
+	}
+
+	event := r.URL.Query().Get("event")
+	competition, entries, err := h.service.GetStartList(r.Context(), id, event)
+	if err != nil {
+		writeError(w, r, err, "Не удалось получить стартовый протокол")
+		return
+	}
+
+	if r.URL.Query().Get("format") != "html" {
+		writeJSONResponse(w, http.StatusOK, entries)
+		return
+	}
+
+	lang := i18n.FromRequest(r)
+	if explicit := r.URL.Query().Get("lang"); i18n.Supported(explicit) {
+		lang = explicit
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Language", lang)
+	err = startListTemplate.Execute(w, map[string]interface{}{
+		"Lang":        lang,
+		"Competition": competition,
+		"Event":       event,
+		"Entries":     entries,
+	})
+	if err != nil {
+		log.Printf("CRITICAL: Failed to render start list: %v", err)
+	}
+}
@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"sport-manager/internal/service"
+
+	"github.com/gorilla/mux"
+)
+
+// UserHandler обрабатывает запросы управления учетными записями и личного профиля
+type UserHandler struct {
+	service *service.UserService
+}
+
+// NewUserHandler создает новый экземпляр хендлера пользователей
+func NewUserHandler(s *service.UserService) *UserHandler {
+	return &UserHandler{service: s}
+}
+
+// ListUsers обрабатывает GET /api/v1/users?search=ivan
+func (h *UserHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	users, err := h.service.List(r.Context(), r.URL.Query().Get("search"))
+	if err != nil {
+		writeError(w, r, err, "Не удалось получить список пользователей")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, users)
+}
+
+// GetUser обрабатывает GET /api/v1/users/{id}
+func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID пользователя")
+		return
+	}
+
+	user, err := h.service.Get(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err, "Не удалось получить пользователя")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, user)
+}
+
+// UpdateUser обрабатывает PUT /api/v1/users/{id}
+// Тело: {"email": "...", "role": "organiser", "is_active": true}
+func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID пользователя")
+		return
+	}
+
+	var input struct {
+		Email    string `json:"email"`
+		Role     string `json:"role"`
+		IsActive *bool  `json:"is_active"` // Не передан — активность не меняется
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Неверный формат данных")
+		return
+	}
+
+	if err := h.service.Update(r.Context(), currentClaims(r), id, input.Email, input.Role, input.IsActive); err != nil {
+		writeError(w, r, err, "Не удалось обновить пользователя")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]string{"status": "success"})
+}
+
+// ResetPassword обрабатывает POST /api/v1/users/{id}/password
+// Задает временный пароль; при следующем входе пользователь будет обязан его сменить.
+func (h *UserHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID пользователя")
+		return
+	}
+
+	var input struct {
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Неверный формат данных")
+		return
+	}
+
+	if err := h.service.ResetPassword(r.Context(), id, input.Password); err != nil {
+		writeError(w, r, err, "Не удалось сбросить пароль")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]string{"status": "success"})
+}
+
+// DeleteUser обрабатывает DELETE /api/v1/users/{id}
+func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID пользователя")
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), currentClaims(r), id); err != nil {
+		writeError(w, r, err, "Не удалось удалить пользователя")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]string{"status": "success"})
+}
+
+// UnlockUser обрабатывает POST /api/v1/users/{id}/unlock
+// Снимает временную блокировку входа после серии неудачных попыток.
+func (h *UserHandler) UnlockUser(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID пользователя")
+		return
+	}
+
+	if err := h.service.Unlock(r.Context(), currentClaims(r), clientIP(r), id); err != nil {
+		writeError(w, r, err, "Не удалось снять блокировку")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]string{"status": "success"})
+}
+
+// ListLockouts обрабатывает GET /api/v1/lockouts
+func (h *UserHandler) ListLockouts(w http.ResponseWriter, r *http.Request) {
+	locks, err := h.service.ListLockouts(r.Context())
+	if err != nil {
+		writeError(w, r, err, "Не удалось получить список блокировок")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, locks)
+}
+
+// ClearLockout обрабатывает DELETE /api/v1/lockouts?key=ip:203.0.113.7
+func (h *UserHandler) ClearLockout(w http.ResponseWriter, r *http.Request) {
+	key := r.URL.Query().Get("key")
+	if key == "" {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Необходимо передать key")
+		return
+	}
+
+	if err := h.service.ClearLockout(r.Context(), currentClaims(r), clientIP(r), key); err != nil {
+		writeError(w, r, err, "Не удалось снять блокировку")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]string{"status": "success"})
+}
+
+// GetMe обрабатывает GET /api/v1/me
+func (h *UserHandler) GetMe(w http.ResponseWriter, r *http.Request) {
+	profile, err := h.service.Me(r.Context(), currentClaims(r))
+	if err != nil {
+		writeError(w, r, err, "Не удалось получить профиль")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, profile)
+}
+
+// UpdateMe обрабатывает PUT /api/v1/me
+// Тело: {"email": "...", "language": "en"}
+func (h *UserHandler) UpdateMe(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Email    string `json:"email"`
+		Language string `json:"language"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Неверный формат данных")
+		return
+	}
+
+	profile, err := h.service.UpdateMe(r.Context(), currentClaims(r), input.Email, input.Language)
+	if err != nil {
+		writeError(w, r, err, "Не удалось обновить профиль")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, profile)
+}
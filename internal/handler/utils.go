@@ -3,7 +3,13 @@ package handler
 import (
 	"encoding/json"
 	"log"
+	"net"
 	"net/http"
+	"strings"
+	"time"
+
+	"sport-manager/internal/i18n"
+	"sport-manager/internal/problem"
 )
 
 // writeJSONResponse — универсальный вспомогательный метод для отправки JSON-ответов.
@@ -24,11 +30,45 @@ func writeJSONResponse(w http.ResponseWriter, statusCode int, data interface{})
 	}
 }
 
-// writeErrorResponse — обертка для стандартизации сообщений об ошибках.
-// Помогает фронтенду всегда ожидать объект вида {"error": "описание"}.
-func writeErrorResponse(w http.ResponseWriter, statusCode int, message string) {
-	// Мы инкапсулируем создание карты (map) здесь, чтобы не дублировать код в хендлерах
-	writeJSONResponse(w, statusCode, map[string]string{
-		"error": message,
-	})
+// writeErrorResponse отвечает об ошибке в формате RFC 7807 с кодом по умолчанию для статуса.
+// Ошибки сервисов отправляйте через writeError — он сам подберет статус и код.
+func writeErrorResponse(w http.ResponseWriter, r *http.Request, statusCode int, message string, args ...interface{}) {
+	problem.Write(w, r, statusCode, "", message, args...)
+}
+
+// requestLanguage определяет язык пользователя: явно переданный в запросе ('ru'/'en')
+// или выбранный по заголовку Accept-Language. По умолчанию — русский.
+func requestLanguage(r *http.Request, explicit string) string {
+	if lang := strings.ToLower(explicit); i18n.Supported(lang) {
+		return lang
+	}
+	return i18n.Negotiate(r.Header.Get("Accept-Language"))
+}
+
+// clientIP возвращает IP-адрес клиента из соединения.
+// Заголовку X-Forwarded-For не доверяем: его может подставить сам клиент.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// parseAsOf разбирает параметр ?as_of= — момент времени в RFC 3339 или дату ГГГГ-ММ-ДД.
+// Дата означает состояние на конец этого дня (UTC). ok == false — параметр не передан.
+func parseAsOf(r *http.Request) (at time.Time, ok bool, err error) {
+	v := r.URL.Query().Get("as_of")
+	if v == "" {
+		return time.Time{}, false, nil
+	}
+	if at, err = time.Parse(time.RFC3339, v); err == nil {
+		return at, true, nil
+	}
+	day, err := time.Parse("2006-01-02", v)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	// Точность времени в PostgreSQL — микросекунды
+	return day.AddDate(0, 0, 1).Add(-time.Microsecond), true, nil
 }
@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"sport-manager/internal/auth"
+	"sport-manager/internal/repository"
+	"sport-manager/internal/service"
+
+	"github.com/gorilla/mux"
+)
+
+// WeighInHandler обрабатывает запросы, связанные с весовыми категориями и взвешиванием
+type WeighInHandler struct {
+	service *service.WeighInService
+}
+
+// NewWeighInHandler создает новый экземпляр хендлера взвешивания
+func NewWeighInHandler(s *service.WeighInService) *WeighInHandler {
+	return &WeighInHandler{service: s}
+}
+
+// --- ВЕСОВЫЕ КАТЕГОРИИ ---
+
+// ListClasses обрабатывает GET /api/v1/competitions/{id}/weight-classes
+func (h *WeighInHandler) ListClasses(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID соревнования")
+		return
+	}
+
+	classes, err := h.service.ListClasses(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err, "Не удалось получить весовые категории")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, classes)
+}
+
+// CreateClass обрабатывает POST /api/v1/competitions/{id}/weight-classes
+func (h *WeighInHandler) CreateClass(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID соревнования")
+		return
+	}
+
+	var class repository.WeightClass
+	if err := json.NewDecoder(r.Body).Decode(&class); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Неверный формат данных")
+		return
+	}
+	class.CompetitionID = id // Принудительно ставим ID из URL
+
+	if err := h.service.CreateClass(r.Context(), &class); err != nil {
+		writeError(w, r, err, "Не удалось создать весовую категорию")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusCreated, class)
+}
+
+// DeleteClass обрабатывает DELETE /api/v1/competitions/{id}/weight-classes/{classId}
+func (h *WeighInHandler) DeleteClass(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID соревнования")
+		return
+	}
+	classID, err := strconv.Atoi(vars["classId"])
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID весовой категории")
+		return
+	}
+
+	if err := h.service.DeleteClass(r.Context(), id, classID); err != nil {
+		writeError(w, r, err, "Не удалось удалить весовую категорию")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// --- ПРАВИЛА ВЗВЕШИВАНИЯ ---
+
+// GetRules обрабатывает GET /api/v1/competitions/{id}/weigh-in-rules
+func (h *WeighInHandler) GetRules(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID соревнования")
+		return
+	}
+
+	rules, err := h.service.GetRules(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err, "Не удалось получить правила взвешивания")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, rules)
+}
+
+// SaveRules обрабатывает PUT /api/v1/competitions/{id}/weigh-in-rules
+func (h *WeighInHandler) SaveRules(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID соревнования")
+		return
+	}
+
+	var rules repository.WeighInRules
+	if err := json.NewDecoder(r.Body).Decode(&rules); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Неверный формат данных")
+		return
+	}
+	rules.CompetitionID = id
+
+	if err := h.service.SaveRules(r.Context(), &rules); err != nil {
+		writeError(w, r, err, "Не удалось сохранить правила взвешивания")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, rules)
+}
+
+// --- ВЗВЕШИВАНИЕ ---
+
+// RecordWeighIn обрабатывает POST /api/v1/participations/{id}/weigh-ins
+// Тело: {"weight": 60.4, "notes": "..."}; судья-взвешивающий берется из токена.
+func (h *WeighInHandler) RecordWeighIn(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID записи участия")
+		return
+	}
+
+	var input struct {
+		Weight float64 `json:"weight"`
+		Notes  string  `json:"notes"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Неверный формат данных")
+		return
+	}
+
+	official, _ := r.Context().Value(auth.ContextKeyUsername).(string)
+
+	weighIn, err := h.service.Record(r.Context(), id, input.Weight, official, input.Notes)
+	if err != nil {
+		writeError(w, r, err, "Не удалось записать взвешивание")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusCreated, weighIn)
+}
+
+// ListWeighIns обрабатывает GET /api/v1/participations/{id}/weigh-ins
+func (h *WeighInHandler) ListWeighIns(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "Некорректный ID записи участия")
+		return
+	}
+
+	history, err := h.service.History(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err, "Не удалось получить протокол взвешивания")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, history)
+}
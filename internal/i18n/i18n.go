@@ -0,0 +1,103 @@
+// Package i18n переводит сообщения API на язык пользователя.
+//
+// Исходный язык сообщений — русский: текст (или формат fmt) на русском служит ключом
+// каталога, как msgid в gettext. Поэтому в коде сообщения пишутся как раньше, а перевод
+// подставляется в одном месте — при формировании ответа. Сообщение без перевода
+// отдается на русском, так что пропуск в каталоге не ломает ответ.
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Поддерживаемые языки интерфейса API.
+const (
+	LangRU = "ru"
+	LangEN = "en"
+)
+
+// Default — язык, если клиент не выразил предпочтений.
+const Default = LangRU
+
+// catalogs — переводы с русского на остальные языки.
+var catalogs = map[string]map[string]string{
+	LangEN: messagesEN,
+}
+
+// Supported сообщает, поддерживается ли язык.
+func Supported(lang string) bool {
+	return lang == LangRU || catalogs[lang] != nil
+}
+
+// T переводит сообщение на язык lang; если перевода нет — возвращает исходный текст.
+func T(lang, msg string) string {
+	if translated, ok := catalogs[lang][msg]; ok {
+		return translated
+	}
+	return msg
+}
+
+// Tf переводит формат и подставляет в него аргументы. Без аргументов формат не разбирается,
+// поэтому обычный текст со знаком % передавать безопасно.
+func Tf(lang, format string, args ...interface{}) string {
+	if len(args) == 0 {
+		return T(lang, format)
+	}
+	return fmt.Sprintf(T(lang, format), args...)
+}
+
+// Negotiate выбирает язык по заголовку Accept-Language (RFC 9110, с весами q).
+// Учитывается основной подтег ("en-US" → "en"); неподдерживаемые языки пропускаются.
+func Negotiate(acceptLanguage string) string {
+	type option struct {
+		lang string
+		q    float64
+	}
+	var options []option
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		lang, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		if !Supported(lang) {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q > 0 {
+			options = append(options, option{lang, q})
+		}
+	}
+	if len(options) == 0 {
+		return Default
+	}
+	// Стабильная сортировка: при равных весах побеждает язык, указанный раньше
+	sort.SliceStable(options, func(i, j int) bool { return options[i].q > options[j].q })
+	return options[0].lang
+}
+
+type contextKey struct{}
+
+// WithLanguage запоминает выбранный язык в контексте запроса
+// (например, язык из профиля пользователя после аутентификации).
+func WithLanguage(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, contextKey{}, lang)
+}
+
+// FromRequest возвращает язык ответа: сохраненный в контексте (профиль пользователя),
+// иначе выбранный по Accept-Language.
+func FromRequest(r *http.Request) string {
+	if lang, ok := r.Context().Value(contextKey{}).(string); ok && Supported(lang) {
+		return lang
+	}
+	return Negotiate(r.Header.Get("Accept-Language"))
+}
@@ -0,0 +1,27 @@
+package i18n
+
+import "testing"
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", LangRU},
+		{"en", LangEN},
+		{"EN-us", LangEN},
+		{"en-US,en;q=0.9,ru;q=0.8", LangEN},
+		{"ru;q=0.5, en;q=0.8", LangEN},
+		{"ru, en", LangRU},                 // при равных весах побеждает указанный раньше
+		{"de, fr;q=0.9, en;q=0.1", LangEN}, // неподдерживаемые языки пропускаются
+		{"de", LangRU},
+		{"en;q=0", LangRU},
+		{"en;q=abc", LangRU},
+		{"*", LangRU},
+	}
+	for _, tt := range tests {
+		if got := Negotiate(tt.header); got != tt.want {
+			t.Errorf("Negotiate(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,386 @@
+package i18n
+
+// messagesEN — английские переводы сообщений API.
+// Ключ — русский текст или формат fmt в точности как в коде; порядок глаголов %s/%d сохраняется.
+var messagesEN = map[string]string{
+	// Общие ответы хендлеров
+	"Ошибка валидации данных":                           "Validation failed",
+	"Неверный формат JSON":                              "Malformed JSON",
+	"Неверный формат данных":                            "Malformed request data",
+	"Некорректный формат данных в запросе":              "Malformed request data",
+	"Некорректный формат запроса":                       "Malformed request",
+	"Ошибка парсинга данных":                            "Failed to parse request data",
+	"Неверный формат данных. Время ожидается в RFC3339": "Malformed request data. Time must be in RFC 3339 format",
+	"Неверный формат тела запроса или даты. Ожидается RFC3339 (например, 2025-12-14T00:00:00Z)": "Malformed request body or date. RFC 3339 expected (for example, 2025-12-14T00:00:00Z)",
+	"Ошибка формирования ответа":                                       "Failed to build the response",
+	"Ожидается тело типа %s":                                           "Request body of type %s expected",
+	"Патч должен быть JSON-объектом":                                   "Patch must be a JSON object",
+	"Удаленные записи доступны только администратору":                  "Deleted records are available to administrators only",
+	"Результат успешно обновлен":                                       "Result updated successfully",
+	"Некорректный as_of: ожидается дата ГГГГ-ММ-ДД или время RFC 3339": "Invalid as_of: a YYYY-MM-DD date or RFC 3339 time expected",
+	"Некорректный from: ожидается время в формате RFC 3339":            "Invalid from: RFC 3339 time expected",
+	"Некорректный to: ожидается время в формате RFC 3339":              "Invalid to: RFC 3339 time expected",
+	"Некорректный номер версии from":                                   "Invalid from version number",
+	"Некорректный номер версии to":                                     "Invalid to version number",
+	"Некорректный limit":                                               "Invalid limit",
+	"Некорректный entity_id":                                           "Invalid entity_id",
+	"Необходимо указать корректное число в поле 'place'":               "The 'place' field must be a valid number",
+	"Параметр 'lanes' должен быть числом":                              "The 'lanes' parameter must be a number",
+
+	// Некорректные идентификаторы в пути
+	"Некорректный ID":                         "Invalid ID",
+	"Некорректный ID весовой категории":       "Invalid weight class ID",
+	"Некорректный ID жеребьевки":              "Invalid draw ID",
+	"Некорректный ID записи":                  "Invalid record ID",
+	"Некорректный ID записи участия":          "Invalid participation ID",
+	"Некорректный ID ключа":                   "Invalid key ID",
+	"Некорректный ID назначения":              "Invalid assignment ID",
+	"Некорректный ID норматива":               "Invalid standard ID",
+	"Некорректный ID пользователя":            "Invalid user ID",
+	"Некорректный ID предложения":             "Invalid proposal ID",
+	"Некорректный ID разряда":                 "Invalid rank ID",
+	"Некорректный ID соревнования":            "Invalid competition ID",
+	"некорректный ID для удаления":            "invalid ID for deletion",
+	"некорректный ID жеребьевки":              "invalid draw ID",
+	"некорректный ID записи":                  "invalid record ID",
+	"некорректный ID записи об участии":       "invalid participation ID",
+	"некорректный ID предложения":             "invalid proposal ID",
+	"некорректный ID соревнования":            "invalid competition ID",
+	"некорректный ID спортсмена":              "invalid athlete ID",
+	"ID спортсмена обязателен для обновления": "Athlete ID is required for update",
+	"для обновления необходим ID записи":      "record ID is required for update",
+	"для обновления необходим ID разряда":     "rank ID is required for update",
+
+	// Сбои при обработке запроса
+	"Не удалось завершить сессию":                   "Failed to end the session",
+	"Не удалось записать взвешивание":               "Failed to record the weigh-in",
+	"Не удалось назначить роль":                     "Failed to assign the role",
+	"Не удалось обновить пользователя":              "Failed to update the user",
+	"Не удалось обновить профиль":                   "Failed to update the profile",
+	"Не удалось обновить разряд":                    "Failed to update the rank",
+	"Не удалось обновить результат":                 "Failed to update the result",
+	"Не удалось объявить жеребьевку":                "Failed to announce the draw",
+	"Не удалось отвязать учетную запись":            "Failed to unlink the account",
+	"Не удалось отклонить предложение":              "Failed to reject the proposal",
+	"Не удалось отозвать API-ключ":                  "Failed to revoke the API key",
+	"Не удалось отозвать роль":                      "Failed to revoke the role",
+	"Не удалось отправить письмо подтверждения":     "Failed to send the verification email",
+	"Не удалось подтвердить email":                  "Failed to verify the email",
+	"Не удалось получить весовые категории":         "Failed to load weight classes",
+	"Не удалось получить внешние учетные записи":    "Failed to load linked accounts",
+	"Не удалось получить жеребьевку":                "Failed to load the draw",
+	"Не удалось получить журнал аудита":             "Failed to load the audit log",
+	"Не удалось получить историю изменений":         "Failed to load the change history",
+	"Не удалось получить историю разрядов":          "Failed to load the rank history",
+	"Не удалось получить настройки безопасности":    "Failed to load security settings",
+	"Не удалось получить настройки номеров":         "Failed to load bib settings",
+	"Не удалось получить отборочные нормативы":      "Failed to load entry standards",
+	"Не удалось получить пользователя":              "Failed to load the user",
+	"Не удалось получить правила взвешивания":       "Failed to load weigh-in rules",
+	"Не удалось получить протокол взвешивания":      "Failed to load the weigh-in report",
+	"Не удалось получить профиль":                   "Failed to load the profile",
+	"Не удалось получить результат":                 "Failed to load the result",
+	"Не удалось получить роли пользователя":         "Failed to load user roles",
+	"Не удалось получить список API-ключей":         "Failed to list API keys",
+	"Не удалось получить список блокировок":         "Failed to list lockouts",
+	"Не удалось получить список жеребьевок":         "Failed to list draws",
+	"Не удалось получить список клубов":             "Failed to list clubs",
+	"Не удалось получить список нормативов":         "Failed to list standards",
+	"Не удалось получить список пользователей":      "Failed to list users",
+	"Не удалось получить список предложений":        "Failed to list proposals",
+	"Не удалось получить список разрядов":           "Failed to list ranks",
+	"Не удалось получить список регистраций":        "Failed to list registrations",
+	"Не удалось получить список соревнований":       "Failed to list competitions",
+	"Не удалось получить стартовый протокол":        "Failed to load the start list",
+	"Не удалось присвоить стартовые номера":         "Failed to assign bib numbers",
+	"Не удалось проверить жеребьевку":               "Failed to verify the draw",
+	"Не удалось проверить журнал аудита":            "Failed to verify the audit log",
+	"Не удалось провести жеребьевку":                "Failed to conduct the draw",
+	"Не удалось распределить участников по забегам": "Failed to seed heats",
+	"Не удалось сбросить пароль":                    "Failed to reset the password",
+	"Не удалось сменить пароль":                     "Failed to change the password",
+	"Не удалось снять блокировку":                   "Failed to clear the lockout",
+	"Не удалось создать API-ключ":                   "Failed to create the API key",
+	"Не удалось создать весовую категорию":          "Failed to create the weight class",
+	"Не удалось создать клуб":                       "Failed to create the club",
+	"Не удалось создать норматив":                   "Failed to create the standard",
+	"Не удалось создать отборочный норматив":        "Failed to create the entry standard",
+	"Не удалось создать разряд":                     "Failed to create the rank",
+	"Не удалось сохранить настройки безопасности":   "Failed to save security settings",
+	"Не удалось сохранить настройки номеров":        "Failed to save bib settings",
+	"Не удалось сохранить правила взвешивания":      "Failed to save weigh-in rules",
+	"Не удалось сохранить результат":                "Failed to save the result",
+	"Не удалось сравнить версии":                    "Failed to compare versions",
+	"Не удалось сформировать стартовый протокол":    "Failed to generate the start list",
+	"Не удалось удалить весовую категорию":          "Failed to delete the weight class",
+	"Не удалось удалить норматив":                   "Failed to delete the standard",
+	"Не удалось удалить пользователя":               "Failed to delete the user",
+	"Не удалось удалить соревнование":               "Failed to delete the competition",
+	"Не удалось удалить спортсмена":                 "Failed to delete the athlete",
+	"Не удалось утвердить предложение":              "Failed to approve the proposal",
+	"Ошибка получения списка":                       "Failed to load the list",
+	"Ошибка получения спортсмена":                   "Failed to load the athlete",
+	"Ошибка при восстановлении записи":              "Failed to restore the record",
+	"Ошибка при восстановлении соревнования":        "Failed to restore the competition",
+	"Ошибка при восстановлении спортсмена":          "Failed to restore the athlete",
+	"Ошибка при обновлении":                         "Update failed",
+	"Ошибка при обновлении данных":                  "Failed to update data",
+	"Ошибка при поиске соревнования":                "Failed to look up the competition",
+	"Ошибка при создании пользователя":              "Failed to create the user",
+	"Ошибка при создании регистрации":               "Failed to create the registration",
+	"Ошибка при создании соревнования на сервере":   "Server failed to create the competition",
+	"Ошибка при сохранении спортсмена":              "Failed to save the athlete",
+	"Ошибка при удалении записи":                    "Failed to delete the record",
+	"Ошибка при удалении записи из системы":         "Failed to delete the record from the system",
+	"Ошибка при удалении соревнования":              "Failed to delete the competition",
+	"Ошибка при удалении спортсмена":                "Failed to delete the athlete",
+	"не удалось назначить роль":                     "failed to assign the role",
+	"не удалось обновить данные":                    "failed to update data",
+	"не удалось обновить пользователя":              "failed to update the user",
+	"не удалось обновить разряд":                    "failed to update the rank",
+	"не удалось обновить результат":                 "failed to update the result",
+	"не удалось окончательно удалить запись":        "failed to permanently delete the record",
+	"не удалось привязать внешнюю учетную запись":   "failed to link the external account",
+	"не удалось создать атлета":                     "failed to create the athlete",
+	"не удалось создать весовую категорию":          "failed to create the weight class",
+	"не удалось создать жеребьевку":                 "failed to create the draw",
+	"не удалось создать запись об участии":          "failed to create the participation",
+	"не удалось создать клуб":                       "failed to create the club",
+	"не удалось создать норматив":                   "failed to create the standard",
+	"не удалось создать отборочный норматив":        "failed to create the entry standard",
+	"не удалось создать пользователя":               "failed to create the user",
+	"не удалось создать разряд":                     "failed to create the rank",
+	"не удалось сохранить взвешивание":              "failed to save the weigh-in",
+	"не удалось сохранить результат":                "failed to save the result",
+	"ошибка обновления данных":                      "failed to update data",
+	"ошибка при создании соревнования":              "failed to create the competition",
+	"ошибка при удалении весовой категории":         "failed to delete the weight class",
+	"ошибка при удалении норматива":                 "failed to delete the standard",
+	"ошибка при удалении пользователя":              "failed to delete the user",
+
+	// Аутентификация и сессии
+	"Требуется авторизация":                                                           "Authentication required",
+	"Неверный формат токена":                                                          "Malformed token",
+	"Токен недействителен или просрочен":                                              "Token is invalid or expired",
+	"Токен отозван":                                                                   "Token has been revoked",
+	"Ошибка проверки токена":                                                          "Failed to verify the token",
+	"Ошибка сервера при авторизации":                                                  "Server error during authorization",
+	"ошибка сервера при авторизации":                                                  "server error during authorization",
+	"ошибка сервера при продлении сессии":                                             "server error while refreshing the session",
+	"Сессия недействительна, выполните вход заново":                                   "Session is invalid, please sign in again",
+	"CSRF-токен отсутствует или неверен":                                              "CSRF token is missing or invalid",
+	"Доступ запрещен: недостаточно прав (%s)":                                         "Access denied: insufficient permissions (%s)",
+	"Необходимо сменить пароль (POST /api/v1/me/password)":                            "Password change required (POST /api/v1/me/password)",
+	"Необходимо подключить двухфакторную аутентификацию (POST /api/v1/me/2fa/enroll)": "Two-factor authentication enrollment required (POST /api/v1/me/2fa/enroll)",
+	"Неверный логин или пароль":                                                       "Invalid username or password",
+	"неверные учетные данные":                                                         "invalid credentials",
+	"учетная запись заблокирована":                                                    "account is blocked",
+	"Слишком много неудачных попыток входа, повторите позже":                          "Too many failed sign-in attempts, try again later",
+	"слишком много неудачных попыток входа, повторите позже":                          "too many failed sign-in attempts, try again later",
+	"Необходимо передать challenge и code":                                            "challenge and code are required",
+	"Необходимо передать code":                                                        "code is required",
+	"Необходимо передать email":                                                       "email is required",
+	"Необходимо передать key":                                                         "key is required",
+	"Необходимо передать password и code":                                             "password and code are required",
+	"Необходимо передать refresh_token":                                               "refresh_token is required",
+	"Необходимо передать token":                                                       "token is required",
+	"Необходимо передать token и new_password":                                        "token and new_password are required",
+	"недействительный refresh-токен":                                                  "invalid refresh token",
+	"refresh-токен не найден":                                                         "refresh token not found",
+	"refresh-токен отозван":                                                           "refresh token has been revoked",
+	"refresh-токен принадлежит другому пользователю":                                  "refresh token belongs to another user",
+	"refresh-токен просрочен":                                                         "refresh token has expired",
+	"refresh-токен уже использован":                                                   "refresh token has already been used",
+	"токен просрочен или недействителен":                                              "token is expired or invalid",
+	"ссылка недействительна или устарела":                                             "link is invalid or expired",
+	"адрес электронной почты уже подтвержден":                                         "email address is already verified",
+	"Если адрес зарегистрирован, на него отправлено письмо со ссылкой для сброса пароля": "If the address is registered, a password reset link has been sent to it",
+	"пароль указан неверно":                         "incorrect password",
+	"текущий пароль указан неверно":                 "current password is incorrect",
+	"новый пароль должен отличаться от текущего":    "new password must differ from the current one",
+	"пароль слишком короткий (минимум %d символов)": "password is too short (at least %d characters)",
+	"некорректный email":                            "invalid email",
+	"ключ должен начинаться с 'user:' или 'ip:'":    "key must start with 'user:' or 'ip:'",
+	"для '%s' нет неудачных попыток входа":          "no failed sign-in attempts for '%s'",
+
+	// Двухфакторная аутентификация
+	"неверный код подтверждения":                                     "invalid verification code",
+	"двухфакторная аутентификация не подключена":                     "two-factor authentication is not enabled",
+	"двухфакторная аутентификация уже подключена":                    "two-factor authentication is already enabled",
+	"у пользователя '%s' двухфакторная аутентификация не подключена": "user '%s' does not have two-factor authentication enabled",
+	"сначала начните подключение (POST /me/2fa/enroll)":              "start enrollment first (POST /me/2fa/enroll)",
+	"политика безопасности требует 2FA для вашей роли":               "security policy requires 2FA for your role",
+	"время на ввод кода истекло, начните вход заново":                "code entry timed out, please sign in again",
+	"вход не найден, начните заново":                                 "sign-in not found, please start over",
+	"вход с 2FA не найден":                                           "2FA sign-in not found",
+	"вход уже завершен, начните заново":                              "sign-in already completed, please start over",
+	"требуется аутентификация":                                       "authentication required",
+	"Не удалось начать подключение 2FA":                              "Failed to start 2FA enrollment",
+	"Не удалось подключить 2FA":                                      "Failed to enable 2FA",
+	"Не удалось отключить 2FA":                                       "Failed to disable 2FA",
+	"Не удалось обновить резервные коды":                             "Failed to regenerate recovery codes",
+	"Не удалось сбросить 2FA":                                        "Failed to reset 2FA",
+
+	// Вход через внешних провайдеров (OIDC)
+	"Провайдер отклонил вход: %s": "The provider rejected the sign-in: %s",
+	"Вход через провайдера начат в другом браузере или устарел, начните заново":                         "The provider sign-in was started in another browser or has expired, please start over",
+	"Не удалось войти через провайдера":                                                                 "Failed to sign in with the provider",
+	"код входа через OIDC не найден или истек, начните заново":                                          "OIDC sign-in code not found or expired, please start over",
+	"вход через OIDC не найден или истек, начните заново":                                               "OIDC sign-in not found or expired, please start over",
+	"вход через OIDC не настроен":                                                                       "OIDC sign-in is not configured",
+	"провайдер OIDC не вернул id_token":                                                                 "OIDC provider did not return an id_token",
+	"провайдер не передал email пользователя":                                                           "the provider did not supply the user's email",
+	"провайдер не подтвердил вход":                                                                      "the provider did not confirm the sign-in",
+	"ID-токен OIDC не содержит sub":                                                                     "OIDC ID token has no sub",
+	"ID-токен OIDC недействителен: nonce не совпадает":                                                  "OIDC ID token is invalid: nonce mismatch",
+	"учетная запись не найдена: войдите по паролю и привяжите провайдера в профиле":                     "account not found: sign in with a password and link the provider in your profile",
+	"пользователь с email '%s' уже зарегистрирован: войдите по паролю и привяжите провайдера в профиле": "a user with email '%s' is already registered: sign in with a password and link the provider in your profile",
+	"эта учетная запись провайдера уже привязана к другому пользователю":                                "this provider account is already linked to another user",
+	"учетная запись провайдера уже привязана":                                                           "provider account is already linked",
+	"внешняя учетная запись с ID %d не найдена":                                                         "external account with ID %d not found",
+
+	// Роли, права и API-ключи
+	"недостаточно прав": "insufficient permissions",
+	"недостаточно прав для просмотра ролей пользователя": "insufficient permissions to view user roles",
+	"недостаточно прав для управления этой ролью":        "insufficient permissions to manage this role",
+	"неизвестная роль '%s'":                              "unknown role '%s'",
+	"неизвестная область '%s'":                           "unknown scope '%s'",
+	"для области '%s' обязателен scope_id":               "scope_id is required for scope '%s'",
+	"такая роль уже назначена пользователю":              "this role is already assigned to the user",
+	"назначение роли с ID %d не найдено":                 "role assignment with ID %d not found",
+	"неизвестное право '%s'":                             "unknown permission '%s'",
+	"право '%s' нельзя выдать API-ключу":                 "permission '%s' cannot be granted to an API key",
+	"нельзя выдать ключу право %s, которого нет у вас":   "cannot grant the key permission %s that you do not have",
+	"укажите хотя бы одно право":                         "specify at least one permission",
+	"срок действия ключа должен быть в будущем":          "key expiry must be in the future",
+	"API-ключ не найден":                                 "API key not found",
+	"API-ключ недействителен":                            "API key is invalid",
+	"API-ключ отозван":                                   "API key has been revoked",
+	"срок действия API-ключа истек":                      "API key has expired",
+	"действующий API-ключ с ID %d не найден":             "active API key with ID %d not found",
+
+	// Пользователи
+	"пользователь '%s' не найден":                                     "user '%s' not found",
+	"пользователь с ID %d не найден":                                  "user with ID %d not found",
+	"пользователь с email '%s' не найден":                             "user with email '%s' not found",
+	"пользователь с таким email уже существует":                       "a user with this email already exists",
+	"пользователь с таким логином уже существует":                     "a user with this username already exists",
+	"нельзя заблокировать себя или снять с себя права администратора": "you cannot block yourself or revoke your own administrator rights",
+	"нельзя удалить собственную учетную запись":                       "you cannot delete your own account",
+
+	// Общие ошибки данных
+	"запись не найдена":                                                             "record not found",
+	"конфликт с текущим состоянием данных":                                          "conflict with the current state of the data",
+	"запись с такими данными уже существует":                                        "a record with the same data already exists",
+	"связанная запись не найдена или на запись есть ссылки":                         "related record not found or the record is still referenced",
+	"запись была изменена другим пользователем, обновите данные и повторите":        "the record was modified by another user, reload and try again",
+	"для изменения записи требуется ее текущая версия (If-Match)":                   "changing the record requires its current version (If-Match)",
+	"запись с ID %d не удалена, восстанавливать нечего":                             "record with ID %d is not deleted, nothing to restore",
+	"запись с ID %d не удалена: окончательно удалить можно только удаленную запись": "record with ID %d is not deleted: only deleted records can be purged",
+	"история записи с ID %d не найдена":                                             "history of record with ID %d not found",
+	"версия %d записи с ID %d не найдена":                                           "version %d of record with ID %d not found",
+	"укажите две разные версии (from и to)":                                         "specify two different versions (from and to)",
+	"начало периода должно быть раньше конца":                                       "period start must be before its end",
+
+	// Спортсмены, соревнования и участие
+	"спортсмен не найден":                                          "athlete not found",
+	"атлет с ID %d не найден":                                      "athlete with ID %d not found",
+	"атлет с ID %d не найден на %s":                                "athlete with ID %d not found as of %s",
+	"указанный спортсмен не найден":                                "the specified athlete was not found",
+	"спортсмен с таким ФИО и датой рождения уже существует":        "an athlete with this full name and date of birth already exists",
+	"неверный формат даты рождения":                                "invalid date of birth format",
+	"соревнование не найдено":                                      "competition not found",
+	"соревнование с ID %d не найдено":                              "competition with ID %d not found",
+	"соревнование с ID %d не найдено на %s":                        "competition with ID %d not found as of %s",
+	"указанное соревнование не найдено":                            "the specified competition was not found",
+	"соревнование с таким названием и датой начала уже существует": "a competition with this title and start date already exists",
+	"клуб с таким названием уже существует":                        "a club with this name already exists",
+	"вид спорта с таким названием уже существует":                  "a sport with this name already exists",
+	"запись об участии с ID %d не найдена":                         "participation with ID %d not found",
+	"спортсмен уже заявлен на это соревнование":                    "the athlete is already entered in this competition",
+	"результат для записи об участии %d не найден":                 "result for participation %d not found",
+	"занятое место должно быть положительным числом":               "place must be a positive number",
+	"для дисциплины '%s' требуется заявочный результат":            "an entry mark is required for event '%s'",
+	"для заявочного результата необходимо указать дисциплину":      "an event is required for the entry mark",
+	"заявочный результат %.2f не выполняет норматив %.2f":          "entry mark %.2f does not meet the standard %.2f",
+
+	// Разряды и нормативы
+	"разряд с ID %d не найден":                                      "rank with ID %d not found",
+	"норматив с ID %d не найден":                                    "standard with ID %d not found",
+	"отборочный норматив с ID %d не найден":                         "entry standard with ID %d not found",
+	"отборочный норматив для этой дисциплины и категории уже задан": "an entry standard for this event and category already exists",
+	"укажите порог результата или максимальное место":               "specify a result threshold or a maximum place",
+	"предложение с ID %d не найдено или уже рассмотрено":            "proposal with ID %d not found or already reviewed",
+	"допустимые значения: pending, approved, rejected":              "allowed values: pending, approved, rejected",
+
+	// Взвешивание
+	"весовая категория не найдена":                                "weight class not found",
+	"весовая категория с ID %d не найдена":                        "weight class with ID %d not found",
+	"весовая категория с таким названием уже есть в соревновании": "a weight class with this name already exists in the competition",
+	"весовая категория '%s' не подходит для участника":            "weight class '%s' does not fit the participant",
+	"верхняя граница веса должна быть больше нижней":              "the upper weight limit must be greater than the lower one",
+	"вес должен быть положительным":                               "weight must be positive",
+	"взвешивание участника уже завершено (%s)":                    "the participant's weigh-in is already complete (%s)",
+	"данные взвешивания участника изменились, повторите попытку":  "the participant's weigh-in data has changed, try again",
+	"участник не заявлен в весовую категорию":                     "the participant is not entered in a weight class",
+
+	// Стартовые номера и протоколы
+	"стартовый номер уже занят в этом соревновании":                  "bib number is already taken in this competition",
+	"диапазон номеров категории '%s' исчерпан":                       "bib range for category '%s' is exhausted",
+	"диапазоны '%s' и '%s' пересекаются":                             "ranges '%s' and '%s' overlap",
+	"для категории '%s' не задан диапазон номеров":                   "no bib range is set for category '%s'",
+	"для режима 'ranges' необходимо задать диапазоны":                "ranges are required for 'ranges' mode",
+	"некорректный диапазон номеров для категории '%s'":               "invalid bib range for category '%s'",
+	"не удалось присвоить номер %d":                                  "failed to assign bib number %d",
+	"неизвестный способ формирования порядка '%s'":                   "unknown ordering method '%s'",
+	"для раздельного старта необходимо указать время первого старта": "interval start requires the first start time",
+
+	// Жеребьевка
+	"для случайной операции требуется заранее объявленная жеребьевка": "a random operation requires a pre-announced draw",
+	"жеребьевка %d еще не проведена":                                  "draw %d has not been conducted yet",
+	"жеребьевка %d предназначена для другой операции":                 "draw %d is intended for another operation",
+	"жеребьевка %d уже проведена":                                     "draw %d has already been conducted",
+	"жеребьевка с ID %d не найдена":                                   "draw with ID %d not found",
+	"жеребьевка с ID %d уже проведена":                                "draw with ID %d has already been conducted",
+	"зерно должно быть hex-строкой длиной %d байт":                    "seed must be a hex string of %d bytes",
+
+	// Проверки полей
+	"обязательное поле":                                   "required field",
+	"обязательное поле, null недопустим":                  "required field, null is not allowed",
+	"не длиннее %d символов":                              "at most %d characters",
+	"не больше %d дорожек":                                "at most %d lanes",
+	"допустимые значения: %s":                             "allowed values: %s",
+	"дата должна быть в диапазоне с %s по %s":             "date must be between %s and %s",
+	"не может быть отрицательным":                         "must not be negative",
+	"должно быть положительным":                           "must be positive",
+	"должно быть строкой":                                 "must be a string",
+	"должно быть целым числом":                            "must be an integer",
+	"должно быть числом":                                  "must be a number",
+	"должно быть true или false":                          "must be true or false",
+	"должно быть датой":                                   "must be a date",
+	"должно быть датой в формате ГГГГ-ММ-ДД или RFC 3339": "must be a date in YYYY-MM-DD or RFC 3339 format",
+	"должно быть датой в формате ГГГГ-ММ-ДД":              "must be a date in YYYY-MM-DD format",
+	"нижняя граница не может быть больше верхней":         "the lower bound must not exceed the upper bound",
+	"неизвестное поле":                                    "unknown field",
+	"поле нельзя изменить":                                "field cannot be changed",
+
+	// Постраничные списки
+	"допустимые значения: %s (префикс '-' — по убыванию)": "allowed values: %s (prefix '-' for descending order)",
+	"курсор поврежден": "cursor is malformed",
+	"курсор выдан для другой сортировки, начните с первой страницы":                         "cursor was issued for a different sort order, start from the first page",
+	"Отбор и сортировка по дате рождения доступны только при праве просмотра личных данных": "Filtering and sorting by date of birth require permission to view personal data",
+
+	// Поиск
+	"Не удалось выполнить поиск": "Search failed",
+	"не короче %d символов":      "at least %d characters",
+
+	// Стартовый протокол (HTML)
+	"Стартовый протокол": "Start list",
+	"№ п/п":              "No.",
+	"Номер":              "Bib",
+	"Спортсмен":          "Athlete",
+	"Клуб":               "Club",
+	"Категория":          "Category",
+	"Дисциплина":         "Event",
+	"Время старта":       "Start time",
+}
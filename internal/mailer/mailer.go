@@ -0,0 +1,125 @@
+// Package mailer отправляет служебные письма (подтверждение email, сброс пароля).
+// Способ доставки выбирается конфигурацией: SMTP для боевой среды или запись в файлы
+// для разработки, чтобы письма можно было открыть без почтового сервера.
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"sport-manager/pkg/config"
+)
+
+// Message — одно письмо в текстовом формате.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer — интерфейс доставки писем.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New создает реализацию Mailer по настройке MAIL_DRIVER ('smtp' или 'log').
+func New(cfg *config.Config) (Mailer, error) {
+	switch cfg.MailDriver {
+	case "smtp":
+		if cfg.SMTPHost == "" {
+			return nil, fmt.Errorf("для MAIL_DRIVER=smtp необходимо задать SMTP_HOST")
+		}
+		return &SMTPMailer{
+			addr:     net.JoinHostPort(cfg.SMTPHost, strconv.Itoa(cfg.SMTPPort)),
+			host:     cfg.SMTPHost,
+			username: cfg.SMTPUsername,
+			password: cfg.SMTPPassword,
+			from:     cfg.MailFrom,
+		}, nil
+	case "", "log":
+		return &FileMailer{dir: cfg.MailDir, from: cfg.MailFrom}, nil
+	default:
+		return nil, fmt.Errorf("неизвестный MAIL_DRIVER '%s'", cfg.MailDriver)
+	}
+}
+
+// --- SMTP ---
+
+// SMTPMailer отправляет письма через SMTP-сервер (STARTTLS используется, если сервер его поддерживает).
+type SMTPMailer struct {
+	addr     string
+	host     string
+	username string
+	password string
+	from     string
+}
+
+// Send отправляет письмо через SMTP.
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	var a smtp.Auth
+	if m.username != "" {
+		a = smtp.PlainAuth("", m.username, m.password, m.host)
+	}
+	if err := smtp.SendMail(m.addr, a, m.from, []string{msg.To}, compose(m.from, msg)); err != nil {
+		return fmt.Errorf("mailer: не удалось отправить письмо на %s: %w", msg.To, err)
+	}
+	return nil
+}
+
+// --- РАЗРАБОТКА ---
+
+// FileMailer не отправляет письма, а сохраняет их в каталог (.eml) и пишет в лог.
+// Если каталог не задан, письмо только выводится в лог.
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+// Send сохраняет письмо в файл и выводит его в лог.
+func (m *FileMailer) Send(ctx context.Context, msg Message) error {
+	log.Printf("MAIL → %s: %s\n%s", msg.To, msg.Subject, msg.Body)
+	if m.dir == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return fmt.Errorf("mailer: не удалось создать каталог писем: %w", err)
+	}
+	name := fmt.Sprintf("%s_%s.eml", time.Now().Format("20060102-150405.000000"), sanitize(msg.To))
+	if err := os.WriteFile(filepath.Join(m.dir, name), compose(m.from, msg), 0o644); err != nil {
+		return fmt.Errorf("mailer: не удалось сохранить письмо: %w", err)
+	}
+	return nil
+}
+
+// compose собирает письмо в формате RFC 5322 (UTF-8, тема в кодировке RFC 2047).
+func compose(from string, msg Message) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + msg.To + "\r\n")
+	b.WriteString("Subject: " + mimeHeader(msg.Subject) + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+// sanitize оставляет в адресе только символы, безопасные для имени файла.
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '@' || r == '.' || r == '-' || r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, s)
+}
@@ -0,0 +1,51 @@
+package mailer
+
+import (
+	"embed"
+	"fmt"
+	"mime"
+	"strings"
+	"text/template"
+
+	"sport-manager/internal/i18n"
+)
+
+// Шаблоны писем лежат в templates/<name>.<lang>.tmpl.
+// Первая строка шаблона — тема письма, после пустой строки идет текст.
+//
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+var templates = template.Must(template.ParseFS(templateFS, "templates/*.tmpl"))
+
+// Поддерживаемые языки писем (те же, что у сообщений API); для остальных используется русский.
+const (
+	LangRU = i18n.LangRU
+	LangEN = i18n.LangEN
+)
+
+// Названия шаблонов.
+const (
+	TemplateVerifyEmail   = "verify_email"
+	TemplatePasswordReset = "password_reset"
+)
+
+// Render собирает письмо по шаблону на нужном языке.
+func Render(name, lang, to string, data interface{}) (Message, error) {
+	if lang != LangEN {
+		lang = LangRU
+	}
+
+	var b strings.Builder
+	if err := templates.ExecuteTemplate(&b, name+"."+lang+".tmpl", data); err != nil {
+		return Message{}, fmt.Errorf("mailer: ошибка шаблона %s: %w", name, err)
+	}
+
+	subject, body, _ := strings.Cut(b.String(), "\n\n")
+	return Message{To: to, Subject: strings.TrimSpace(subject), Body: body}, nil
+}
+
+// mimeHeader кодирует заголовок, если в нем есть не-ASCII символы.
+func mimeHeader(s string) string {
+	return mime.BEncoding.Encode("UTF-8", s)
+}
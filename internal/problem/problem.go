@@ -0,0 +1,113 @@
+// Package problem формирует ответы об ошибках в формате RFC 7807 (application/problem+json).
+// Все слои HTTP (хендлеры и middleware) отвечают об ошибках только через этот пакет,
+// поэтому клиент всегда получает одинаковую структуру, стабильный машиночитаемый код
+// и текст detail на своем языке (см. пакет i18n).
+package problem
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"sport-manager/internal/i18n"
+)
+
+// ContentType — тип тела ответа об ошибке.
+const ContentType = "application/problem+json"
+
+// Стабильные коды ошибок (поле code). Клиенты ветвятся по ним, а не по тексту detail.
+const (
+	CodeBadRequest           = "bad_request"
+	CodeUnauthorized         = "unauthorized"
+	CodeForbidden            = "forbidden"
+	CodeNotFound             = "not_found"
+	CodeConflict             = "conflict"
+	CodeValidation           = "validation_failed"
+	CodeVersionConflict      = "version_conflict"
+	CodePreconditionRequired = "precondition_required"
+	CodeUnsupportedMedia     = "unsupported_media_type"
+	CodeTooManyRequests      = "too_many_requests"
+	CodeInternal             = "internal_error"
+
+	CodeTokenInvalid           = "token_invalid"
+	CodeTokenRevoked           = "token_revoked"
+	CodeCSRFInvalid            = "csrf_invalid"
+	CodePasswordChangeRequired = "password_change_required"
+	CodeMFAEnrollmentRequired  = "mfa_enrollment_required"
+)
+
+// statusCodes — код по умолчанию для HTTP-статуса, если вызывающий не уточнил его.
+var statusCodes = map[int]string{
+	http.StatusBadRequest:            CodeBadRequest,
+	http.StatusUnauthorized:          CodeUnauthorized,
+	http.StatusForbidden:             CodeForbidden,
+	http.StatusNotFound:              CodeNotFound,
+	http.StatusConflict:              CodeConflict,
+	http.StatusUnprocessableEntity:   CodeValidation,
+	http.StatusPreconditionFailed:    CodeVersionConflict,
+	http.StatusPreconditionRequired:  CodePreconditionRequired,
+	http.StatusUnsupportedMediaType:  CodeUnsupportedMedia,
+	http.StatusTooManyRequests:       CodeTooManyRequests,
+	http.StatusInternalServerError:   CodeInternal,
+	http.StatusServiceUnavailable:    "service_unavailable",
+	http.StatusMethodNotAllowed:      "method_not_allowed",
+	http.StatusRequestEntityTooLarge: "payload_too_large",
+}
+
+// CodeForStatus возвращает код по умолчанию для HTTP-статуса.
+func CodeForStatus(status int) string {
+	if code, ok := statusCodes[status]; ok {
+		return code
+	}
+	if status >= 500 {
+		return CodeInternal
+	}
+	return CodeBadRequest
+}
+
+// Details — тело ответа об ошибке. Type всегда about:blank: смысл ошибки передает code,
+// отдельных страниц с описанием типов у API нет.
+type Details struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Code   string `json:"code"`
+	Detail string `json:"detail,omitempty"`
+	// Fields — нарушения по полям для validation_failed (расширение RFC 7807)
+	Fields interface{} `json:"fields,omitempty"`
+
+	lang string // Язык detail, уходит в заголовок Content-Language
+}
+
+// New готовит описание ошибки на языке запроса: detail — сообщение каталога i18n
+// (русский текст или формат fmt с аргументами args). Пустой code заменяется кодом по умолчанию для статуса.
+func New(r *http.Request, status int, code, detail string, args ...interface{}) *Details {
+	if code == "" {
+		code = CodeForStatus(status)
+	}
+	lang := i18n.FromRequest(r)
+	return &Details{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Code:   code,
+		Detail: i18n.Tf(lang, detail, args...),
+		lang:   lang,
+	}
+}
+
+// Write отправляет описание ошибки клиенту.
+func (d *Details) Write(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("Content-Language", d.lang)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(d.Status)
+	if err := json.NewEncoder(w).Encode(d); err != nil {
+		log.Printf("CRITICAL: Failed to encode problem response: %v", err)
+	}
+}
+
+// Write — сокращение для New(r, status, code, detail, args...).Write(w).
+func Write(w http.ResponseWriter, r *http.Request, status int, code, detail string, args ...interface{}) {
+	New(r, status, code, detail, args...).Write(w)
+}
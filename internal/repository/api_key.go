@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// APIKey — ключ доступа для машинного клиента (хронометраж, табло).
+// Права ключа ограничены списком Permissions в области ScopeType/ScopeID.
+type APIKey struct {
+	ID          int        `json:"id"`
+	Name        string     `json:"name"`
+	Prefix      string     `json:"prefix"`
+	KeyHash     string     `json:"-"`
+	Permissions []string   `json:"permissions"`
+	ScopeType   string     `json:"scope_type"`
+	ScopeID     int        `json:"scope_id"`
+	CreatedBy   string     `json:"created_by"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+}
+
+// apiKeyColumns — общий список столбцов для выборок ключей.
+const apiKeyColumns = `id, name, prefix, key_hash, permissions, scope_type, scope_id,
+	COALESCE(created_by, ''), created_at, expires_at, last_used_at, revoked_at`
+
+// apiKeyTouchInterval — время последнего использования обновляется не чаще раза в минуту,
+// чтобы частые запросы табло не превращались в поток записей.
+const apiKeyTouchInterval = time.Minute
+
+// APIKeyRepository управляет таблицей api_keys.
+type APIKeyRepository struct {
+	db *sql.DB
+}
+
+// NewAPIKeyRepository создает новый экземпляр репозитория API-ключей.
+func NewAPIKeyRepository(db *sql.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+// Create сохраняет новый ключ.
+func (r *APIKeyRepository) Create(ctx context.Context, k *APIKey) error {
+	query := `
+		INSERT INTO api_keys (name, prefix, key_hash, permissions, scope_type, scope_id, created_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), $8)
+		RETURNING id, created_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		k.Name, k.Prefix, k.KeyHash, pq.Array(k.Permissions), k.ScopeType, k.ScopeID, k.CreatedBy, k.ExpiresAt,
+	).Scan(&k.ID, &k.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("repo: не удалось создать API-ключ: %w", err)
+	}
+	return nil
+}
+
+// List возвращает все ключи, новые сверху.
+func (r *APIKeyRepository) List(ctx context.Context) ([]APIKey, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("repo: ошибка при получении списка API-ключей: %w", err)
+	}
+	defer rows.Close()
+
+	keys := make([]APIKey, 0)
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("repo: ошибка сканирования API-ключа: %w", err)
+		}
+		keys = append(keys, *k)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repo: ошибка итерации строк: %w", err)
+	}
+	return keys, nil
+}
+
+// GetByHash ищет ключ по хешу (проверка заголовка X-API-Key).
+func (r *APIKeyRepository) GetByHash(ctx context.Context, hash string) (*APIKey, error) {
+	k, err := scanAPIKey(r.db.QueryRowContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE key_hash = $1`, hash))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, NotFound("API-ключ не найден")
+		}
+		return nil, fmt.Errorf("repo: ошибка при поиске API-ключа: %w", err)
+	}
+	return k, nil
+}
+
+// Touch отмечает использование ключа.
+func (r *APIKeyRepository) Touch(ctx context.Context, id int) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE api_keys SET last_used_at = NOW()
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - $2 * INTERVAL '1 second')`,
+		id, int(apiKeyTouchInterval.Seconds()),
+	)
+	if err != nil {
+		return fmt.Errorf("repo: не удалось обновить время использования API-ключа: %w", err)
+	}
+	return nil
+}
+
+// Revoke отзывает ключ. Отозванный ключ остается в списке для истории.
+func (r *APIKeyRepository) Revoke(ctx context.Context, id int) error {
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE api_keys SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL", id,
+	)
+	if err != nil {
+		return fmt.Errorf("repo: не удалось отозвать API-ключ: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return NotFound("действующий API-ключ с ID %d не найден", id)
+	}
+	return nil
+}
+
+// scanAPIKey считывает строку выборки apiKeyColumns.
+func scanAPIKey(row rowScanner) (*APIKey, error) {
+	k := &APIKey{}
+	err := row.Scan(
+		&k.ID, &k.Name, &k.Prefix, &k.KeyHash, pq.Array(&k.Permissions), &k.ScopeType, &k.ScopeID,
+		&k.CreatedBy, &k.CreatedAt, &k.ExpiresAt, &k.LastUsedAt, &k.RevokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return k, nil
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"log"
 	"time"
 )
 
@@ -16,6 +15,11 @@ type Athlete struct {
 	Gender    string    `json:"gender"`
 	IsActive  bool      `json:"is_active"`
 	Address   string    `json:"address"`
+	ClubID    int       `json:"club_id"` // 0 означает, что спортсмен не состоит в клубе
+	// Version увеличивается при каждом изменении; служит ETag для оптимистичной блокировки
+	Version int `json:"version"`
+	// DeletedAt — время мягкого удаления; удаленные спортсмены скрыты из выборок по умолчанию
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
 
 // AthleteRepository предоставляет методы для взаимодействия с таблицей athletes
@@ -30,73 +34,130 @@ func NewAthleteRepository(db *sql.DB) *AthleteRepository {
 
 // --- МЕТОДЫ ДОСТУПА К ДАННЫМ ---
 
-// Create добавляет нового спортсмена и возвращает присвоенный ID
+// Create добавляет нового спортсмена и возвращает присвоенный ID.
+// Запись в журнал аудита делается в той же транзакции.
 func (r *AthleteRepository) Create(ctx context.Context, athlete *Athlete) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("repo: не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback()
+
 	query := `
-		INSERT INTO athletes (full_name, birth_date, gender, is_active, address)
-		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id`
+		INSERT INTO athletes (full_name, birth_date, gender, is_active, address, club_id)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, 0))
+		RETURNING id, version`
 
 	// Используем QueryRowContext для получения сгенерированного ID через RETURNING
-	err := r.db.QueryRowContext(ctx, query,
+	err = tx.QueryRowContext(ctx, query,
 		athlete.FullName,
 		athlete.BirthDate,
 		athlete.Gender,
 		athlete.IsActive,
 		athlete.Address,
-	).Scan(&athlete.ID)
+		athlete.ClubID,
+	).Scan(&athlete.ID, &athlete.Version)
 
 	if err != nil {
-		return fmt.Errorf("repo: не удалось создать атлета: %w", err)
+		return dbError("не удалось создать атлета", err)
+	}
+
+	if err := recordChange(ctx, tx, "athlete", "athletes", athlete.ID, "create", nil); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("repo: ошибка фиксации транзакции: %w", err)
 	}
 	return nil
 }
 
-// ListAll возвращает список всех спортсменов из базы данных
-func (r *AthleteRepository) ListAll(ctx context.Context) ([]Athlete, error) {
-	query := `
-		SELECT id, full_name, birth_date, gender, is_active, address
-		FROM athletes
-		ORDER BY id ASC`
+// athleteColumns — общий список столбцов для выборок спортсменов.
+const athleteColumns = `id, full_name, birth_date, gender, is_active, address, COALESCE(club_id, 0), version, deleted_at`
 
-	rows, err := r.db.QueryContext(ctx, query)
-	if err != nil {
-		return nil, fmt.Errorf("repo: ошибка при получении списка: %w", err)
+// AthleteFilter — условия выборки списка спортсменов. Пустые поля не ограничивают выборку.
+type AthleteFilter struct {
+	Name           string // Подстрока ФИО без учета регистра
+	Gender         string
+	Active         *bool
+	BirthYearFrom  int // Год рождения не раньше (включительно)
+	BirthYearTo    int // Год рождения не позже (включительно)
+	ClubID         int
+	IncludeDeleted bool // Показывать и мягко удаленных
+}
+
+// athleteList — постраничный список спортсменов и поля, по которым его можно сортировать.
+var athleteList = listSpec{
+	columns: athleteColumns,
+	from:    "athletes",
+	id:      "id",
+	sorts: map[string]sortField{
+		"id":         {expr: "id", cast: "INT"},
+		"full_name":  {expr: "full_name", cast: "TEXT"},
+		"birth_date": {expr: "COALESCE(birth_date, DATE '0001-01-01')", cast: "DATE"},
+	},
+}
+
+// SortFields возвращает поля, по которым можно сортировать список спортсменов.
+func (r *AthleteRepository) SortFields() []string {
+	return athleteList.sortNames()
+}
+
+// List возвращает страницу списка спортсменов по фильтру и общее число подходящих записей.
+func (r *AthleteRepository) List(ctx context.Context, f AthleteFilter, page Page) ([]Athlete, *PageInfo, error) {
+	q := &listQuery{}
+	if !f.IncludeDeleted {
+		q.where("deleted_at IS NULL")
+	}
+	if f.Name != "" {
+		q.where("full_name ILIKE %s", containsPattern(f.Name))
+	}
+	if f.Gender != "" {
+		q.where("gender = %s", f.Gender)
+	}
+	if f.Active != nil {
+		q.where("is_active = %s", *f.Active)
+	}
+	if f.BirthYearFrom > 0 {
+		q.where("birth_date >= make_date(%s, 1, 1)", f.BirthYearFrom)
+	}
+	if f.BirthYearTo > 0 {
+		q.where("birth_date < make_date(%s, 1, 1)", f.BirthYearTo+1)
+	}
+	if f.ClubID > 0 {
+		q.where("club_id = %s", f.ClubID)
 	}
-	defer rows.Close() // Обязательно освобождаем ресурсы
 
 	athletes := make([]Athlete, 0)
-	for rows.Next() {
-		var a Athlete
-		if err := rows.Scan(&a.ID, &a.FullName, &a.BirthDate, &a.Gender, &a.IsActive, &a.Address); err != nil {
-			return nil, fmt.Errorf("repo: ошибка сканирования строки: %w", err)
+	info, err := athleteList.fetch(ctx, r.db, q, page, func(row rowScanner) (int, error) {
+		a, err := scanAthlete(row)
+		if err != nil {
+			return 0, err
 		}
-		athletes = append(athletes, a)
-	}
-
-	// Проверяем, не возникло ли ошибок в процессе итерации
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("repo: ошибка итерации строк: %w", err)
+		athletes = append(athletes, *a)
+		return a.ID, nil
+	})
+	if err != nil {
+		return nil, nil, err
 	}
-
-	return athletes, nil
+	return athletes, info, nil
 }
 
-// GetByID находит одного спортсмена по его уникальному идентификатору
+// GetByID находит одного (не удаленного) спортсмена по его уникальному идентификатору
 func (r *AthleteRepository) GetByID(ctx context.Context, id int) (*Athlete, error) {
-	a := &Athlete{}
+	return r.Find(ctx, id, false)
+}
+
+// Find находит спортсмена по ID; при includeDeleted возвращает и мягко удаленного.
+func (r *AthleteRepository) Find(ctx context.Context, id int, includeDeleted bool) (*Athlete, error) {
 	query := `
-		SELECT id, full_name, birth_date, gender, is_active, address 
+		SELECT ` + athleteColumns + `
 		FROM athletes
-		WHERE id = $1`
-
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&a.ID, &a.FullName, &a.BirthDate, &a.Gender, &a.IsActive, &a.Address,
-	)
+		WHERE id = $1 AND ($2 OR deleted_at IS NULL)`
 
+	a, err := scanAthlete(r.db.QueryRowContext(ctx, query, id, includeDeleted))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("атлет с ID %d не найден", id)
+			return nil, NotFound("атлет с ID %d не найден", id)
 		}
 		return nil, fmt.Errorf("repo: ошибка при поиске по ID: %w", err)
 	}
@@ -104,38 +165,169 @@ func (r *AthleteRepository) GetByID(ctx context.Context, id int) (*Athlete, erro
 	return a, nil
 }
 
-// Update обновляет все поля существующего спортсмена по его ID
-func (r *AthleteRepository) Update(ctx context.Context, a *Athlete) error {
+// FindAsOf возвращает спортсмена в том виде, в каком он был на момент at (по истории версий).
+// Если на тот момент спортсмена не было или он был удален, возвращается ошибка «не найден».
+func (r *AthleteRepository) FindAsOf(ctx context.Context, id int, at time.Time) (*Athlete, error) {
+	query := `SELECT ` + athleteColumns + ` FROM ` + asOfSource("athletes") + ` WHERE deleted_at IS NULL`
+
+	a, err := scanAthlete(r.db.QueryRowContext(ctx, query, id, at))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, NotFound("атлет с ID %d не найден на %s", id, at.Format(time.RFC3339))
+		}
+		return nil, fmt.Errorf("repo: ошибка при поиске версии атлета: %w", err)
+	}
+	return a, nil
+}
+
+// scanAthlete читает одну строку спортсмена в порядке athleteColumns.
+func scanAthlete(row rowScanner) (*Athlete, error) {
+	a := &Athlete{}
+	var deletedAt sql.NullTime
+	if err := row.Scan(&a.ID, &a.FullName, &a.BirthDate, &a.Gender, &a.IsActive, &a.Address, &a.ClubID, &a.Version, &deletedAt); err != nil {
+		return nil, err
+	}
+	if deletedAt.Valid {
+		a.DeletedAt = &deletedAt.Time
+	}
+	return a, nil
+}
+
+// Update обновляет все поля существующего спортсмена по его ID.
+// expectedVersion — версия, которую видел клиент (AnyVersion — без проверки);
+// если запись с тех пор изменилась, возвращается ErrVersionConflict.
+func (r *AthleteRepository) Update(ctx context.Context, a *Athlete, expectedVersion int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("repo: не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Состояние до изменения нужно журналу аудита
+	before, err := snapshot(ctx, tx, "athletes", "id", a.ID)
+	if err != nil {
+		return err
+	}
+	if before == nil || isDeleted(before) {
+		return NotFound("атлет с ID %d не найден", a.ID)
+	}
+	// Строка заблокирована снимком до конца транзакции, поэтому проверка версии не гоняется с другими изменениями
+	if err := checkVersion(before, expectedVersion); err != nil {
+		return err
+	}
+
 	query := `
 		UPDATE athletes 
-		SET full_name = $2, birth_date = $3, gender = $4, is_active = $5, address = $6
-		WHERE id = $1`
+		SET full_name = $2, birth_date = $3, gender = $4, is_active = $5, address = $6, club_id = NULLIF($7, 0)
+		WHERE id = $1
+		RETURNING version`
 
-	_, err := r.db.ExecContext(ctx, query,
-		a.ID, a.FullName, a.BirthDate, a.Gender, a.IsActive, a.Address,
-	)
+	err = tx.QueryRowContext(ctx, query,
+		a.ID, a.FullName, a.BirthDate, a.Gender, a.IsActive, a.Address, a.ClubID,
+	).Scan(&a.Version)
 
 	if err != nil {
-		return fmt.Errorf("repo: не удалось обновить данные: %w", err)
+		return dbError("не удалось обновить данные", err)
+	}
+
+	if err := recordChange(ctx, tx, "athlete", "athletes", a.ID, "update", before); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("repo: ошибка фиксации транзакции: %w", err)
 	}
 	return nil
 }
 
-// Delete безвозвратно удаляет запись о спортсмене из базы данных
-func (r *AthleteRepository) Delete(ctx context.Context, id int) error {
-	log.Printf("Запрос на удаление атлета ID: %d", id)
+// Patch изменяет только переданные в p поля спортсмена и возвращает его новое состояние.
+// Столбцы, значение которых не меняется, в UPDATE не попадают; если не меняется ничего,
+// запись, ее версия и журнал аудита остаются прежними. expectedVersion проверяется так же, как в Update.
+func (r *AthleteRepository) Patch(ctx context.Context, id int, p AthletePatch, expectedVersion int) (*Athlete, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("repo: не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback()
 
-	result, err := r.db.ExecContext(ctx, "DELETE FROM athletes WHERE id = $1", id)
+	before, err := snapshot(ctx, tx, "athletes", "id", id)
 	if err != nil {
-		return fmt.Errorf("repo: критическая ошибка при удалении: %w", err)
+		return nil, err
+	}
+	if before == nil || isDeleted(before) {
+		return nil, NotFound("атлет с ID %d не найден", id)
+	}
+	if err := checkVersion(before, expectedVersion); err != nil {
+		return nil, err
 	}
 
-	// Проверяем, была ли удалена хоть одна строка
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		return fmt.Errorf("атлет с ID %d не найден для удаления", id)
+	current, err := scanAthlete(tx.QueryRowContext(ctx, `SELECT `+athleteColumns+` FROM athletes WHERE id = $1`, id))
+	if err != nil {
+		return nil, fmt.Errorf("repo: ошибка при чтении атлета: %w", err)
 	}
 
-	log.Printf("Успешно удалено строк: %d", rowsAffected)
-	return nil
+	var set columnSet
+	if p.FullName != nil && *p.FullName != current.FullName {
+		set.add("full_name", "%s", *p.FullName)
+	}
+	if p.BirthDate != nil && !sameDay(*p.BirthDate, current.BirthDate) {
+		set.add("birth_date", "%s", *p.BirthDate)
+	}
+	if p.Gender != nil && *p.Gender != current.Gender {
+		set.add("gender", "%s", *p.Gender)
+	}
+	if p.IsActive != nil && *p.IsActive != current.IsActive {
+		set.add("is_active", "%s", *p.IsActive)
+	}
+	if p.Address != nil && *p.Address != current.Address {
+		set.add("address", "%s", *p.Address)
+	}
+	if p.ClubID != nil && *p.ClubID != current.ClubID {
+		set.add("club_id", "NULLIF(%s, 0)", *p.ClubID)
+	}
+	if set.empty() {
+		return current, nil
+	}
+
+	query := `UPDATE athletes SET ` + set.sql() + ` WHERE id = $1 RETURNING ` + athleteColumns
+	updated, err := scanAthlete(tx.QueryRowContext(ctx, query, append([]interface{}{id}, set.args...)...))
+	if err != nil {
+		return nil, dbError("не удалось обновить данные", err)
+	}
+
+	if err := recordChange(ctx, tx, "athlete", "athletes", id, "update", before); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("repo: ошибка фиксации транзакции: %w", err)
+	}
+	return updated, nil
+}
+
+// Delete мягко удаляет спортсмена: запись и его участия сохраняются, но скрываются из выборок.
+// expectedVersion проверяется так же, как в Update.
+func (r *AthleteRepository) Delete(ctx context.Context, id, expectedVersion int) error {
+	return setDeleted(ctx, r.db, "athlete", "athletes", id, true, expectedVersion)
+}
+
+// Restore возвращает мягко удаленного спортсмена вместе с его участиями.
+func (r *AthleteRepository) Restore(ctx context.Context, id int) error {
+	return setDeleted(ctx, r.db, "athlete", "athletes", id, false, AnyVersion)
+}
+
+// Purge безвозвратно удаляет мягко удаленного спортсмена. Его участия и результаты
+// удаляются явно в той же транзакции: внешний ключ participations больше не каскадный.
+func (r *AthleteRepository) Purge(ctx context.Context, id int) error {
+	return purge(ctx, r.db, "athlete", "athletes", "athlete_id", id, time.Time{})
+}
+
+// PurgeUnreferenced безвозвратно удаляет мягко удаленного спортсмена, только если все его участия
+// удалены раньше cutoff (иначе — ErrConflict). Используется автоматической очисткой: удалять действующие
+// или недавно удаленные участия вместе с результатами можно только явным запросом администратора (Purge).
+func (r *AthleteRepository) PurgeUnreferenced(ctx context.Context, id int, cutoff time.Time) error {
+	return purge(ctx, r.db, "athlete", "athletes", "athlete_id", id, cutoff)
+}
+
+// ListDeletedBefore возвращает ID спортсменов, удаленных раньше cutoff (кандидаты на очистку).
+func (r *AthleteRepository) ListDeletedBefore(ctx context.Context, cutoff time.Time) ([]int, error) {
+	return listDeletedBefore(ctx, r.db, "athletes", cutoff)
 }
@@ -0,0 +1,241 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// AuditEntry — одна запись журнала аудита: кто, когда, откуда и что сделал с сущностью.
+type AuditEntry struct {
+	ID        int64           `json:"id"`
+	CreatedAt time.Time       `json:"created_at"`
+	Username  string          `json:"username"`
+	IP        string          `json:"ip"`
+	Entity    string          `json:"entity"`
+	EntityID  int             `json:"entity_id"`
+	Action    string          `json:"action"`
+	Before    json.RawMessage `json:"before,omitempty"`
+	After     json.RawMessage `json:"after,omitempty"`
+	PrevHash  string          `json:"prev_hash,omitempty"`
+	Hash      string          `json:"hash"`
+}
+
+// AuditFilter — условия выборки журнала аудита. Пустые поля не ограничивают выборку.
+type AuditFilter struct {
+	Entity   string
+	EntityID int
+	Username string
+	From     time.Time
+	To       time.Time
+	Limit    int
+}
+
+// AuditVerification — результат проверки цепочки хешей журнала.
+// BrokenAt — ID первой записи, хеш которой не сходится (0, если цепочка цела).
+type AuditVerification struct {
+	Valid    bool  `json:"valid"`
+	Checked  int   `json:"checked"`
+	BrokenAt int64 `json:"broken_at,omitempty"`
+}
+
+// auditActorKey — ключ контекста, в котором хранится автор изменений для журнала аудита.
+type auditActorKey struct{}
+
+type auditActor struct {
+	username string
+	ip       string
+}
+
+// WithAuditActor запоминает в контексте, кто и с какого адреса выполняет запрос.
+// Репозитории берут автора отсюда, когда пишут аудит в транзакции изменения.
+func WithAuditActor(ctx context.Context, username, ip string) context.Context {
+	return context.WithValue(ctx, auditActorKey{}, auditActor{username: username, ip: ip})
+}
+
+// auditActorFrom возвращает автора изменений из контекста (пустой — система или аноним).
+func auditActorFrom(ctx context.Context) auditActor {
+	actor, _ := ctx.Value(auditActorKey{}).(auditActor)
+	return actor
+}
+
+// queryRower — общее у *sql.DB и *sql.Tx: запись аудита вставляется и отдельно, и в транзакции изменения.
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// AuditRepository управляет журналом аудита. Записи только добавляются.
+type AuditRepository struct {
+	db *sql.DB
+}
+
+// NewAuditRepository создает новый экземпляр репозитория аудита.
+func NewAuditRepository(db *sql.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// Record добавляет запись в журнал аудита.
+func (r *AuditRepository) Record(ctx context.Context, e *AuditEntry) error {
+	return insertAudit(ctx, r.db, e)
+}
+
+// insertAudit вставляет запись журнала. ID, время и хеши проставляет триггер audit_log_chain.
+func insertAudit(ctx context.Context, q queryRower, e *AuditEntry) error {
+	query := `
+		INSERT INTO audit_log (username, ip, entity, entity_id, action, before_data, after_data)
+		VALUES (NULLIF($1, ''), NULLIF($2, ''), $3, NULLIF($4, 0), $5, $6, $7)
+		RETURNING id, created_at, COALESCE(prev_hash, ''), hash`
+
+	err := q.QueryRowContext(ctx, query,
+		e.Username, e.IP, e.Entity, e.EntityID, e.Action, nullJSON(e.Before), nullJSON(e.After),
+	).Scan(&e.ID, &e.CreatedAt, &e.PrevHash, &e.Hash)
+
+	if err != nil {
+		return fmt.Errorf("repo: не удалось записать событие аудита: %w", err)
+	}
+	return nil
+}
+
+// snapshot возвращает строку таблицы в виде JSON для журнала аудита и блокирует ее до конца транзакции.
+// Секреты (хеш пароля, ключ TOTP) в журнал не попадают. Возвращает nil, если строки нет.
+func snapshot(ctx context.Context, tx *sql.Tx, table, column string, value int) (json.RawMessage, error) {
+	query := `SELECT to_jsonb(t) - 'password_hash' - 'totp_secret' FROM ` + table + ` t WHERE ` + column + ` = $1 FOR UPDATE`
+
+	var data []byte
+	err := tx.QueryRowContext(ctx, query, value).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("repo: не удалось прочитать состояние записи для аудита: %w", err)
+	}
+	return data, nil
+}
+
+// recordChange пишет в журнал изменение строки table с указанным ID в той же транзакции, что и само изменение:
+// состояние после изменения читается из таблицы, автор — из контекста запроса (WithAuditActor).
+// Для таблиц из versionedTables заодно сохраняется новая версия записи.
+func recordChange(ctx context.Context, tx *sql.Tx, entity, table string, id int, action string, before json.RawMessage) error {
+	after, err := snapshot(ctx, tx, table, "id", id)
+	if err != nil {
+		return err
+	}
+
+	actor := auditActorFrom(ctx)
+	err = insertAudit(ctx, tx, &AuditEntry{
+		Username: actor.username,
+		IP:       actor.ip,
+		Entity:   entity,
+		EntityID: id,
+		Action:   action,
+		Before:   before,
+		After:    after,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Для спортсменов и соревнований дополнительно ведется история версий
+	if _, ok := versionedTables[table]; ok {
+		return saveVersion(ctx, tx, entity, id, after, actor.username)
+	}
+	return nil
+}
+
+// List возвращает записи журнала по фильтру, сначала новые.
+func (r *AuditRepository) List(ctx context.Context, f AuditFilter) ([]AuditEntry, error) {
+	query := `
+		SELECT id, created_at, COALESCE(username, ''), COALESCE(ip, ''), entity, COALESCE(entity_id, 0), action,
+			before_data, after_data, COALESCE(prev_hash, ''), hash
+		FROM audit_log
+		WHERE ($1 = '' OR entity = $1)
+		  AND ($2 = 0 OR entity_id = $2)
+		  AND ($3 = '' OR username = $3)
+		  AND ($4::TIMESTAMPTZ IS NULL OR created_at >= $4)
+		  AND ($5::TIMESTAMPTZ IS NULL OR created_at < $5)
+		ORDER BY id DESC
+		LIMIT $6`
+
+	rows, err := r.db.QueryContext(ctx, query,
+		f.Entity, f.EntityID, f.Username, nullTime(f.From), nullTime(f.To), f.Limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("repo: ошибка при чтении журнала аудита: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]AuditEntry, 0)
+	for rows.Next() {
+		var (
+			e             AuditEntry
+			before, after []byte
+		)
+		err := rows.Scan(&e.ID, &e.CreatedAt, &e.Username, &e.IP, &e.Entity, &e.EntityID, &e.Action,
+			&before, &after, &e.PrevHash, &e.Hash)
+		if err != nil {
+			return nil, fmt.Errorf("repo: ошибка сканирования записи аудита: %w", err)
+		}
+		e.Before, e.After = before, after
+		entries = append(entries, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repo: ошибка итерации журнала аудита: %w", err)
+	}
+	return entries, nil
+}
+
+// Verify пересчитывает хеши всех записей и проверяет, что каждая ссылается на предыдущую.
+func (r *AuditRepository) Verify(ctx context.Context) (*AuditVerification, error) {
+	query := `
+		SELECT id,
+			hash = audit_entry_hash(prev_hash, created_at, username, ip, entity, entity_id, action, before_data, after_data)
+			AND prev_hash IS NOT DISTINCT FROM LAG(hash) OVER (ORDER BY id)
+		FROM audit_log
+		ORDER BY id`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("repo: ошибка при проверке журнала аудита: %w", err)
+	}
+	defer rows.Close()
+
+	result := &AuditVerification{Valid: true}
+	for rows.Next() {
+		var (
+			id int64
+			ok bool
+		)
+		if err := rows.Scan(&id, &ok); err != nil {
+			return nil, fmt.Errorf("repo: ошибка сканирования при проверке журнала: %w", err)
+		}
+		result.Checked++
+		if !ok {
+			result.Valid, result.BrokenAt = false, id
+			break
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repo: ошибка итерации при проверке журнала: %w", err)
+	}
+	return result, nil
+}
+
+// nullJSON превращает пустой JSON в NULL для необязательных столбцов JSONB.
+func nullJSON(raw json.RawMessage) interface{} {
+	if len(raw) == 0 {
+		return nil
+	}
+	return []byte(raw)
+}
+
+// nullTime превращает нулевое время в NULL для необязательных условий выборки.
+func nullTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 )
 
 // User описывает модель пользователя системы.
@@ -14,8 +15,19 @@ type User struct {
 	Email        string `json:"email"`
 	PasswordHash string `json:"-"` // Скрываем хеш пароля при сериализации
 	Role         string `json:"role"`
+	IsActive     bool   `json:"is_active"`
+	// MustChangePassword — пользователь обязан сменить пароль, прежде чем работать с API
+	MustChangePassword bool      `json:"must_change_password"`
+	EmailVerified      bool      `json:"email_verified"`
+	Language           string    `json:"language"` // Язык писем и сообщений API: 'ru' или 'en'
+	TwoFactorEnabled   bool      `json:"two_factor_enabled"`
+	CreatedAt          time.Time `json:"created_at"`
 }
 
+// userColumns — общий список столбцов для выборок пользователей.
+const userColumns = `id, username, email, password_hash, role, is_active, must_change_password,
+	email_verified, language, totp_enabled, COALESCE(created_at, NOW())`
+
 // AuthRepository управляет хранением и поиском учетных данных пользователей.
 type AuthRepository struct {
 	db *sql.DB
@@ -30,28 +42,182 @@ func NewAuthRepository(db *sql.DB) *AuthRepository {
 
 // GetByUsername ищет пользователя по имени для процесса логина.
 func (r *AuthRepository) GetByUsername(ctx context.Context, username string) (*User, error) {
-	user := &User{}
-	query := `
-		SELECT id, username, email, password_hash, role 
-		FROM users 
-		WHERE username = $1`
+	query := `SELECT ` + userColumns + ` FROM users WHERE username = $1`
 
 	// Выполняем запрос с использованием контекста для контроля времени выполнения
-	err := r.db.QueryRowContext(ctx, query, username).Scan(
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, username))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, NotFound("пользователь '%s' не найден", username)
+		}
+		return nil, fmt.Errorf("repo: ошибка при поиске пользователя: %w", err)
+	}
+
+	return user, nil
+}
+
+// GetByID ищет пользователя по ID (например, при продлении сессии по refresh-токену).
+func (r *AuthRepository) GetByID(ctx context.Context, id int) (*User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1`
+
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, NotFound("пользователь с ID %d не найден", id)
+		}
+		return nil, fmt.Errorf("repo: ошибка при поиске пользователя: %w", err)
+	}
+
+	return user, nil
+}
+
+// GetByEmail ищет пользователя по адресу электронной почты (без учета регистра).
+func (r *AuthRepository) GetByEmail(ctx context.Context, email string) (*User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE LOWER(email) = LOWER($1)`
+
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, email))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, NotFound("пользователь с email '%s' не найден", email)
+		}
+		return nil, fmt.Errorf("repo: ошибка при поиске пользователя: %w", err)
+	}
+
+	return user, nil
+}
+
+// List возвращает пользователей; search ищет по вхождению в имя или email (без учета регистра).
+func (r *AuthRepository) List(ctx context.Context, search string) ([]User, error) {
+	query := `SELECT ` + userColumns + `
+		FROM users
+		WHERE $1 = '' OR username ILIKE '%' || $1 || '%' OR email ILIKE '%' || $1 || '%'
+		ORDER BY username ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, search)
+	if err != nil {
+		return nil, fmt.Errorf("repo: ошибка при получении списка пользователей: %w", err)
+	}
+	defer rows.Close()
+
+	users := make([]User, 0)
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("repo: ошибка сканирования пользователя: %w", err)
+		}
+		users = append(users, *user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repo: ошибка итерации строк: %w", err)
+	}
+	return users, nil
+}
+
+// Update сохраняет email, глобальную роль, признак активности и язык пользователя.
+// Смена email сбрасывает подтверждение адреса.
+func (r *AuthRepository) Update(ctx context.Context, user *User) error {
+	return r.auditedUpdate(ctx, user.ID, "update", `
+		UPDATE users
+		SET email_verified = email_verified AND email = $2,
+		    email = $2, role = $3, is_active = $4, language = COALESCE(NULLIF($5, ''), language)
+		WHERE id = $1`,
+		user.Email, user.Role, user.IsActive, user.Language,
+	)
+}
+
+// UpdatePassword записывает новый хеш пароля и флаг принудительной смены.
+// В журнал аудита попадает только сам факт смены: хеш из снимков исключен.
+func (r *AuthRepository) UpdatePassword(ctx context.Context, id int, passwordHash string, mustChange bool) error {
+	return r.auditedUpdate(ctx, id, "password_change",
+		"UPDATE users SET password_hash = $2, must_change_password = $3 WHERE id = $1",
+		passwordHash, mustChange,
+	)
+}
+
+// SetEmailVerified отмечает адрес электронной почты пользователя как подтвержденный.
+func (r *AuthRepository) SetEmailVerified(ctx context.Context, id int) error {
+	return r.auditedUpdate(ctx, id, "email_verify", "UPDATE users SET email_verified = TRUE WHERE id = $1")
+}
+
+// auditedUpdate выполняет UPDATE пользователя с ID id (параметр $1, остальные — args)
+// и записывает изменение в журнал аудита в той же транзакции.
+func (r *AuthRepository) auditedUpdate(ctx context.Context, id int, action, query string, args ...interface{}) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("repo: не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback()
+
+	before, err := snapshot(ctx, tx, "users", "id", id)
+	if err != nil {
+		return err
+	}
+	if before == nil {
+		return NotFound("пользователь с ID %d не найден", id)
+	}
+
+	if _, err := tx.ExecContext(ctx, query, append([]interface{}{id}, args...)...); err != nil {
+		return dbError("не удалось обновить пользователя", err)
+	}
+
+	if err := recordChange(ctx, tx, "user", "users", id, action, before); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("repo: ошибка фиксации транзакции: %w", err)
+	}
+	return nil
+}
+
+// Delete удаляет пользователя; его токены и роли удаляются каскадно.
+func (r *AuthRepository) Delete(ctx context.Context, id int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("repo: не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback()
+
+	before, err := snapshot(ctx, tx, "users", "id", id)
+	if err != nil {
+		return err
+	}
+	if before == nil {
+		return NotFound("пользователь с ID %d не найден", id)
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM users WHERE id = $1", id); err != nil {
+		return dbError("ошибка при удалении пользователя", err)
+	}
+
+	if err := recordChange(ctx, tx, "user", "users", id, "delete", before); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("repo: ошибка фиксации транзакции: %w", err)
+	}
+	return nil
+}
+
+// scanUser читает одну строку пользователя в порядке userColumns.
+func scanUser(row rowScanner) (*User, error) {
+	user := &User{}
+	err := row.Scan(
 		&user.ID,
 		&user.Username,
 		&user.Email,
 		&user.PasswordHash,
 		&user.Role,
+		&user.IsActive,
+		&user.MustChangePassword,
+		&user.EmailVerified,
+		&user.Language,
+		&user.TwoFactorEnabled,
+		&user.CreatedAt,
 	)
-
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("пользователь '%s' не найден", username)
-		}
-		return nil, fmt.Errorf("repo: ошибка при поиске пользователя: %w", err)
+		return nil, err
 	}
-
 	return user, nil
 }
 
@@ -59,25 +225,39 @@ func (r *AuthRepository) GetByUsername(ctx context.Context, username string) (*U
 func (r *AuthRepository) CreateUser(ctx context.Context, user *User) error {
 	// Устанавливаем роль по умолчанию, если она не задана
 	if user.Role == "" {
-		user.Role = "user"
+		user.Role = "viewer"
 	}
 
 	query := `
-		INSERT INTO users (username, email, password_hash, role)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id`
+		INSERT INTO users (username, email, password_hash, role, is_active, must_change_password, language)
+		VALUES ($1, $2, $3, $4, TRUE, $5, COALESCE(NULLIF($6, ''), 'ru'))
+		RETURNING id, is_active, email_verified, language, COALESCE(created_at, NOW())`
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("repo: не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback()
 
 	// RETURNING id позволяет нам сразу обновить структуру user после вставки
-	err := r.db.QueryRowContext(ctx, query,
+	err = tx.QueryRowContext(ctx, query,
 		user.Username,
 		user.Email,
 		user.PasswordHash,
 		user.Role,
-	).Scan(&user.ID)
+		user.MustChangePassword,
+		user.Language,
+	).Scan(&user.ID, &user.IsActive, &user.EmailVerified, &user.Language, &user.CreatedAt)
 
 	if err != nil {
-		return fmt.Errorf("repo: не удалось создать пользователя: %w", err)
+		return dbError("не удалось создать пользователя", err)
 	}
 
+	if err := recordChange(ctx, tx, "user", "users", user.ID, "create", nil); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("repo: ошибка фиксации транзакции: %w", err)
+	}
 	return nil
 }
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Club описывает спортивный клуб, за который выступает спортсмен.
+type Club struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	City string `json:"city"`
+}
+
+// ClubRepository предоставляет методы для работы с таблицей clubs.
+type ClubRepository struct {
+	db *sql.DB
+}
+
+// NewClubRepository создает новый экземпляр репозитория клубов.
+func NewClubRepository(db *sql.DB) *ClubRepository {
+	return &ClubRepository{db: db}
+}
+
+// --- МЕТОДЫ ДОСТУПА К ДАННЫМ ---
+
+// Create добавляет новый клуб и возвращает присвоенный ID.
+func (r *ClubRepository) Create(ctx context.Context, c *Club) error {
+	query := `
+		INSERT INTO clubs (name, city)
+		VALUES ($1, NULLIF($2, ''))
+		RETURNING id`
+
+	if err := r.db.QueryRowContext(ctx, query, c.Name, c.City).Scan(&c.ID); err != nil {
+		return dbError("не удалось создать клуб", err)
+	}
+	return nil
+}
+
+// ListAll возвращает список всех клубов в алфавитном порядке.
+func (r *ClubRepository) ListAll(ctx context.Context) ([]Club, error) {
+	query := `
+		SELECT id, name, COALESCE(city, '')
+		FROM clubs
+		ORDER BY name ASC`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("repo: ошибка при получении списка клубов: %w", err)
+	}
+	defer rows.Close()
+
+	clubs := make([]Club, 0)
+	for rows.Next() {
+		var c Club
+		if err := rows.Scan(&c.ID, &c.Name, &c.City); err != nil {
+			return nil, fmt.Errorf("repo: ошибка сканирования клуба: %w", err)
+		}
+		clubs = append(clubs, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repo: ошибка итерации строк: %w", err)
+	}
+	return clubs, nil
+}
@@ -13,6 +13,12 @@ type Competition struct {
 	Name      string    `json:"name"`
 	Location  string    `json:"location"`
 	StartDate time.Time `json:"start_date"`
+	Level     string    `json:"level"`    // Уровень: 'regional', 'national', 'international' и т.п.
+	SportID   int       `json:"sport_id"` // 0 означает, что вид спорта не указан
+	// Version увеличивается при каждом изменении; служит ETag для оптимистичной блокировки
+	Version int `json:"version"`
+	// DeletedAt — время мягкого удаления; удаленные соревнования скрыты из выборок по умолчанию
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
 
 // CompetitionRepository инкапсулирует логику работы с таблицей соревнований.
@@ -29,102 +35,281 @@ func NewCompetitionRepository(db *sql.DB) *CompetitionRepository {
 
 // Create сохраняет новое соревнование и возвращает сгенерированный базой ID.
 func (r *CompetitionRepository) Create(ctx context.Context, c *Competition) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("repo: не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback()
+
 	query := `
-		INSERT INTO competitions (name, location, start_date)
-		VALUES ($1, $2, $3)
-		RETURNING id`
+		INSERT INTO competitions (name, location, start_date, level, sport_id)
+		VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, 0))
+		RETURNING id, version`
 
 	// Используем QueryRowContext для безопасного выполнения в рамках контекста запроса
-	err := r.db.QueryRowContext(ctx, query,
-		c.Name, c.Location, c.StartDate,
-	).Scan(&c.ID)
+	err = tx.QueryRowContext(ctx, query,
+		c.Name, c.Location, c.StartDate, c.Level, c.SportID,
+	).Scan(&c.ID, &c.Version)
 
 	if err != nil {
-		return fmt.Errorf("repo: ошибка при создании соревнования: %w", err)
+		return dbError("ошибка при создании соревнования", err)
+	}
+
+	if err := recordChange(ctx, tx, "competition", "competitions", c.ID, "create", nil); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("repo: ошибка фиксации транзакции: %w", err)
 	}
 	return nil
 }
 
-// GetByID возвращает данные конкретного соревнования по его первичному ключу.
+// competitionColumns — общий список столбцов для выборок соревнований.
+const competitionColumns = `id, name, location, start_date, COALESCE(level, ''), COALESCE(sport_id, 0), version, deleted_at`
+
+// GetByID возвращает данные конкретного (не удаленного) соревнования по его первичному ключу.
 func (r *CompetitionRepository) GetByID(ctx context.Context, id int) (*Competition, error) {
-	query := `
-		SELECT id, name, location, start_date
-		FROM competitions WHERE id = $1`
+	return r.Find(ctx, id, false)
+}
 
-	c := &Competition{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&c.ID, &c.Name, &c.Location, &c.StartDate,
-	)
+// Find возвращает соревнование по ID; при includeDeleted — и мягко удаленное.
+func (r *CompetitionRepository) Find(ctx context.Context, id int, includeDeleted bool) (*Competition, error) {
+	query := `
+		SELECT ` + competitionColumns + `
+		FROM competitions WHERE id = $1 AND ($2 OR deleted_at IS NULL)`
 
+	c, err := scanCompetition(r.db.QueryRowContext(ctx, query, id, includeDeleted))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("соревнование с ID %d не найдено", id)
+			return nil, NotFound("соревнование с ID %d не найдено", id)
 		}
 		return nil, fmt.Errorf("repo: ошибка при получении данных: %w", err)
 	}
 	return c, nil
 }
 
-// ListAll возвращает полный список соревнований, отсортированный по дате начала (сначала новые).
-func (r *CompetitionRepository) ListAll(ctx context.Context) ([]Competition, error) {
-	query := `
-		SELECT id, name, location, start_date
-		FROM competitions ORDER BY start_date DESC`
+// FindAsOf возвращает соревнование в том виде, в каком оно было на момент at (по истории версий).
+func (r *CompetitionRepository) FindAsOf(ctx context.Context, id int, at time.Time) (*Competition, error) {
+	query := `SELECT ` + competitionColumns + ` FROM ` + asOfSource("competitions") + ` WHERE deleted_at IS NULL`
 
-	rows, err := r.db.QueryContext(ctx, query)
+	c, err := scanCompetition(r.db.QueryRowContext(ctx, query, id, at))
 	if err != nil {
-		return nil, fmt.Errorf("repo: ошибка запроса списка: %w", err)
+		if err == sql.ErrNoRows {
+			return nil, NotFound("соревнование с ID %d не найдено на %s", id, at.Format(time.RFC3339))
+		}
+		return nil, fmt.Errorf("repo: ошибка при поиске версии соревнования: %w", err)
 	}
-	defer rows.Close() // Закрываем соединение после обработки всех строк
+	return c, nil
+}
+
+// CompetitionFilter — условия выборки списка соревнований. Пустые поля не ограничивают выборку.
+type CompetitionFilter struct {
+	Name           string    // Подстрока названия без учета регистра
+	Location       string    // Подстрока места проведения без учета регистра
+	DateFrom       time.Time // Дата начала не раньше (включительно)
+	DateTo         time.Time // Дата начала не позже (включительно)
+	Level          string
+	SportID        int
+	IncludeDeleted bool // Показывать и мягко удаленные
+}
 
-	var competitions []Competition
-	for rows.Next() {
-		c := Competition{}
-		if err := rows.Scan(&c.ID, &c.Name, &c.Location, &c.StartDate); err != nil {
-			return nil, fmt.Errorf("repo: ошибка сканирования данных: %w", err)
+// competitionList — постраничный список соревнований и поля, по которым его можно сортировать.
+var competitionList = listSpec{
+	columns: competitionColumns,
+	from:    "competitions",
+	id:      "id",
+	sorts: map[string]sortField{
+		"id":         {expr: "id", cast: "INT"},
+		"name":       {expr: "name", cast: "TEXT"},
+		"location":   {expr: "location", cast: "TEXT"},
+		"start_date": {expr: "start_date", cast: "DATE"},
+	},
+}
+
+// SortFields возвращает поля, по которым можно сортировать список соревнований.
+func (r *CompetitionRepository) SortFields() []string {
+	return competitionList.sortNames()
+}
+
+// List возвращает страницу списка соревнований по фильтру и общее число подходящих записей.
+func (r *CompetitionRepository) List(ctx context.Context, f CompetitionFilter, page Page) ([]Competition, *PageInfo, error) {
+	q := &listQuery{}
+	if !f.IncludeDeleted {
+		q.where("deleted_at IS NULL")
+	}
+	if f.Name != "" {
+		q.where("name ILIKE %s", containsPattern(f.Name))
+	}
+	if f.Location != "" {
+		q.where("location ILIKE %s", containsPattern(f.Location))
+	}
+	if !f.DateFrom.IsZero() {
+		q.where("start_date >= %s::DATE", f.DateFrom.Format("2006-01-02"))
+	}
+	if !f.DateTo.IsZero() {
+		q.where("start_date <= %s::DATE", f.DateTo.Format("2006-01-02"))
+	}
+	if f.Level != "" {
+		q.where("level = %s", f.Level)
+	}
+	if f.SportID > 0 {
+		q.where("sport_id = %s", f.SportID)
+	}
+
+	competitions := make([]Competition, 0)
+	info, err := competitionList.fetch(ctx, r.db, q, page, func(row rowScanner) (int, error) {
+		c, err := scanCompetition(row)
+		if err != nil {
+			return 0, err
 		}
-		competitions = append(competitions, c)
+		competitions = append(competitions, *c)
+		return c.ID, nil
+	})
+	if err != nil {
+		return nil, nil, err
 	}
+	return competitions, info, nil
+}
 
-	// Проверяем финальное состояние итератора на наличие скрытых ошибок
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("repo: ошибка обработки строк: %w", err)
+// scanCompetition читает одну строку соревнования в порядке competitionColumns.
+func scanCompetition(row rowScanner) (*Competition, error) {
+	c := &Competition{}
+	var deletedAt sql.NullTime
+	if err := row.Scan(&c.ID, &c.Name, &c.Location, &c.StartDate, &c.Level, &c.SportID, &c.Version, &deletedAt); err != nil {
+		return nil, err
 	}
-	return competitions, nil
+	if deletedAt.Valid {
+		c.DeletedAt = &deletedAt.Time
+	}
+	return c, nil
 }
 
-// Update изменяет информацию о соревновании. Возвращает ошибку, если запись не найдена.
-func (r *CompetitionRepository) Update(ctx context.Context, c *Competition) error {
+// Update изменяет информацию о соревновании. Возвращает ошибку, если запись не найдена,
+// и ErrVersionConflict, если ее версия отличается от expectedVersion (AnyVersion — без проверки).
+func (r *CompetitionRepository) Update(ctx context.Context, c *Competition, expectedVersion int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("repo: не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Снимок до изменения одновременно проверяет, что запись существует
+	before, err := snapshot(ctx, tx, "competitions", "id", c.ID)
+	if err != nil {
+		return err
+	}
+	if before == nil || isDeleted(before) {
+		return NotFound("соревнование с ID %d не найдено", c.ID)
+	}
+	if err := checkVersion(before, expectedVersion); err != nil {
+		return err
+	}
+
 	query := `
 		UPDATE competitions 
-		SET name = $1, location = $2, start_date = $3
-		WHERE id = $4`
+		SET name = $1, location = $2, start_date = $3, level = NULLIF($4, ''), sport_id = NULLIF($5, 0)
+		WHERE id = $6
+		RETURNING version`
 
-	result, err := r.db.ExecContext(ctx, query, c.Name, c.Location, c.StartDate, c.ID)
+	err = tx.QueryRowContext(ctx, query, c.Name, c.Location, c.StartDate, c.Level, c.SportID, c.ID).Scan(&c.Version)
 	if err != nil {
-		return fmt.Errorf("repo: ошибка обновления данных: %w", err)
+		return dbError("ошибка обновления данных", err)
 	}
 
-	// Важный шаг: проверяем, затронул ли запрос хоть одну строку
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		return fmt.Errorf("соревнование с ID %d не найдено", c.ID)
+	if err := recordChange(ctx, tx, "competition", "competitions", c.ID, "update", before); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("repo: ошибка фиксации транзакции: %w", err)
 	}
 	return nil
 }
 
-// Delete удаляет соревнование по его ID.
-func (r *CompetitionRepository) Delete(ctx context.Context, id int) error {
-	query := `DELETE FROM competitions WHERE id = $1`
+// Patch изменяет только переданные в p поля соревнования и возвращает его новое состояние.
+// Неизменившиеся столбцы в UPDATE не попадают; если не меняется ничего, версия и журнал аудита остаются прежними.
+func (r *CompetitionRepository) Patch(ctx context.Context, id int, p CompetitionPatch, expectedVersion int) (*Competition, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("repo: не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback()
+
+	before, err := snapshot(ctx, tx, "competitions", "id", id)
+	if err != nil {
+		return nil, err
+	}
+	if before == nil || isDeleted(before) {
+		return nil, NotFound("соревнование с ID %d не найдено", id)
+	}
+	if err := checkVersion(before, expectedVersion); err != nil {
+		return nil, err
+	}
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	current, err := scanCompetition(tx.QueryRowContext(ctx, `SELECT `+competitionColumns+` FROM competitions WHERE id = $1`, id))
 	if err != nil {
-		return fmt.Errorf("repo: ошибка при удалении записи: %w", err)
+		return nil, fmt.Errorf("repo: ошибка при чтении соревнования: %w", err)
 	}
 
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		return fmt.Errorf("соревнование с ID %d не найдено для удаления", id)
+	var set columnSet
+	if p.Name != nil && *p.Name != current.Name {
+		set.add("name", "%s", *p.Name)
 	}
-	return nil
+	if p.Location != nil && *p.Location != current.Location {
+		set.add("location", "%s", *p.Location)
+	}
+	if p.StartDate != nil && !sameDay(*p.StartDate, current.StartDate) {
+		set.add("start_date", "%s", *p.StartDate)
+	}
+	if p.Level != nil && *p.Level != current.Level {
+		set.add("level", "NULLIF(%s, '')", *p.Level)
+	}
+	if p.SportID != nil && *p.SportID != current.SportID {
+		set.add("sport_id", "NULLIF(%s, 0)", *p.SportID)
+	}
+	if set.empty() {
+		return current, nil
+	}
+
+	query := `UPDATE competitions SET ` + set.sql() + ` WHERE id = $1 RETURNING ` + competitionColumns
+	updated, err := scanCompetition(tx.QueryRowContext(ctx, query, append([]interface{}{id}, set.args...)...))
+	if err != nil {
+		return nil, dbError("ошибка обновления данных", err)
+	}
+
+	if err := recordChange(ctx, tx, "competition", "competitions", id, "update", before); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("repo: ошибка фиксации транзакции: %w", err)
+	}
+	return updated, nil
+}
+
+// Delete мягко удаляет соревнование: участия и результаты сохраняются, но скрываются из выборок.
+// expectedVersion проверяется так же, как в Update.
+func (r *CompetitionRepository) Delete(ctx context.Context, id, expectedVersion int) error {
+	return setDeleted(ctx, r.db, "competition", "competitions", id, true, expectedVersion)
+}
+
+// Restore возвращает мягко удаленное соревнование вместе с его участиями.
+func (r *CompetitionRepository) Restore(ctx context.Context, id int) error {
+	return setDeleted(ctx, r.db, "competition", "competitions", id, false, AnyVersion)
+}
+
+// Purge безвозвратно удаляет мягко удаленное соревнование вместе с участиями и результатами.
+// Настройки соревнования (нормативы, жеребьевки, весовые категории) удаляются каскадно.
+func (r *CompetitionRepository) Purge(ctx context.Context, id int) error {
+	return purge(ctx, r.db, "competition", "competitions", "competition_id", id, time.Time{})
+}
+
+// PurgeUnreferenced безвозвратно удаляет мягко удаленное соревнование, только если все его участия
+// удалены раньше cutoff (иначе — ErrConflict). Используется автоматической очисткой: удалять действующие
+// или недавно удаленные участия вместе с результатами можно только явным запросом администратора (Purge).
+func (r *CompetitionRepository) PurgeUnreferenced(ctx context.Context, id int, cutoff time.Time) error {
+	return purge(ctx, r.db, "competition", "competitions", "competition_id", id, cutoff)
+}
+
+// ListDeletedBefore возвращает ID соревнований, удаленных раньше cutoff (кандидаты на очистку).
+func (r *CompetitionRepository) ListDeletedBefore(ctx context.Context, cutoff time.Time) ([]int, error) {
+	return listDeletedBefore(ctx, r.db, "competitions", cutoff)
 }
@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// AnyVersion — ожидаемая версия для If-Match: * (подходит любая существующая версия записи).
+const AnyVersion = -1
+
+var (
+	// ErrVersionConflict — запись изменилась с тех пор, как клиент ее прочитал (ETag не совпал).
+	ErrVersionConflict = errors.New("запись была изменена другим пользователем, обновите данные и повторите")
+	// ErrVersionRequired — запись существует, а клиент не передал ее версию (If-Match).
+	ErrVersionRequired = errors.New("для изменения записи требуется ее текущая версия (If-Match)")
+)
+
+// checkVersion сравнивает версию из снимка строки (см. snapshot) с ожидаемой клиентом.
+func checkVersion(row json.RawMessage, expected int) error {
+	if expected == AnyVersion {
+		return nil
+	}
+	var state struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(row, &state); err != nil || state.Version != expected {
+		return ErrVersionConflict
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Draw описывает одну жеребьевку с публичным обязательством (хешем зерна).
+// Пока жеребьевка не проведена, поле Seed не должно попадать в API ответы.
+type Draw struct {
+	ID            int        `json:"id"`
+	CompetitionID int        `json:"competition_id"`
+	Kind          string     `json:"kind"` // 'start_order', 'bib'
+	SeedHash      string     `json:"seed_hash"`
+	Seed          string     `json:"seed,omitempty"`
+	Status        string     `json:"status"` // 'committed', 'revealed'
+	Items         []int      `json:"items,omitempty"`
+	Subjects      []int      `json:"subjects,omitempty"`
+	Result        []int      `json:"result,omitempty"`
+	CreatedBy     string     `json:"created_by"`
+	CreatedAt     time.Time  `json:"created_at"`
+	RevealedAt    *time.Time `json:"revealed_at,omitempty"`
+}
+
+// DrawRepository управляет хранением жеребьевок.
+type DrawRepository struct {
+	db *sql.DB
+}
+
+// NewDrawRepository создает новый экземпляр репозитория жеребьевок.
+func NewDrawRepository(db *sql.DB) *DrawRepository {
+	return &DrawRepository{db: db}
+}
+
+// --- МЕТОДЫ РАБОТЫ С ДАННЫМИ ---
+
+// Create сохраняет новую жеребьевку в статусе 'committed'.
+func (r *DrawRepository) Create(ctx context.Context, d *Draw) error {
+	query := `
+		INSERT INTO draws (competition_id, kind, seed_hash, seed, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, status, created_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		d.CompetitionID, d.Kind, d.SeedHash, d.Seed, d.CreatedBy,
+	).Scan(&d.ID, &d.Status, &d.CreatedAt)
+
+	if err != nil {
+		return dbError("не удалось создать жеребьевку", err)
+	}
+	return nil
+}
+
+// GetByID возвращает жеребьевку вместе с зерном (скрытие зерна — задача сервиса).
+func (r *DrawRepository) GetByID(ctx context.Context, id int) (*Draw, error) {
+	query := `
+		SELECT id, competition_id, kind, seed_hash, seed, status, items, subjects, result,
+		       COALESCE(created_by, ''), created_at, revealed_at
+		FROM draws
+		WHERE id = $1`
+
+	d, err := scanDraw(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, NotFound("жеребьевка с ID %d не найдена", id)
+		}
+		return nil, fmt.Errorf("repo: ошибка при получении жеребьевки: %w", err)
+	}
+	return d, nil
+}
+
+// ListByCompetition возвращает все жеребьевки соревнования (сначала последние).
+func (r *DrawRepository) ListByCompetition(ctx context.Context, competitionID int) ([]Draw, error) {
+	query := `
+		SELECT id, competition_id, kind, seed_hash, seed, status, items, subjects, result,
+		       COALESCE(created_by, ''), created_at, revealed_at
+		FROM draws
+		WHERE competition_id = $1
+		ORDER BY created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, competitionID)
+	if err != nil {
+		return nil, fmt.Errorf("repo: ошибка при получении жеребьевок: %w", err)
+	}
+	defer rows.Close()
+
+	draws := make([]Draw, 0)
+	for rows.Next() {
+		d, err := scanDraw(rows)
+		if err != nil {
+			return nil, fmt.Errorf("repo: ошибка сканирования жеребьевки: %w", err)
+		}
+		draws = append(draws, *d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repo: ошибка итерации строк: %w", err)
+	}
+	return draws, nil
+}
+
+// Reveal сохраняет входные данные и результат жеребьевки и раскрывает зерно.
+// Жеребьевку можно провести только один раз.
+func (r *DrawRepository) Reveal(ctx context.Context, d *Draw) error {
+	items, _ := json.Marshal(d.Items)
+	subjects, _ := json.Marshal(d.Subjects)
+	result, _ := json.Marshal(d.Result)
+
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE draws
+		SET status = 'revealed', items = $2, subjects = $3, result = $4, revealed_at = NOW()
+		WHERE id = $1 AND status = 'committed'
+		RETURNING status, revealed_at`,
+		d.ID, items, subjects, result,
+	).Scan(&d.Status, &d.RevealedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return Conflict("жеребьевка с ID %d уже проведена", d.ID)
+		}
+		return fmt.Errorf("repo: не удалось сохранить результат жеребьевки: %w", err)
+	}
+	return nil
+}
+
+// rowScanner — общий интерфейс *sql.Row и *sql.Rows для повторного использования кода сканирования.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanDraw читает одну строку таблицы draws, разбирая JSON-поля.
+func scanDraw(row rowScanner) (*Draw, error) {
+	var (
+		d                       Draw
+		items, subjects, result []byte
+		revealedAt              sql.NullTime
+	)
+	err := row.Scan(
+		&d.ID, &d.CompetitionID, &d.Kind, &d.SeedHash, &d.Seed, &d.Status,
+		&items, &subjects, &result, &d.CreatedBy, &d.CreatedAt, &revealedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range []struct {
+		raw  []byte
+		dest *[]int
+	}{{items, &d.Items}, {subjects, &d.Subjects}, {result, &d.Result}} {
+		if len(f.raw) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(f.raw, f.dest); err != nil {
+			return nil, fmt.Errorf("повреждены данные жеребьевки %d: %w", d.ID, err)
+		}
+	}
+	if revealedAt.Valid {
+		d.RevealedAt = &revealedAt.Time
+	}
+	return &d, nil
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// EntryStandard описывает отборочный норматив для допуска к соревнованию.
+// Участник допускается, если его заявочный результат не хуже порога
+// и показан не ранее чем за QualifyingPeriodDays дней до заявки.
+type EntryStandard struct {
+	ID                   int     `json:"id"`
+	CompetitionID        int     `json:"competition_id"`
+	Event                string  `json:"event"`
+	Gender               string  `json:"gender"` // Пустая строка — для всех
+	ResultThreshold      float64 `json:"result_threshold"`
+	LowerIsBetter        bool    `json:"lower_is_better"`
+	QualifyingPeriodDays int     `json:"qualifying_period_days"`
+}
+
+// EntryStandardRepository управляет отборочными нормативами соревнований.
+type EntryStandardRepository struct {
+	db *sql.DB
+}
+
+// NewEntryStandardRepository создает новый экземпляр репозитория нормативов допуска.
+func NewEntryStandardRepository(db *sql.DB) *EntryStandardRepository {
+	return &EntryStandardRepository{db: db}
+}
+
+// --- МЕТОДЫ РАБОТЫ С ДАННЫМИ ---
+
+// Create сохраняет новый отборочный норматив.
+func (r *EntryStandardRepository) Create(ctx context.Context, s *EntryStandard) error {
+	query := `
+		INSERT INTO entry_standards
+			(competition_id, event, gender, result_threshold, lower_is_better, qualifying_period_days)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6)
+		RETURNING id`
+
+	err := r.db.QueryRowContext(ctx, query,
+		s.CompetitionID, s.Event, s.Gender, s.ResultThreshold, s.LowerIsBetter, s.QualifyingPeriodDays,
+	).Scan(&s.ID)
+
+	if err != nil {
+		return dbError("не удалось создать отборочный норматив", err)
+	}
+	return nil
+}
+
+// ListByCompetition возвращает все отборочные нормативы соревнования.
+func (r *EntryStandardRepository) ListByCompetition(ctx context.Context, competitionID int) ([]EntryStandard, error) {
+	query := `
+		SELECT id, competition_id, event, COALESCE(gender, ''), result_threshold, lower_is_better, qualifying_period_days
+		FROM entry_standards
+		WHERE competition_id = $1
+		ORDER BY event, gender`
+
+	rows, err := r.db.QueryContext(ctx, query, competitionID)
+	if err != nil {
+		return nil, fmt.Errorf("repo: ошибка при получении отборочных нормативов: %w", err)
+	}
+	defer rows.Close()
+
+	standards := make([]EntryStandard, 0)
+	for rows.Next() {
+		var s EntryStandard
+		err := rows.Scan(&s.ID, &s.CompetitionID, &s.Event, &s.Gender, &s.ResultThreshold, &s.LowerIsBetter, &s.QualifyingPeriodDays)
+		if err != nil {
+			return nil, fmt.Errorf("repo: ошибка сканирования норматива: %w", err)
+		}
+		standards = append(standards, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repo: ошибка итерации строк: %w", err)
+	}
+	return standards, nil
+}
+
+// Find ищет норматив, применимый к дисциплине и полу участника.
+// Норматив для конкретного пола имеет приоритет над общим. Возвращает nil, если норматива нет.
+func (r *EntryStandardRepository) Find(ctx context.Context, competitionID int, event, gender string) (*EntryStandard, error) {
+	query := `
+		SELECT id, competition_id, event, COALESCE(gender, ''), result_threshold, lower_is_better, qualifying_period_days
+		FROM entry_standards
+		WHERE competition_id = $1 AND event = $2 AND (gender IS NULL OR gender = $3)
+		ORDER BY gender NULLS LAST
+		LIMIT 1`
+
+	s := &EntryStandard{}
+	err := r.db.QueryRowContext(ctx, query, competitionID, event, gender).Scan(
+		&s.ID, &s.CompetitionID, &s.Event, &s.Gender, &s.ResultThreshold, &s.LowerIsBetter, &s.QualifyingPeriodDays,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Свободный допуск
+		}
+		return nil, fmt.Errorf("repo: ошибка при поиске отборочного норматива: %w", err)
+	}
+	return s, nil
+}
+
+// Delete удаляет отборочный норматив соревнования.
+func (r *EntryStandardRepository) Delete(ctx context.Context, competitionID, id int) error {
+	result, err := r.db.ExecContext(ctx,
+		"DELETE FROM entry_standards WHERE id = $1 AND competition_id = $2",
+		id, competitionID,
+	)
+	if err != nil {
+		return dbError("ошибка при удалении норматива", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return NotFound("отборочный норматив с ID %d не найден", id)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrNotFound — запрошенной записи нет (или она удалена).
+	ErrNotFound = errors.New("запись не найдена")
+	// ErrConflict — операция противоречит текущему состоянию данных (дубликат, запись уже используется и т.п.).
+	ErrConflict = errors.New("конфликт с текущим состоянием данных")
+)
+
+// DomainError — ошибка предметной области с сообщением для клиента.
+// Kind — одна из сигнальных ошибок (ErrNotFound, ErrConflict), по ней хендлеры выбирают HTTP-статус:
+// errors.Is(err, ErrNotFound) срабатывает и для обернутой DomainError.
+// Сообщение хранится как формат и аргументы, чтобы хендлер мог перевести его на язык клиента (см. i18n).
+type DomainError struct {
+	Kind   error
+	Format string
+	Args   []interface{}
+}
+
+func (e *DomainError) Error() string { return fmt.Sprintf(e.Format, e.Args...) }
+
+func (e *DomainError) Unwrap() error { return e.Kind }
+
+// NotFound возвращает ошибку «не найдено» с сообщением по формату.
+func NotFound(format string, args ...interface{}) error {
+	return &DomainError{Kind: ErrNotFound, Format: format, Args: args}
+}
+
+// Conflict возвращает ошибку конфликта с сообщением по формату.
+func Conflict(format string, args ...interface{}) error {
+	return &DomainError{Kind: ErrConflict, Format: format, Args: args}
+}
+
+// Коды ошибок PostgreSQL, которые означают конфликт с данными, а не сбой базы.
+const (
+	pqUniqueViolation     = "23505"
+	pqForeignKeyViolation = "23503"
+)
+
+// constraintMessages — понятные клиенту тексты нарушений известных ограничений схемы.
+var constraintMessages = map[string]string{
+	"athletes_full_name_birth_date_key":               "спортсмен с таким ФИО и датой рождения уже существует",
+	"competitions_title_start_date_key":               "соревнование с таким названием и датой начала уже существует",
+	"idx_participations_active":                       "спортсмен уже заявлен на это соревнование",
+	"idx_participations_bib":                          "стартовый номер уже занят в этом соревновании",
+	"clubs_name_key":                                  "клуб с таким названием уже существует",
+	"sports_name_key":                                 "вид спорта с таким названием уже существует",
+	"users_username_key":                              "пользователь с таким логином уже существует",
+	"users_email_key":                                 "пользователь с таким email уже существует",
+	"idx_entry_standards_unique":                      "отборочный норматив для этой дисциплины и категории уже задан",
+	"weight_classes_competition_id_gender_name_key":   "весовая категория с таким названием уже есть в соревновании",
+	"user_roles_user_id_role_scope_type_scope_id_key": "такая роль уже назначена пользователю",
+	"user_identities_issuer_subject_key":              "учетная запись провайдера уже привязана",
+	"participations_athlete_id_fkey":                  "указанный спортсмен не найден",
+	"participations_competition_id_fkey":              "указанное соревнование не найдено",
+}
+
+// dbError переводит ошибку базы в ошибку предметной области: нарушение уникальности
+// и внешнего ключа — ErrConflict, остальное оборачивается как сбой репозитория с описанием action.
+func dbError(action string, err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return fmt.Errorf("repo: %s: %w", action, err)
+	}
+	msg, known := constraintMessages[pqErr.Constraint]
+	switch pqErr.Code {
+	case pqUniqueViolation:
+		if !known {
+			msg = "запись с такими данными уже существует"
+		}
+		return &DomainError{Kind: ErrConflict, Format: msg}
+	case pqForeignKeyViolation:
+		if !known {
+			msg = "связанная запись не найдена или на запись есть ссылки"
+		}
+		return &DomainError{Kind: ErrConflict, Format: msg}
+	}
+	return fmt.Errorf("repo: %s: %w", action, err)
+}
@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// UserIdentity — учетная запись внешнего провайдера (OIDC), привязанная к пользователю.
+type UserIdentity struct {
+	ID          int        `json:"id"`
+	UserID      int        `json:"user_id"`
+	Issuer      string     `json:"issuer"`
+	Subject     string     `json:"subject"`
+	Email       string     `json:"email"`
+	CreatedAt   time.Time  `json:"created_at"`
+	LastLoginAt *time.Time `json:"last_login_at"`
+}
+
+// OIDCLogin — начатый вход через провайдера: секреты, которые понадобятся при возврате.
+type OIDCLogin struct {
+	Nonce        string
+	CodeVerifier string
+	LinkUserID   int // Не 0 — привязка провайдера к существующей учетной записи
+	ExpiresAt    time.Time
+}
+
+// IdentityRepository управляет внешними учетными записями и начатыми входами через OIDC.
+type IdentityRepository struct {
+	db *sql.DB
+}
+
+// NewIdentityRepository создает новый экземпляр репозитория внешних учетных записей.
+func NewIdentityRepository(db *sql.DB) *IdentityRepository {
+	return &IdentityRepository{db: db}
+}
+
+// --- ВНЕШНИЕ УЧЕТНЫЕ ЗАПИСИ ---
+
+// GetBySubject ищет привязку по издателю и идентификатору пользователя у провайдера.
+// Возвращает nil, если такой пользователь провайдера еще не входил.
+func (r *IdentityRepository) GetBySubject(ctx context.Context, issuer, subject string) (*UserIdentity, error) {
+	var id UserIdentity
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, user_id, issuer, subject, COALESCE(email, ''), created_at, last_login_at
+		FROM user_identities
+		WHERE issuer = $1 AND subject = $2`,
+		issuer, subject,
+	).Scan(&id.ID, &id.UserID, &id.Issuer, &id.Subject, &id.Email, &id.CreatedAt, &id.LastLoginAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("repo: ошибка при поиске внешней учетной записи: %w", err)
+	}
+	return &id, nil
+}
+
+// ListByUser возвращает внешние учетные записи пользователя.
+func (r *IdentityRepository) ListByUser(ctx context.Context, userID int) ([]UserIdentity, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, user_id, issuer, subject, COALESCE(email, ''), created_at, last_login_at
+		FROM user_identities
+		WHERE user_id = $1
+		ORDER BY created_at`,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("repo: ошибка при получении внешних учетных записей: %w", err)
+	}
+	defer rows.Close()
+
+	identities := make([]UserIdentity, 0)
+	for rows.Next() {
+		var id UserIdentity
+		if err := rows.Scan(&id.ID, &id.UserID, &id.Issuer, &id.Subject, &id.Email, &id.CreatedAt, &id.LastLoginAt); err != nil {
+			return nil, fmt.Errorf("repo: ошибка сканирования внешней учетной записи: %w", err)
+		}
+		identities = append(identities, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repo: ошибка итерации строк: %w", err)
+	}
+	return identities, nil
+}
+
+// Link привязывает внешнюю учетную запись к пользователю.
+func (r *IdentityRepository) Link(ctx context.Context, id *UserIdentity) error {
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO user_identities (user_id, issuer, subject, email, last_login_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), NOW())
+		RETURNING id, created_at`,
+		id.UserID, id.Issuer, id.Subject, id.Email,
+	).Scan(&id.ID, &id.CreatedAt)
+
+	if err != nil {
+		return dbError("не удалось привязать внешнюю учетную запись", err)
+	}
+	return nil
+}
+
+// Touch отмечает вход через внешнюю учетную запись.
+func (r *IdentityRepository) Touch(ctx context.Context, id int) error {
+	if _, err := r.db.ExecContext(ctx, "UPDATE user_identities SET last_login_at = NOW() WHERE id = $1", id); err != nil {
+		return fmt.Errorf("repo: не удалось обновить внешнюю учетную запись: %w", err)
+	}
+	return nil
+}
+
+// Unlink отвязывает внешнюю учетную запись пользователя.
+func (r *IdentityRepository) Unlink(ctx context.Context, userID, id int) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM user_identities WHERE id = $1 AND user_id = $2", id, userID)
+	if err != nil {
+		return fmt.Errorf("repo: не удалось отвязать внешнюю учетную запись: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return NotFound("внешняя учетная запись с ID %d не найдена", id)
+	}
+	return nil
+}
+
+// --- НАЧАТЫЕ ВХОДЫ ---
+
+// CreateLogin сохраняет начатый вход через провайдера.
+func (r *IdentityRepository) CreateLogin(ctx context.Context, stateHash string, l *OIDCLogin) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO oidc_logins (state_hash, nonce, code_verifier, link_user_id, expires_at)
+		VALUES ($1, $2, $3, NULLIF($4, 0), $5)`,
+		stateHash, l.Nonce, l.CodeVerifier, l.LinkUserID, l.ExpiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("repo: не удалось сохранить вход через OIDC: %w", err)
+	}
+	return nil
+}
+
+// ConsumeLogin атомарно погашает начатый вход по state: повторный возврат с тем же state не пройдет.
+func (r *IdentityRepository) ConsumeLogin(ctx context.Context, stateHash string) (*OIDCLogin, error) {
+	var l OIDCLogin
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE oidc_logins SET used_at = NOW()
+		WHERE state_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING nonce, code_verifier, COALESCE(link_user_id, 0), expires_at`,
+		stateHash,
+	).Scan(&l.Nonce, &l.CodeVerifier, &l.LinkUserID, &l.ExpiresAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, NotFound("вход через OIDC не найден или истек, начните заново")
+		}
+		return nil, fmt.Errorf("repo: ошибка при завершении входа через OIDC: %w", err)
+	}
+	return &l, nil
+}
+
+// CreateHandoff сохраняет одноразовый код завершенного входа через провайдера.
+func (r *IdentityRepository) CreateHandoff(ctx context.Context, userID int, codeHash string, expiresAt time.Time) error {
+	_, err := r.db.ExecContext(ctx,
+		"INSERT INTO oidc_handoffs (user_id, code_hash, expires_at) VALUES ($1, $2, $3)",
+		userID, codeHash, expiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("repo: не удалось сохранить код входа через OIDC: %w", err)
+	}
+	return nil
+}
+
+// ConsumeHandoff атомарно погашает код входа и возвращает ID пользователя: код действует один раз.
+func (r *IdentityRepository) ConsumeHandoff(ctx context.Context, codeHash string) (int, error) {
+	var userID int
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE oidc_handoffs SET used_at = NOW()
+		WHERE code_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING user_id`,
+		codeHash,
+	).Scan(&userID)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, NotFound("код входа через OIDC не найден или истек, начните заново")
+		}
+		return 0, fmt.Errorf("repo: ошибка при обмене кода входа через OIDC: %w", err)
+	}
+	return userID, nil
+}
@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Page — запрошенная страница списка: поле сортировки из белого списка сущности,
+// направление, размер и позиция, с которой продолжить (nil — первая страница).
+type Page struct {
+	Sort  string
+	Desc  bool
+	Limit int
+	After *Cursor
+}
+
+// PageInfo — сведения о выборке для клиента: сколько всего записей подходит под фильтр
+// и курсор следующей страницы (nil — страница последняя).
+type PageInfo struct {
+	Total int
+	Next  *Cursor
+}
+
+// Cursor — позиция в списке: значение ключа сортировки и ID последней записи страницы.
+// Вместе с ключом хранится сортировка, для которой курсор выдан: с другой он не имеет смысла.
+type Cursor struct {
+	Sort string `json:"s"`
+	Desc bool   `json:"d,omitempty"`
+	Key  string `json:"k"`
+	ID   int    `json:"id"`
+}
+
+// Encode упаковывает курсор в непрозрачную для клиента строку.
+func (c *Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor разбирает строку, полученную от Encode.
+func DecodeCursor(s string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.New("курсор поврежден")
+	}
+	c := &Cursor{}
+	if err := json.Unmarshal(data, c); err != nil || c.Sort == "" || c.ID <= 0 {
+		return nil, errors.New("курсор поврежден")
+	}
+	return c, nil
+}
+
+// sortField — поле, по которому разрешено сортировать список.
+// Выражение не должно давать NULL: ключ сравнивается со значением из курсора построчно.
+type sortField struct {
+	expr string // SQL-выражение ключа сортировки
+	cast string // Тип, к которому приводится текстовое значение ключа из курсора
+}
+
+// listSpec описывает постраничный список сущности. Сортировка всегда дополняется ID,
+// поэтому порядок однозначен и продолжение с курсора не пропускает и не повторяет записи.
+type listSpec struct {
+	columns string               // Столбцы выборки в порядке функции сканирования
+	from    string               // FROM вместе с JOIN
+	id      string               // Выражение первичного ключа
+	sorts   map[string]sortField // Белый список полей сортировки
+}
+
+// sortNames возвращает имена полей сортировки в алфавитном порядке (для сообщений об ошибке).
+func (s listSpec) sortNames() []string {
+	names := make([]string, 0, len(s.sorts))
+	for name := range s.sorts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// listQuery накапливает условия WHERE. Значения всегда передаются параметрами,
+// в текст запроса попадают только выражения из кода репозитория.
+type listQuery struct {
+	conds []string
+	args  []interface{}
+}
+
+// arg добавляет параметр запроса и возвращает его плейсхолдер ($1, $2, ...).
+func (q *listQuery) arg(v interface{}) string {
+	q.args = append(q.args, v)
+	return "$" + strconv.Itoa(len(q.args))
+}
+
+// where добавляет условие; вместо %s в cond подставляются плейсхолдеры значений args.
+func (q *listQuery) where(cond string, args ...interface{}) {
+	placeholders := make([]interface{}, len(args))
+	for i, v := range args {
+		placeholders[i] = q.arg(v)
+	}
+	q.conds = append(q.conds, fmt.Sprintf(cond, placeholders...))
+}
+
+// sql возвращает условие WHERE целиком.
+func (q *listQuery) sql() string {
+	if len(q.conds) == 0 {
+		return "TRUE"
+	}
+	return strings.Join(q.conds, " AND ")
+}
+
+// containsPattern превращает подстроку поиска в шаблон ILIKE, экранируя служебные символы.
+func containsPattern(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+	return "%" + s + "%"
+}
+
+// sortKeyScanner дочитывает из строки ключ сортировки — последний столбец постраничной выборки.
+type sortKeyScanner struct {
+	row rowScanner
+	key *string
+}
+
+func (s sortKeyScanner) Scan(dest ...interface{}) error {
+	return s.row.Scan(append(dest, s.key)...)
+}
+
+// fetch выполняет постраничную выборку по условиям q. Каждая строка передается в scan,
+// который сохраняет запись и возвращает ее ID. Общее число записей считается без учета курсора.
+func (s listSpec) fetch(ctx context.Context, db *sql.DB, q *listQuery, page Page, scan func(row rowScanner) (int, error)) (*PageInfo, error) {
+	field, ok := s.sorts[page.Sort]
+	if !ok {
+		return nil, fmt.Errorf("repo: неизвестное поле сортировки '%s'", page.Sort)
+	}
+
+	info := &PageInfo{}
+	countQuery := `SELECT COUNT(*) FROM ` + s.from + ` WHERE ` + q.sql()
+	if err := db.QueryRowContext(ctx, countQuery, q.args...).Scan(&info.Total); err != nil {
+		return nil, fmt.Errorf("repo: ошибка подсчета записей: %w", err)
+	}
+
+	direction, after := "ASC", ">"
+	if page.Desc {
+		direction, after = "DESC", "<"
+	}
+	if c := page.After; c != nil {
+		q.where("("+field.expr+", "+s.id+") "+after+" (%s::"+field.cast+", %s)", c.Key, c.ID)
+	}
+	// Лишняя строка сверх лимита показывает, что есть следующая страница
+	query := `SELECT ` + s.columns + `, (` + field.expr + `)::TEXT FROM ` + s.from +
+		` WHERE ` + q.sql() +
+		` ORDER BY ` + field.expr + ` ` + direction + `, ` + s.id + ` ` + direction +
+		` LIMIT ` + q.arg(page.Limit+1)
+
+	rows, err := db.QueryContext(ctx, query, q.args...)
+	if err != nil {
+		return nil, fmt.Errorf("repo: ошибка при получении страницы списка: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		key    string
+		lastID int
+		n      int
+	)
+	for rows.Next() {
+		if n == page.Limit {
+			info.Next = &Cursor{Sort: page.Sort, Desc: page.Desc, Key: key, ID: lastID}
+			break
+		}
+		id, err := scan(sortKeyScanner{row: rows, key: &key})
+		if err != nil {
+			return nil, fmt.Errorf("repo: ошибка сканирования строки: %w", err)
+		}
+		lastID = id
+		n++
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repo: ошибка итерации строк: %w", err)
+	}
+	return info, nil
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// LoginFailure — счетчик неудачных входов по учетной записи или IP-адресу.
+type LoginFailure struct {
+	Key           string     `json:"key"` // 'user:<логин>' или 'ip:<адрес>'
+	Failures      int        `json:"failures"`
+	LastFailureAt time.Time  `json:"last_failure_at"`
+	LockedUntil   *time.Time `json:"locked_until,omitempty"`
+}
+
+// LoginAttemptRepository хранит счетчики неудачных входов и временные блокировки.
+type LoginAttemptRepository struct {
+	db *sql.DB
+}
+
+// NewLoginAttemptRepository создает новый экземпляр репозитория попыток входа.
+func NewLoginAttemptRepository(db *sql.DB) *LoginAttemptRepository {
+	return &LoginAttemptRepository{db: db}
+}
+
+// LockedUntil возвращает время окончания действующей блокировки ключа или nil.
+func (r *LoginAttemptRepository) LockedUntil(ctx context.Context, key string) (*time.Time, error) {
+	var until sql.NullTime
+	err := r.db.QueryRowContext(ctx,
+		"SELECT locked_until FROM login_failures WHERE key = $1 AND locked_until > NOW()", key,
+	).Scan(&until)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("repo: ошибка проверки блокировки: %w", err)
+	}
+	return &until.Time, nil
+}
+
+// RecordFailure увеличивает счетчик неудач и возвращает его новое значение.
+// Если с последней неудачи прошло больше window, счет начинается заново.
+func (r *LoginAttemptRepository) RecordFailure(ctx context.Context, key string, window time.Duration) (int, error) {
+	var failures int
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO login_failures (key, failures, last_failure_at)
+		VALUES ($1, 1, NOW())
+		ON CONFLICT (key) DO UPDATE
+		SET failures = CASE
+		        WHEN login_failures.last_failure_at < NOW() - make_interval(secs => $2) THEN 1
+		        ELSE login_failures.failures + 1
+		    END,
+		    last_failure_at = NOW()
+		RETURNING failures`,
+		key, window.Seconds(),
+	).Scan(&failures)
+
+	if err != nil {
+		return 0, fmt.Errorf("repo: не удалось учесть неудачный вход: %w", err)
+	}
+	return failures, nil
+}
+
+// Lock блокирует вход по ключу до указанного времени.
+func (r *LoginAttemptRepository) Lock(ctx context.Context, key string, until time.Time) error {
+	if _, err := r.db.ExecContext(ctx,
+		"UPDATE login_failures SET locked_until = $2 WHERE key = $1", key, until,
+	); err != nil {
+		return fmt.Errorf("repo: не удалось заблокировать вход: %w", err)
+	}
+	return nil
+}
+
+// Reset сбрасывает счетчик и блокировку ключа. Возвращает false, если сбрасывать было нечего.
+func (r *LoginAttemptRepository) Reset(ctx context.Context, key string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM login_failures WHERE key = $1", key)
+	if err != nil {
+		return false, fmt.Errorf("repo: не удалось сбросить счетчик входов: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected > 0, nil
+}
+
+// ListLocked возвращает все действующие блокировки.
+func (r *LoginAttemptRepository) ListLocked(ctx context.Context) ([]LoginFailure, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT key, failures, last_failure_at, locked_until
+		FROM login_failures
+		WHERE locked_until > NOW()
+		ORDER BY locked_until DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("repo: ошибка при получении блокировок: %w", err)
+	}
+	defer rows.Close()
+
+	locks := make([]LoginFailure, 0)
+	for rows.Next() {
+		var lf LoginFailure
+		if err := rows.Scan(&lf.Key, &lf.Failures, &lf.LastFailureAt, &lf.LockedUntil); err != nil {
+			return nil, fmt.Errorf("repo: ошибка сканирования блокировки: %w", err)
+		}
+		locks = append(locks, lf)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repo: ошибка итерации строк: %w", err)
+	}
+	return locks, nil
+}
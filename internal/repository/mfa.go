@@ -0,0 +1,255 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// TOTPState — состояние двухфакторной аутентификации пользователя.
+type TOTPState struct {
+	Secret   string
+	Enabled  bool
+	LastStep int64
+}
+
+// MFAChallenge — незавершенный вход, ожидающий кода второго фактора.
+type MFAChallenge struct {
+	ID        int
+	UserID    int
+	IP        string
+	Attempts  int
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+}
+
+// MFARepository хранит секреты TOTP, резервные коды и незавершенные входы.
+type MFARepository struct {
+	db *sql.DB
+}
+
+// NewMFARepository создает новый экземпляр репозитория двухфакторной аутентификации.
+func NewMFARepository(db *sql.DB) *MFARepository {
+	return &MFARepository{db: db}
+}
+
+// --- TOTP ---
+
+// GetTOTP возвращает состояние TOTP пользователя.
+func (r *MFARepository) GetTOTP(ctx context.Context, userID int) (*TOTPState, error) {
+	var st TOTPState
+	err := r.db.QueryRowContext(ctx,
+		"SELECT COALESCE(totp_secret, ''), totp_enabled, totp_last_step FROM users WHERE id = $1", userID,
+	).Scan(&st.Secret, &st.Enabled, &st.LastStep)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, NotFound("пользователь с ID %d не найден", userID)
+		}
+		return nil, fmt.Errorf("repo: ошибка при получении настроек 2FA: %w", err)
+	}
+	return &st, nil
+}
+
+// SetPendingSecret сохраняет новый секрет, который начнет действовать после подтверждения.
+// Уже подключенную 2FA так заменить нельзя.
+func (r *MFARepository) SetPendingSecret(ctx context.Context, userID int, secret string) error {
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE users SET totp_secret = $2, totp_last_step = 0 WHERE id = $1 AND NOT totp_enabled",
+		userID, secret,
+	)
+	if err != nil {
+		return fmt.Errorf("repo: не удалось сохранить секрет 2FA: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return Conflict("двухфакторная аутентификация уже подключена")
+	}
+	return nil
+}
+
+// Enable включает 2FA и заменяет резервные коды в одной транзакции.
+func (r *MFARepository) Enable(ctx context.Context, userID int, step int64, codeHashes []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("repo: не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		"UPDATE users SET totp_enabled = TRUE, totp_last_step = $2 WHERE id = $1", userID, step,
+	); err != nil {
+		return fmt.Errorf("repo: не удалось включить 2FA: %w", err)
+	}
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("repo: ошибка фиксации транзакции: %w", err)
+	}
+	return nil
+}
+
+// Disable отключает 2FA, удаляя секрет и резервные коды.
+func (r *MFARepository) Disable(ctx context.Context, userID int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("repo: не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		"UPDATE users SET totp_secret = NULL, totp_enabled = FALSE, totp_last_step = 0 WHERE id = $1", userID,
+	); err != nil {
+		return fmt.Errorf("repo: не удалось отключить 2FA: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM recovery_codes WHERE user_id = $1", userID); err != nil {
+		return fmt.Errorf("repo: не удалось удалить резервные коды: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("repo: ошибка фиксации транзакции: %w", err)
+	}
+	return nil
+}
+
+// AcceptStep фиксирует использованный шаг TOTP. Возвращает false, если этот
+// или более поздний шаг уже принимался (повтор перехваченного кода).
+func (r *MFARepository) AcceptStep(ctx context.Context, userID int, step int64) (bool, error) {
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE users SET totp_last_step = $2 WHERE id = $1 AND totp_last_step < $2", userID, step,
+	)
+	if err != nil {
+		return false, fmt.Errorf("repo: не удалось сохранить шаг TOTP: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected == 1, nil
+}
+
+// --- РЕЗЕРВНЫЕ КОДЫ ---
+
+// ReplaceRecoveryCodes заменяет все резервные коды пользователя новыми.
+func (r *MFARepository) ReplaceRecoveryCodes(ctx context.Context, userID int, codeHashes []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("repo: не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("repo: ошибка фиксации транзакции: %w", err)
+	}
+	return nil
+}
+
+// UseRecoveryCode погашает резервный код. Возвращает false, если код неверен или уже использован.
+func (r *MFARepository) UseRecoveryCode(ctx context.Context, userID int, codeHash string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE recovery_codes SET used_at = NOW()
+		WHERE id = (
+			SELECT id FROM recovery_codes
+			WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+			LIMIT 1
+		)`,
+		userID, codeHash,
+	)
+	if err != nil {
+		return false, fmt.Errorf("repo: ошибка при проверке резервного кода: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected == 1, nil
+}
+
+// CountRecoveryCodes возвращает число неиспользованных резервных кодов.
+func (r *MFARepository) CountRecoveryCodes(ctx context.Context, userID int) (int, error) {
+	var n int
+	err := r.db.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM recovery_codes WHERE user_id = $1 AND used_at IS NULL", userID,
+	).Scan(&n)
+	if err != nil {
+		return 0, fmt.Errorf("repo: ошибка подсчета резервных кодов: %w", err)
+	}
+	return n, nil
+}
+
+// replaceRecoveryCodes удаляет старые коды и сохраняет новые в рамках транзакции.
+func replaceRecoveryCodes(ctx context.Context, tx *sql.Tx, userID int, codeHashes []string) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM recovery_codes WHERE user_id = $1", userID); err != nil {
+		return fmt.Errorf("repo: не удалось удалить резервные коды: %w", err)
+	}
+	for _, hash := range codeHashes {
+		if _, err := tx.ExecContext(ctx,
+			"INSERT INTO recovery_codes (user_id, code_hash) VALUES ($1, $2)", userID, hash,
+		); err != nil {
+			return fmt.Errorf("repo: не удалось сохранить резервный код: %w", err)
+		}
+	}
+	return nil
+}
+
+// --- НЕЗАВЕРШЕННЫЕ ВХОДЫ ---
+
+// CreateChallenge сохраняет незавершенный вход.
+func (r *MFARepository) CreateChallenge(ctx context.Context, userID int, tokenHash, ip string, expiresAt time.Time) error {
+	if _, err := r.db.ExecContext(ctx, `
+		INSERT INTO mfa_challenges (user_id, token_hash, ip, expires_at)
+		VALUES ($1, $2, NULLIF($3, ''), $4)`,
+		userID, tokenHash, ip, expiresAt,
+	); err != nil {
+		return fmt.Errorf("repo: не удалось сохранить вход с 2FA: %w", err)
+	}
+	return nil
+}
+
+// GetChallenge ищет незавершенный вход по хешу токена.
+func (r *MFARepository) GetChallenge(ctx context.Context, tokenHash string) (*MFAChallenge, error) {
+	var c MFAChallenge
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, user_id, COALESCE(ip, ''), attempts, expires_at, used_at
+		FROM mfa_challenges
+		WHERE token_hash = $1`,
+		tokenHash,
+	).Scan(&c.ID, &c.UserID, &c.IP, &c.Attempts, &c.ExpiresAt, &c.UsedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, NotFound("вход с 2FA не найден")
+		}
+		return nil, fmt.Errorf("repo: ошибка при поиске входа с 2FA: %w", err)
+	}
+	return &c, nil
+}
+
+// FailChallenge увеличивает счетчик неверных кодов и возвращает его новое значение.
+func (r *MFARepository) FailChallenge(ctx context.Context, id int) (int, error) {
+	var attempts int
+	err := r.db.QueryRowContext(ctx,
+		"UPDATE mfa_challenges SET attempts = attempts + 1 WHERE id = $1 RETURNING attempts", id,
+	).Scan(&attempts)
+	if err != nil {
+		return 0, fmt.Errorf("repo: не удалось обновить вход с 2FA: %w", err)
+	}
+	return attempts, nil
+}
+
+// ConsumeChallenge завершает вход. Возвращает false, если он уже был завершен.
+func (r *MFARepository) ConsumeChallenge(ctx context.Context, id int) (bool, error) {
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE mfa_challenges SET used_at = NOW() WHERE id = $1 AND used_at IS NULL", id,
+	)
+	if err != nil {
+		return false, fmt.Errorf("repo: не удалось завершить вход с 2FA: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected == 1, nil
+}
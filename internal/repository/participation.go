@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 )
 
 // Participation представляет собой связующую сущность (Many-to-Many)
@@ -14,6 +15,21 @@ type Participation struct {
 	CompetitionID int `json:"competition_id"`
 	Place         int `json:"place"` // 0 означает, что соревнование еще не завершено
 
+	// Заявочный результат: используется для проверки допуска и посева по забегам
+	Event        string   `json:"event"`
+	SeedResult   *float64 `json:"seed_result,omitempty"`
+	SeedVerified bool     `json:"seed_verified"` // false — результат не подтвержден данными системы
+
+	// Категория (возрастная группа, весовая категория); пустая — используется дисциплина
+	Category string `json:"category"`
+
+	// Весовая категория (0 — не используется) и итог взвешивания
+	WeightClassID int    `json:"weight_class_id"`
+	WeighInStatus string `json:"weigh_in_status"` // 'pending', 'reweigh', 'passed', 'moved', 'disqualified'
+
+	// DeletedAt — время мягкого удаления записи или (если раньше) спортсмена либо соревнования
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+
 	// Поля, заполняемые через JOIN для удобства отображения на фронтенде
 	AthleteName     string `json:"athlete_name"`
 	CompetitionName string `json:"competition_name"`
@@ -33,54 +49,175 @@ func NewParticipationRepository(db *sql.DB) *ParticipationRepository {
 
 // Create регистрирует атлета на соревнование.
 func (r *ParticipationRepository) Create(ctx context.Context, p *Participation) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("repo: не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback()
+
 	query := `
-		INSERT INTO participations (athlete_id, competition_id, place)
-		VALUES ($1, $2, $3)
-		RETURNING id`
+		INSERT INTO participations
+			(athlete_id, competition_id, place, event, seed_result, seed_verified, category, weight_class_id)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, NULLIF($7, ''), NULLIF($8, 0))
+		RETURNING id, weigh_in_status`
 
-	err := r.db.QueryRowContext(ctx, query,
+	err = tx.QueryRowContext(ctx, query,
 		p.AthleteID,
 		p.CompetitionID,
 		p.Place,
-	).Scan(&p.ID)
+		p.Event,
+		p.SeedResult,
+		p.SeedVerified,
+		p.Category,
+		p.WeightClassID,
+	).Scan(&p.ID, &p.WeighInStatus)
 
 	if err != nil {
-		return fmt.Errorf("repo: не удалось создать запись об участии: %w", err)
+		return dbError("не удалось создать запись об участии", err)
+	}
+
+	if err := recordChange(ctx, tx, "participation", "participations", p.ID, "create", nil); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("repo: ошибка фиксации транзакции: %w", err)
 	}
 	return nil
 }
 
-// ListAll извлекает все записи об участии, объединяя данные из таблиц athletes и competitions.
-func (r *ParticipationRepository) ListAll(ctx context.Context) ([]Participation, error) {
-	// Используем JOIN, чтобы сразу получить читаемые названия вместо простых ID
+// participationColumns — столбцы выборок участий (в порядке scanParticipation), включая имена из JOIN.
+const participationColumns = `
+	p.id, p.athlete_id, p.competition_id, p.place,
+	COALESCE(p.event, ''), p.seed_result, p.seed_verified, COALESCE(p.category, ''),
+	COALESCE(p.weight_class_id, 0), p.weigh_in_status,
+	COALESCE(p.deleted_at, a.deleted_at, c.deleted_at),
+	a.full_name AS athlete_name,
+	c.name      AS competition_name`
+
+// participationSource — участия вместе со спортсменами и соревнованиями: JOIN сразу дает читаемые названия вместо ID.
+const participationSource = `participations p
+	JOIN athletes a ON a.id = p.athlete_id
+	JOIN competitions c ON c.id = p.competition_id`
+
+// ParticipationFilter — условия выборки списка участий. Пустые поля не ограничивают выборку.
+type ParticipationFilter struct {
+	CompetitionID int
+	AthleteID     int
+	Event         string
+	// IncludeDeleted — показывать и участия, удаленные сами или вместе со спортсменом либо соревнованием
+	IncludeDeleted bool
+}
+
+// participationList — постраничный список участий и поля, по которым его можно сортировать.
+var participationList = listSpec{
+	columns: participationColumns,
+	from:    participationSource,
+	id:      "p.id",
+	sorts: map[string]sortField{
+		"id":               {expr: "p.id", cast: "INT"},
+		"athlete_name":     {expr: "a.full_name", cast: "TEXT"},
+		"competition_name": {expr: "c.name", cast: "TEXT"},
+		"place":            {expr: "COALESCE(p.place, 2147483647)", cast: "INT"},
+	},
+}
+
+// SortFields возвращает поля, по которым можно сортировать список участий.
+func (r *ParticipationRepository) SortFields() []string {
+	return participationList.sortNames()
+}
+
+// List возвращает страницу списка участий по фильтру и общее число подходящих записей.
+func (r *ParticipationRepository) List(ctx context.Context, f ParticipationFilter, page Page) ([]Participation, *PageInfo, error) {
+	q := &listQuery{}
+	if !f.IncludeDeleted {
+		q.where("COALESCE(p.deleted_at, a.deleted_at, c.deleted_at) IS NULL")
+	}
+	if f.CompetitionID > 0 {
+		q.where("p.competition_id = %s", f.CompetitionID)
+	}
+	if f.AthleteID > 0 {
+		q.where("p.athlete_id = %s", f.AthleteID)
+	}
+	if f.Event != "" {
+		q.where("p.event = %s", f.Event)
+	}
+
+	participations := make([]Participation, 0)
+	info, err := participationList.fetch(ctx, r.db, q, page, func(row rowScanner) (int, error) {
+		p, err := scanParticipation(row)
+		if err != nil {
+			return 0, err
+		}
+		participations = append(participations, *p)
+		return p.ID, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return participations, info, nil
+}
+
+// ListByCompetition возвращает допущенных участников соревнования в дисциплине (пустой event — все дисциплины).
+// Сортировка выполняется по заявочному результату; участники без заявки идут в конце.
+func (r *ParticipationRepository) ListByCompetition(ctx context.Context, competitionID int, event string, lowerIsBetter bool) ([]Participation, error) {
 	query := `
-		SELECT 
-			p.id, p.athlete_id, p.competition_id, p.place,
-			a.full_name AS athlete_name,
-			c.name      AS competition_name
-		FROM participations p
-		JOIN athletes a ON a.id = p.athlete_id
-		JOIN competitions c ON c.id = p.competition_id
-		ORDER BY p.id ASC`
-
-	rows, err := r.db.QueryContext(ctx, query)
+		SELECT ` + participationColumns + `
+		FROM ` + participationSource + `
+		WHERE p.competition_id = $1 AND ($2 = '' OR p.event = $2)
+		  AND COALESCE(p.deleted_at, a.deleted_at, c.deleted_at) IS NULL
+		  -- В весовых категориях участвуют только прошедшие взвешивание
+		  AND (p.weight_class_id IS NULL OR p.weigh_in_status IN ('passed', 'moved'))
+		ORDER BY
+			CASE WHEN $3 THEN p.seed_result END ASC NULLS LAST,
+			CASE WHEN NOT $3 THEN p.seed_result END DESC NULLS LAST,
+			p.id ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, competitionID, event, lowerIsBetter)
 	if err != nil {
-		return nil, fmt.Errorf("repo: ошибка при выполнении JOIN-запроса: %w", err)
+		return nil, fmt.Errorf("repo: ошибка при получении участников соревнования: %w", err)
 	}
 	defer rows.Close()
 
+	return scanParticipations(rows)
+}
+
+// GetByID возвращает (не удаленную) запись об участии по ID.
+func (r *ParticipationRepository) GetByID(ctx context.Context, id int) (*Participation, error) {
+	return r.Find(ctx, id, false)
+}
+
+// Find возвращает запись об участии по ID; при includeDeleted — и удаленную.
+func (r *ParticipationRepository) Find(ctx context.Context, id int, includeDeleted bool) (*Participation, error) {
+	query := `
+		SELECT ` + participationColumns + `
+		FROM ` + participationSource + `
+		WHERE p.id = $1 AND ($2 OR COALESCE(p.deleted_at, a.deleted_at, c.deleted_at) IS NULL)`
+
+	rows, err := r.db.QueryContext(ctx, query, id, includeDeleted)
+	if err != nil {
+		return nil, fmt.Errorf("repo: ошибка при поиске записи об участии: %w", err)
+	}
+	defer rows.Close()
+
+	participations, err := scanParticipations(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(participations) == 0 {
+		return nil, NotFound("запись об участии с ID %d не найдена", id)
+	}
+	return &participations[0], nil
+}
+
+// scanParticipations читает строки выборки участий (столбцы participationColumns) в срез.
+func scanParticipations(rows *sql.Rows) ([]Participation, error) {
 	participations := make([]Participation, 0)
 	for rows.Next() {
-		var p Participation
-		// Сканируем все поля, включая полученные через JOIN
-		err := rows.Scan(
-			&p.ID, &p.AthleteID, &p.CompetitionID, &p.Place,
-			&p.AthleteName, &p.CompetitionName,
-		)
+		p, err := scanParticipation(rows)
 		if err != nil {
 			return nil, fmt.Errorf("repo: ошибка сканирования строки участия: %w", err)
 		}
-		participations = append(participations, p)
+		participations = append(participations, *p)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -90,33 +227,77 @@ func (r *ParticipationRepository) ListAll(ctx context.Context) ([]Participation,
 	return participations, nil
 }
 
-// UpdatePlace обновляет только результат (место) атлета в соревновании.
-func (r *ParticipationRepository) UpdatePlace(ctx context.Context, id int, place int) error {
-	result, err := r.db.ExecContext(ctx,
-		"UPDATE participations SET place = $2 WHERE id = $1",
-		id, place,
+// scanParticipation читает одну строку участия в порядке participationColumns.
+func scanParticipation(row rowScanner) (*Participation, error) {
+	var (
+		p         Participation
+		seed      sql.NullFloat64
+		deletedAt sql.NullTime
+	)
+	// Сканируем все поля, включая полученные через JOIN
+	err := row.Scan(
+		&p.ID, &p.AthleteID, &p.CompetitionID, &p.Place,
+		&p.Event, &seed, &p.SeedVerified, &p.Category,
+		&p.WeightClassID, &p.WeighInStatus, &deletedAt,
+		&p.AthleteName, &p.CompetitionName,
 	)
 	if err != nil {
-		return fmt.Errorf("repo: не удалось обновить результат: %w", err)
+		return nil, err
 	}
-
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		return fmt.Errorf("запись об участии с ID %d не найдена", id)
+	if seed.Valid {
+		p.SeedResult = &seed.Float64
 	}
-	return nil
+	if deletedAt.Valid {
+		p.DeletedAt = &deletedAt.Time
+	}
+	return &p, nil
 }
 
-// Delete удаляет запись об участии (аннулирует регистрацию атлета).
-func (r *ParticipationRepository) Delete(ctx context.Context, id int) error {
-	result, err := r.db.ExecContext(ctx, "DELETE FROM participations WHERE id = $1", id)
+// UpdatePlace обновляет только результат (место) атлета в соревновании.
+func (r *ParticipationRepository) UpdatePlace(ctx context.Context, id int, place int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("repo: ошибка при удалении записи: %w", err)
+		return fmt.Errorf("repo: не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback()
+
+	before, err := snapshot(ctx, tx, "participations", "id", id)
+	if err != nil {
+		return err
+	}
+	if before == nil || isDeleted(before) {
+		return NotFound("запись об участии с ID %d не найдена", id)
 	}
 
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		return fmt.Errorf("запись об участии с ID %d не найдена", id)
+	if _, err := tx.ExecContext(ctx, "UPDATE participations SET place = $2 WHERE id = $1", id, place); err != nil {
+		return dbError("не удалось обновить результат", err)
+	}
+
+	if err := recordChange(ctx, tx, "participation", "participations", id, "update_place", before); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("repo: ошибка фиксации транзакции: %w", err)
 	}
 	return nil
 }
+
+// Delete мягко удаляет запись об участии (аннулирует регистрацию атлета); результат сохраняется.
+func (r *ParticipationRepository) Delete(ctx context.Context, id int) error {
+	return setDeleted(ctx, r.db, "participation", "participations", id, true, AnyVersion)
+}
+
+// Restore восстанавливает мягко удаленную запись об участии.
+func (r *ParticipationRepository) Restore(ctx context.Context, id int) error {
+	return setDeleted(ctx, r.db, "participation", "participations", id, false, AnyVersion)
+}
+
+// Purge безвозвратно удаляет мягко удаленную запись об участии вместе с результатом.
+func (r *ParticipationRepository) Purge(ctx context.Context, id int) error {
+	return purge(ctx, r.db, "participation", "participations", "", id, time.Time{})
+}
+
+// ListDeletedBefore возвращает ID участий, удаленных раньше cutoff (кандидаты на очистку).
+func (r *ParticipationRepository) ListDeletedBefore(ctx context.Context, cutoff time.Time) ([]int, error) {
+	return listDeletedBefore(ctx, r.db, "participations", cutoff)
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// AthletePatch — частичное изменение спортсмена. nil означает «поле не меняется».
+type AthletePatch struct {
+	FullName  *string
+	BirthDate *time.Time
+	Gender    *string
+	IsActive  *bool
+	Address   *string
+	ClubID    *int // 0 — исключить из клуба
+}
+
+// CompetitionPatch — частичное изменение соревнования. nil означает «поле не меняется».
+type CompetitionPatch struct {
+	Name      *string
+	Location  *string
+	StartDate *time.Time
+	Level     *string // "" — уровень не указан
+	SportID   *int    // 0 — вид спорта не указан
+}
+
+// ResultPatch — частичное изменение результата. nil означает «поле не меняется».
+type ResultPatch struct {
+	Event *string  // "" — дисциплина не указана
+	Place *int     // 0 — место не определено
+	Score *float64 // Время, очки, метры и т.д.
+	Notes *string
+}
+
+// columnSet собирает SET-часть UPDATE только из действительно изменившихся столбцов.
+// Параметры нумеруются с $2: $1 занят ID строки.
+type columnSet struct {
+	assignments []string
+	args        []interface{}
+}
+
+// add добавляет присваивание; expr — выражение с %s на месте параметра (например, "NULLIF(%s, 0)").
+func (s *columnSet) add(column, expr string, value interface{}) {
+	s.args = append(s.args, value)
+	placeholder := fmt.Sprintf("$%d", len(s.args)+1)
+	s.assignments = append(s.assignments, column+" = "+fmt.Sprintf(expr, placeholder))
+}
+
+// empty сообщает, что изменять нечего.
+func (s *columnSet) empty() bool {
+	return len(s.assignments) == 0
+}
+
+// sql возвращает список присваиваний для SET.
+func (s *columnSet) sql() string {
+	return strings.Join(s.assignments, ", ")
+}
+
+// sameDay сравнивает значения столбцов типа DATE: время суток в них не хранится.
+func sameDay(a, b time.Time) bool {
+	return a.Format("2006-01-02") == b.Format("2006-01-02")
+}
@@ -0,0 +1,449 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Rank описывает спортивный разряд или звание (КМС, МС, I разряд и т.д.).
+type Rank struct {
+	ID             int    `json:"id"`
+	Name           string `json:"name"`
+	Description    string `json:"description"`
+	Priority       int    `json:"priority"`        // Старшинство: чем больше, тем выше разряд
+	ValidityMonths int    `json:"validity_months"` // 0 означает бессрочный разряд
+}
+
+// RankStandard описывает разрядный норматив для вида спорта, дисциплины и пола.
+// Норматив считается выполненным, если соблюдены все заданные условия:
+// результат не хуже порога и/или место не ниже MaxPlace на соревновании нужного уровня.
+type RankStandard struct {
+	ID               int      `json:"id"`
+	SportID          int      `json:"sport_id"`
+	Event            string   `json:"event"`
+	Gender           string   `json:"gender"`
+	RankID           int      `json:"rank_id"`
+	ResultThreshold  *float64 `json:"result_threshold,omitempty"`
+	LowerIsBetter    bool     `json:"lower_is_better"`
+	MaxPlace         *int     `json:"max_place,omitempty"`
+	CompetitionLevel string   `json:"competition_level"` // Пустая строка — любой уровень
+
+	// Поле, заполняемое через JOIN
+	RankName string `json:"rank_name"`
+}
+
+// RankProposal — предложение о присвоении разряда, сформированное по результату.
+type RankProposal struct {
+	ID         int        `json:"id"`
+	AthleteID  int        `json:"athlete_id"`
+	RankID     int        `json:"rank_id"`
+	StandardID int        `json:"standard_id"`
+	ResultID   int        `json:"result_id"`
+	Status     string     `json:"status"` // 'pending', 'approved', 'rejected'
+	CreatedAt  time.Time  `json:"created_at"`
+	DecidedBy  string     `json:"decided_by,omitempty"`
+	DecidedAt  *time.Time `json:"decided_at,omitempty"`
+
+	// Поля, заполняемые через JOIN для удобства отображения на фронтенде
+	AthleteName string `json:"athlete_name"`
+	RankName    string `json:"rank_name"`
+}
+
+// AthleteRank — запись в истории разрядов спортсмена.
+type AthleteRank struct {
+	ID         int        `json:"id"`
+	AthleteID  int        `json:"athlete_id"`
+	RankID     int        `json:"rank_id"`
+	RankName   string     `json:"rank_name"`
+	ProposalID int        `json:"proposal_id"`
+	ResultID   int        `json:"result_id"`
+	AwardedAt  time.Time  `json:"awarded_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	ApprovedBy string     `json:"approved_by"`
+}
+
+// RankRepository управляет разрядами, нормативами и историей их присвоения.
+type RankRepository struct {
+	db *sql.DB
+}
+
+// NewRankRepository создает новый экземпляр репозитория разрядов.
+func NewRankRepository(db *sql.DB) *RankRepository {
+	return &RankRepository{db: db}
+}
+
+// --- РАЗРЯДЫ ---
+
+// ListRanks возвращает справочник разрядов, начиная со старшего.
+func (r *RankRepository) ListRanks(ctx context.Context) ([]Rank, error) {
+	query := `
+		SELECT id, name, COALESCE(description, ''), priority, COALESCE(validity_months, 0)
+		FROM ranks
+		ORDER BY priority DESC, id ASC`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("repo: ошибка при получении списка разрядов: %w", err)
+	}
+	defer rows.Close()
+
+	ranks := make([]Rank, 0)
+	for rows.Next() {
+		var rk Rank
+		if err := rows.Scan(&rk.ID, &rk.Name, &rk.Description, &rk.Priority, &rk.ValidityMonths); err != nil {
+			return nil, fmt.Errorf("repo: ошибка сканирования разряда: %w", err)
+		}
+		ranks = append(ranks, rk)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repo: ошибка итерации строк: %w", err)
+	}
+	return ranks, nil
+}
+
+// CreateRank добавляет разряд в справочник.
+func (r *RankRepository) CreateRank(ctx context.Context, rk *Rank) error {
+	query := `
+		INSERT INTO ranks (name, description, priority, validity_months)
+		VALUES ($1, NULLIF($2, ''), $3, NULLIF($4, 0))
+		RETURNING id`
+
+	err := r.db.QueryRowContext(ctx, query, rk.Name, rk.Description, rk.Priority, rk.ValidityMonths).Scan(&rk.ID)
+	if err != nil {
+		return dbError("не удалось создать разряд", err)
+	}
+	return nil
+}
+
+// UpdateRank изменяет данные разряда (название, старшинство, срок действия).
+func (r *RankRepository) UpdateRank(ctx context.Context, rk *Rank) error {
+	query := `
+		UPDATE ranks
+		SET name = $2, description = NULLIF($3, ''), priority = $4, validity_months = NULLIF($5, 0)
+		WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, query, rk.ID, rk.Name, rk.Description, rk.Priority, rk.ValidityMonths)
+	if err != nil {
+		return dbError("не удалось обновить разряд", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return NotFound("разряд с ID %d не найден", rk.ID)
+	}
+	return nil
+}
+
+// --- НОРМАТИВЫ ---
+
+// ListStandards возвращает все разрядные нормативы.
+func (r *RankRepository) ListStandards(ctx context.Context) ([]RankStandard, error) {
+	query := `
+		SELECT s.id, s.sport_id, s.event, s.gender, s.rank_id, s.result_threshold,
+		       s.lower_is_better, s.max_place, COALESCE(s.competition_level, ''), rk.name
+		FROM rank_standards s
+		JOIN ranks rk ON rk.id = s.rank_id
+		ORDER BY s.sport_id, s.event, s.gender, rk.priority DESC`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("repo: ошибка при получении нормативов: %w", err)
+	}
+	defer rows.Close()
+
+	standards := make([]RankStandard, 0)
+	for rows.Next() {
+		var (
+			s         RankStandard
+			threshold sql.NullFloat64
+			maxPlace  sql.NullInt64
+		)
+		err := rows.Scan(
+			&s.ID, &s.SportID, &s.Event, &s.Gender, &s.RankID, &threshold,
+			&s.LowerIsBetter, &maxPlace, &s.CompetitionLevel, &s.RankName,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("repo: ошибка сканирования норматива: %w", err)
+		}
+		if threshold.Valid {
+			s.ResultThreshold = &threshold.Float64
+		}
+		if maxPlace.Valid {
+			place := int(maxPlace.Int64)
+			s.MaxPlace = &place
+		}
+		standards = append(standards, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repo: ошибка итерации строк: %w", err)
+	}
+	return standards, nil
+}
+
+// CreateStandard сохраняет новый разрядный норматив.
+func (r *RankRepository) CreateStandard(ctx context.Context, s *RankStandard) error {
+	query := `
+		INSERT INTO rank_standards
+			(sport_id, event, gender, rank_id, result_threshold, lower_is_better, max_place, competition_level)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''))
+		RETURNING id`
+
+	err := r.db.QueryRowContext(ctx, query,
+		s.SportID, s.Event, s.Gender, s.RankID, s.ResultThreshold,
+		s.LowerIsBetter, s.MaxPlace, s.CompetitionLevel,
+	).Scan(&s.ID)
+
+	if err != nil {
+		return dbError("не удалось создать норматив", err)
+	}
+	return nil
+}
+
+// DeleteStandard удаляет норматив. Уже сформированные предложения сохраняются.
+func (r *RankRepository) DeleteStandard(ctx context.Context, id int) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM rank_standards WHERE id = $1", id)
+	if err != nil {
+		return dbError("ошибка при удалении норматива", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return NotFound("норматив с ID %d не найден", id)
+	}
+	return nil
+}
+
+// --- ПРЕДЛОЖЕНИЯ О ПРИСВОЕНИИ ---
+
+// competitionLevels — уровни соревнований по возрастанию: норматив, требующий уровня,
+// выполняется и на соревновании более высокого уровня.
+const competitionLevels = `ARRAY['regional', 'national', 'international']::text[]`
+
+// ProposeFromResult сопоставляет результат с нормативами и создает предложение
+// о присвоении самого старшего из выполненных разрядов.
+// Предложение создается только если разряд выше действующего у спортсмена.
+// Возвращает nil, если результат не дает повышения.
+func (r *RankRepository) ProposeFromResult(ctx context.Context, resultID int) (*RankProposal, error) {
+	query := `
+		INSERT INTO rank_proposals (athlete_id, rank_id, standard_id, result_id)
+		SELECT a.id, rk.id, s.id, res.id
+		FROM results res
+		JOIN participations p ON p.id = res.participation_id
+		JOIN athletes a ON a.id = p.athlete_id
+		JOIN competitions c ON c.id = p.competition_id
+		JOIN rank_standards s ON s.sport_id = c.sport_id AND s.event = res.event AND s.gender = a.gender
+		JOIN ranks rk ON rk.id = s.rank_id
+		WHERE res.id = $1
+		  AND (s.result_threshold IS NULL OR (res.score IS NOT NULL AND CASE
+		        WHEN s.lower_is_better THEN res.score <= s.result_threshold
+		        ELSE res.score >= s.result_threshold END))
+		  AND (s.max_place IS NULL OR (res.place IS NOT NULL AND res.place <= s.max_place))
+		  AND (s.competition_level IS NULL OR s.competition_level = c.level
+		        OR array_position(` + competitionLevels + `, c.level::text) >= array_position(` + competitionLevels + `, s.competition_level::text))
+		  AND rk.priority > COALESCE((
+		        SELECT MAX(cur.priority)
+		        FROM athlete_ranks ar
+		        JOIN ranks cur ON cur.id = ar.rank_id
+		        WHERE ar.athlete_id = a.id
+		          AND (ar.expires_at IS NULL OR ar.expires_at >= CURRENT_DATE)
+		      ), -1)
+		ORDER BY rk.priority DESC, s.id ASC
+		LIMIT 1
+		ON CONFLICT (athlete_id, rank_id, result_id) DO NOTHING
+		RETURNING id, athlete_id, rank_id, standard_id, result_id, status, created_at`
+
+	p := &RankProposal{}
+	err := r.db.QueryRowContext(ctx, query, resultID).Scan(
+		&p.ID, &p.AthleteID, &p.RankID, &p.StandardID, &p.ResultID, &p.Status, &p.CreatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Норматив не выполнен или разряд уже присвоен
+		}
+		return nil, fmt.Errorf("repo: ошибка при подборе норматива: %w", err)
+	}
+	return p, nil
+}
+
+// ListProposals возвращает предложения о присвоении. Пустой status — все предложения.
+func (r *RankRepository) ListProposals(ctx context.Context, status string) ([]RankProposal, error) {
+	query := `
+		SELECT rp.id, rp.athlete_id, rp.rank_id, COALESCE(rp.standard_id, 0), COALESCE(rp.result_id, 0),
+		       rp.status, rp.created_at, COALESCE(rp.decided_by, ''), rp.decided_at,
+		       a.full_name, rk.name
+		FROM rank_proposals rp
+		JOIN athletes a ON a.id = rp.athlete_id
+		JOIN ranks rk ON rk.id = rp.rank_id
+		WHERE $1 = '' OR rp.status = $1
+		ORDER BY rp.created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, status)
+	if err != nil {
+		return nil, fmt.Errorf("repo: ошибка при получении предложений: %w", err)
+	}
+	defer rows.Close()
+
+	proposals := make([]RankProposal, 0)
+	for rows.Next() {
+		var (
+			p         RankProposal
+			decidedAt sql.NullTime
+		)
+		err := rows.Scan(
+			&p.ID, &p.AthleteID, &p.RankID, &p.StandardID, &p.ResultID,
+			&p.Status, &p.CreatedAt, &p.DecidedBy, &decidedAt,
+			&p.AthleteName, &p.RankName,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("repo: ошибка сканирования предложения: %w", err)
+		}
+		if decidedAt.Valid {
+			p.DecidedAt = &decidedAt.Time
+		}
+		proposals = append(proposals, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repo: ошибка итерации строк: %w", err)
+	}
+	return proposals, nil
+}
+
+// ApproveProposal утверждает предложение: записывает разряд в историю спортсмена
+// и обновляет его текущий разряд. Все изменения выполняются в одной транзакции.
+func (r *RankRepository) ApproveProposal(ctx context.Context, id int, approvedBy string) (*AthleteRank, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("repo: не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback() // Безопасно вызывать после Commit
+
+	// 1. Меняем статус предложения (только если оно еще не рассмотрено)
+	ar := &AthleteRank{ProposalID: id, ApprovedBy: approvedBy}
+	var resultID sql.NullInt64
+	err = tx.QueryRowContext(ctx, `
+		UPDATE rank_proposals
+		SET status = 'approved', decided_by = $2, decided_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+		RETURNING athlete_id, rank_id, result_id`,
+		id, approvedBy,
+	).Scan(&ar.AthleteID, &ar.RankID, &resultID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, NotFound("предложение с ID %d не найдено или уже рассмотрено", id)
+		}
+		return nil, fmt.Errorf("repo: ошибка при утверждении предложения: %w", err)
+	}
+	ar.ResultID = int(resultID.Int64)
+
+	// 2. Добавляем запись в историю; срок действия считается от даты присвоения
+	var expiresAt sql.NullTime
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO athlete_ranks (athlete_id, rank_id, proposal_id, result_id, awarded_at, expires_at, approved_by)
+		SELECT $1, rk.id, $3, $4, CURRENT_DATE,
+		       CASE WHEN rk.validity_months IS NULL THEN NULL
+		            ELSE (CURRENT_DATE + make_interval(months => rk.validity_months))::date END,
+		       $5
+		FROM ranks rk WHERE rk.id = $2
+		RETURNING id, awarded_at, expires_at, (SELECT name FROM ranks WHERE id = $2)`,
+		ar.AthleteID, ar.RankID, id, resultID, approvedBy,
+	).Scan(&ar.ID, &ar.AwardedAt, &expiresAt, &ar.RankName)
+	if err != nil {
+		return nil, fmt.Errorf("repo: не удалось записать разряд в историю: %w", err)
+	}
+	if expiresAt.Valid {
+		ar.ExpiresAt = &expiresAt.Time
+	}
+
+	// 3. Обновляем текущий разряд спортсмена, если новый не ниже действующего.
+	// Изменение попадает в журнал аудита и историю версий, как любое изменение карточки спортсмена
+	before, err := snapshot(ctx, tx, "athletes", "id", ar.AthleteID)
+	if err != nil {
+		return nil, err
+	}
+	result, err := tx.ExecContext(ctx, `
+		UPDATE athletes a SET rank_id = $2
+		WHERE a.id = $1 AND a.rank_id IS DISTINCT FROM $2
+		  AND (a.rank_id IS NULL OR (SELECT priority FROM ranks WHERE id = a.rank_id) <= (SELECT priority FROM ranks WHERE id = $2))`,
+		ar.AthleteID, ar.RankID)
+	if err != nil {
+		return nil, fmt.Errorf("repo: не удалось обновить разряд спортсмена: %w", err)
+	}
+	if changed, _ := result.RowsAffected(); changed > 0 {
+		if err := recordChange(ctx, tx, "athlete", "athletes", ar.AthleteID, "update_rank", before); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("repo: ошибка фиксации транзакции: %w", err)
+	}
+	return ar, nil
+}
+
+// RejectProposal отклоняет предложение о присвоении разряда.
+func (r *RankRepository) RejectProposal(ctx context.Context, id int, decidedBy string) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE rank_proposals
+		SET status = 'rejected', decided_by = $2, decided_at = NOW()
+		WHERE id = $1 AND status = 'pending'`,
+		id, decidedBy,
+	)
+	if err != nil {
+		return fmt.Errorf("repo: ошибка при отклонении предложения: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return NotFound("предложение с ID %d не найдено или уже рассмотрено", id)
+	}
+	return nil
+}
+
+// --- ИСТОРИЯ РАЗРЯДОВ ---
+
+// ListAthleteRanks возвращает историю разрядов спортсмена (сначала последние).
+func (r *RankRepository) ListAthleteRanks(ctx context.Context, athleteID int) ([]AthleteRank, error) {
+	query := `
+		SELECT ar.id, ar.athlete_id, ar.rank_id, rk.name, COALESCE(ar.proposal_id, 0), COALESCE(ar.result_id, 0),
+		       ar.awarded_at, ar.expires_at, COALESCE(ar.approved_by, '')
+		FROM athlete_ranks ar
+		JOIN ranks rk ON rk.id = ar.rank_id
+		WHERE ar.athlete_id = $1
+		ORDER BY ar.awarded_at DESC, ar.id DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, athleteID)
+	if err != nil {
+		return nil, fmt.Errorf("repo: ошибка при получении истории разрядов: %w", err)
+	}
+	defer rows.Close()
+
+	history := make([]AthleteRank, 0)
+	for rows.Next() {
+		var (
+			ar        AthleteRank
+			expiresAt sql.NullTime
+		)
+		err := rows.Scan(
+			&ar.ID, &ar.AthleteID, &ar.RankID, &ar.RankName, &ar.ProposalID, &ar.ResultID,
+			&ar.AwardedAt, &expiresAt, &ar.ApprovedBy,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("repo: ошибка сканирования истории: %w", err)
+		}
+		if expiresAt.Valid {
+			ar.ExpiresAt = &expiresAt.Time
+		}
+		history = append(history, ar)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repo: ошибка итерации строк: %w", err)
+	}
+	return history, nil
+}
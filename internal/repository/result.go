@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Result описывает итоговый результат спортсмена в рамках одной записи об участии.
+type Result struct {
+	ID              int      `json:"id"`
+	ParticipationID int      `json:"participation_id"`
+	Event           string   `json:"event"` // Дисциплина, например '100m'
+	Place           int      `json:"place"` // 0 означает, что место не определено
+	Score           *float64 `json:"score"` // Время, очки, метры и т.д.; nil — результат только по месту
+	Notes           string   `json:"notes"`
+	Version         int      `json:"version"` // Увеличивается при каждом изменении (ETag)
+}
+
+// ResultRepository управляет таблицей результатов.
+type ResultRepository struct {
+	db *sql.DB
+}
+
+// NewResultRepository создает новый экземпляр репозитория результатов.
+func NewResultRepository(db *sql.DB) *ResultRepository {
+	return &ResultRepository{db: db}
+}
+
+// --- МЕТОДЫ РАБОТЫ С ДАННЫМИ ---
+
+// Save создает или перезаписывает результат для записи об участии.
+// У каждой записи об участии может быть только один результат (UNIQUE participation_id).
+// expectedVersion — версия перезаписываемого результата, которую видел клиент:
+// 0 — клиент считает, что результата еще нет; AnyVersion — перезаписать без проверки.
+func (r *ResultRepository) Save(ctx context.Context, res *Result, expectedVersion int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("repo: не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Прежний результат (если был) попадает в журнал аудита
+	before, err := snapshot(ctx, tx, "results", "participation_id", res.ParticipationID)
+	if err != nil {
+		return err
+	}
+	switch {
+	case before == nil && expectedVersion > 0:
+		// Клиент ждет конкретную версию, а результата нет (например, его удалили)
+		return ErrVersionConflict
+	case before != nil && expectedVersion == 0:
+		return ErrVersionRequired
+	case before != nil:
+		if err := checkVersion(before, expectedVersion); err != nil {
+			return err
+		}
+	}
+
+	query := `
+		INSERT INTO results (participation_id, event, place, score, notes)
+		VALUES ($1, NULLIF($2, ''), NULLIF($3, 0), $4, NULLIF($5, ''))
+		ON CONFLICT (participation_id) DO UPDATE
+		SET event = EXCLUDED.event, place = EXCLUDED.place, score = EXCLUDED.score, notes = EXCLUDED.notes
+		RETURNING id, version`
+
+	err = tx.QueryRowContext(ctx, query,
+		res.ParticipationID, res.Event, res.Place, res.Score, res.Notes,
+	).Scan(&res.ID, &res.Version)
+
+	if err != nil {
+		return dbError("не удалось сохранить результат", err)
+	}
+
+	action := "update"
+	if before == nil {
+		action = "create"
+	}
+	if err := recordChange(ctx, tx, "result", "results", res.ID, action, before); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("repo: ошибка фиксации транзакции: %w", err)
+	}
+	return nil
+}
+
+// resultColumns — общий список столбцов для выборок результатов.
+const resultColumns = `id, participation_id, COALESCE(event, ''), COALESCE(place, 0), score, COALESCE(notes, ''), version`
+
+// scanResult читает одну строку результата в порядке resultColumns.
+func scanResult(row rowScanner) (*Result, error) {
+	res := &Result{}
+	if err := row.Scan(&res.ID, &res.ParticipationID, &res.Event, &res.Place, &res.Score, &res.Notes, &res.Version); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Patch изменяет только переданные в p поля существующего результата и возвращает его новое состояние.
+// Неизменившиеся столбцы в UPDATE не попадают; если не меняется ничего, версия и журнал аудита остаются прежними.
+func (r *ResultRepository) Patch(ctx context.Context, participationID int, p ResultPatch, expectedVersion int) (*Result, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("repo: не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback()
+
+	before, err := snapshot(ctx, tx, "results", "participation_id", participationID)
+	if err != nil {
+		return nil, err
+	}
+	if before == nil {
+		return nil, NotFound("результат для записи об участии %d не найден", participationID)
+	}
+	if err := checkVersion(before, expectedVersion); err != nil {
+		return nil, err
+	}
+
+	current, err := scanResult(tx.QueryRowContext(ctx, `SELECT `+resultColumns+` FROM results WHERE participation_id = $1`, participationID))
+	if err != nil {
+		return nil, fmt.Errorf("repo: ошибка при чтении результата: %w", err)
+	}
+
+	var set columnSet
+	if p.Event != nil && *p.Event != current.Event {
+		set.add("event", "NULLIF(%s, '')", *p.Event)
+	}
+	if p.Place != nil && *p.Place != current.Place {
+		set.add("place", "NULLIF(%s, 0)", *p.Place)
+	}
+	if p.Score != nil && (current.Score == nil || *p.Score != *current.Score) {
+		set.add("score", "%s", *p.Score)
+	}
+	if p.Notes != nil && *p.Notes != current.Notes {
+		set.add("notes", "NULLIF(%s, '')", *p.Notes)
+	}
+	if set.empty() {
+		return current, nil
+	}
+
+	query := `UPDATE results SET ` + set.sql() + ` WHERE id = $1 RETURNING ` + resultColumns
+	updated, err := scanResult(tx.QueryRowContext(ctx, query, append([]interface{}{current.ID}, set.args...)...))
+	if err != nil {
+		return nil, dbError("не удалось обновить результат", err)
+	}
+
+	if err := recordChange(ctx, tx, "result", "results", current.ID, "update", before); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("repo: ошибка фиксации транзакции: %w", err)
+	}
+	return updated, nil
+}
+
+// GetByParticipationID возвращает результат по ID записи об участии.
+func (r *ResultRepository) GetByParticipationID(ctx context.Context, participationID int) (*Result, error) {
+	query := `SELECT ` + resultColumns + ` FROM results WHERE participation_id = $1`
+
+	res, err := scanResult(r.db.QueryRowContext(ctx, query, participationID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, NotFound("результат для записи об участии %d не найден", participationID)
+		}
+		return nil, fmt.Errorf("repo: ошибка при получении результата: %w", err)
+	}
+	return res, nil
+}
+
+// BestResult возвращает лучший результат спортсмена в дисциплине,
+// показанный на соревнованиях, начавшихся не ранее since. Возвращает nil, если результатов нет.
+// Результаты без значения или с нулевым значением (только место) не учитываются.
+func (r *ResultRepository) BestResult(ctx context.Context, athleteID int, event string, since time.Time, lowerIsBetter bool) (*float64, error) {
+	// Направление агрегата зависит от дисциплины: время — меньше лучше, очки/метры — больше лучше
+	query := `
+		SELECT CASE WHEN $4 THEN MIN(res.score) ELSE MAX(res.score) END
+		FROM results res
+		JOIN participations p ON p.id = res.participation_id
+		JOIN competitions c ON c.id = p.competition_id
+		WHERE p.athlete_id = $1 AND res.event = $2 AND c.start_date >= $3 AND res.score IS NOT NULL AND res.score > 0
+		  AND p.deleted_at IS NULL AND c.deleted_at IS NULL`
+
+	var best sql.NullFloat64
+	if err := r.db.QueryRowContext(ctx, query, athleteID, event, since, lowerIsBetter).Scan(&best); err != nil {
+		return nil, fmt.Errorf("repo: ошибка при поиске лучшего результата: %w", err)
+	}
+	if !best.Valid {
+		return nil, nil
+	}
+	return &best.Float64, nil
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// RoleAssignment — роль, выданная пользователю в пределах области действия.
+// ScopeType: ” — глобально, 'competition' или 'club'; ScopeID — ID соревнования или клуба.
+type RoleAssignment struct {
+	ID        int       `json:"id"`
+	UserID    int       `json:"user_id"`
+	Role      string    `json:"role"`
+	ScopeType string    `json:"scope_type"`
+	ScopeID   int       `json:"scope_id"`
+	GrantedBy string    `json:"granted_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// RoleRepository управляет таблицей user_roles.
+type RoleRepository struct {
+	db *sql.DB
+}
+
+// NewRoleRepository создает новый экземпляр репозитория ролей.
+func NewRoleRepository(db *sql.DB) *RoleRepository {
+	return &RoleRepository{db: db}
+}
+
+// --- МЕТОДЫ ДОСТУПА К ДАННЫМ ---
+
+// ListByUser возвращает все роли пользователя с областями действия.
+func (r *RoleRepository) ListByUser(ctx context.Context, userID int) ([]RoleAssignment, error) {
+	query := `
+		SELECT id, user_id, role, scope_type, scope_id, COALESCE(granted_by, ''), created_at
+		FROM user_roles
+		WHERE user_id = $1
+		ORDER BY scope_type, scope_id, role`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("repo: ошибка при получении ролей пользователя: %w", err)
+	}
+	defer rows.Close()
+
+	roles := make([]RoleAssignment, 0)
+	for rows.Next() {
+		var ra RoleAssignment
+		if err := rows.Scan(&ra.ID, &ra.UserID, &ra.Role, &ra.ScopeType, &ra.ScopeID, &ra.GrantedBy, &ra.CreatedAt); err != nil {
+			return nil, fmt.Errorf("repo: ошибка сканирования роли: %w", err)
+		}
+		roles = append(roles, ra)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repo: ошибка итерации строк: %w", err)
+	}
+	return roles, nil
+}
+
+// GetByID возвращает назначение роли пользователя по ID.
+func (r *RoleRepository) GetByID(ctx context.Context, userID, id int) (*RoleAssignment, error) {
+	query := `
+		SELECT id, user_id, role, scope_type, scope_id, COALESCE(granted_by, ''), created_at
+		FROM user_roles
+		WHERE id = $1 AND user_id = $2`
+
+	var ra RoleAssignment
+	err := r.db.QueryRowContext(ctx, query, id, userID).Scan(
+		&ra.ID, &ra.UserID, &ra.Role, &ra.ScopeType, &ra.ScopeID, &ra.GrantedBy, &ra.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, NotFound("назначение роли с ID %d не найдено", id)
+		}
+		return nil, fmt.Errorf("repo: ошибка при получении роли: %w", err)
+	}
+	return &ra, nil
+}
+
+// Assign выдает роль. Повторная выдача той же роли в той же области ничего не меняет.
+func (r *RoleRepository) Assign(ctx context.Context, ra *RoleAssignment) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("repo: не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO user_roles (user_id, role, scope_type, scope_id, granted_by)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''))
+		ON CONFLICT (user_id, role, scope_type, scope_id) DO UPDATE SET role = EXCLUDED.role
+		RETURNING id, created_at, xmax = 0`
+
+	// xmax = 0 — строка вставлена, а не найдена по ON CONFLICT: повторное назначение журнал не засоряет
+	var created bool
+	err = tx.QueryRowContext(ctx, query,
+		ra.UserID, ra.Role, ra.ScopeType, ra.ScopeID, ra.GrantedBy,
+	).Scan(&ra.ID, &ra.CreatedAt, &created)
+
+	if err != nil {
+		return dbError("не удалось назначить роль", err)
+	}
+	if created {
+		if err := recordChange(ctx, tx, "user_role", "user_roles", ra.ID, "create", nil); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("repo: ошибка фиксации транзакции: %w", err)
+	}
+	return nil
+}
+
+// Revoke отзывает назначение роли.
+func (r *RoleRepository) Revoke(ctx context.Context, userID, id int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("repo: не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback()
+
+	before, err := snapshot(ctx, tx, "user_roles", "id", id)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, "DELETE FROM user_roles WHERE id = $1 AND user_id = $2", id, userID)
+	if err != nil {
+		return fmt.Errorf("repo: ошибка при отзыве роли: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return NotFound("назначение роли с ID %d не найдено", id)
+	}
+
+	if err := recordChange(ctx, tx, "user_role", "user_roles", id, "delete", before); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("repo: ошибка фиксации транзакции: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Типы записей, среди которых ищет Search.
+const (
+	SearchAthlete     = "athlete"
+	SearchCompetition = "competition"
+	SearchClub        = "club"
+)
+
+// SearchTypes — все типы записей, доступные для поиска.
+var SearchTypes = []string{SearchAthlete, SearchCompetition, SearchClub}
+
+// SearchHit — найденная запись: достаточно данных, чтобы показать подсказку и открыть карточку по ID.
+type SearchHit struct {
+	Type     string  `json:"type"`
+	ID       int     `json:"id"`
+	Title    string  `json:"title"`
+	Subtitle string  `json:"subtitle,omitempty"` // Клуб спортсмена, место и дата соревнования, город клуба
+	Score    float64 `json:"score"`              // Релевантность: чем больше, тем выше в списке
+}
+
+// SearchQuery — условия поиска.
+type SearchQuery struct {
+	Terms []string // Варианты написания запроса (исходный и транслитерированный)
+	Types []string // Типы записей; пустой список — все
+	Limit int
+}
+
+// searchSource описывает, где и по какому полю искать записи одного типа.
+type searchSource struct {
+	typ      string
+	id       string
+	name     string // Поле, по которому ищем (на нем триграммный и полнотекстовый индексы)
+	subtitle string
+	from     string
+	alive    string // Условие, скрывающее удаленные записи
+}
+
+var searchSources = []searchSource{
+	{
+		typ: SearchAthlete, id: "a.id", name: "a.full_name", subtitle: "COALESCE(cl.name, '')",
+		from: "athletes a LEFT JOIN clubs cl ON cl.id = a.club_id", alive: "a.deleted_at IS NULL",
+	},
+	{
+		typ: SearchCompetition, id: "c.id", name: "c.name",
+		subtitle: "concat_ws(', ', c.location, to_char(c.start_date, 'YYYY-MM-DD'))",
+		from:     "competitions c", alive: "c.deleted_at IS NULL",
+	},
+	{
+		typ: SearchClub, id: "cl.id", name: "cl.name", subtitle: "COALESCE(cl.city, '')",
+		from: "clubs cl", alive: "TRUE",
+	},
+}
+
+// SearchRepository ищет спортсменов, соревнования и клубы по названию.
+type SearchRepository struct {
+	db *sql.DB
+}
+
+// NewSearchRepository создает новый экземпляр репозитория поиска.
+func NewSearchRepository(db *sql.DB) *SearchRepository {
+	return &SearchRepository{db: db}
+}
+
+// Search находит записи, название которых совпадает с любым из вариантов запроса по префиксам слов
+// (полнотекстовый поиск) или похоже на него (триграммы pg_trgm), и упорядочивает их по релевантности.
+func (r *SearchRepository) Search(ctx context.Context, sq SearchQuery) ([]SearchHit, error) {
+	q := &listQuery{}
+	type term struct{ text, prefixes string }
+	var terms []term
+	for _, t := range sq.Terms {
+		if prefixes := prefixQuery(t); prefixes != "" {
+			terms = append(terms, term{text: q.arg(t), prefixes: "to_tsquery('simple', " + q.arg(prefixes) + ")"})
+		}
+	}
+	if len(terms) == 0 {
+		return []SearchHit{}, nil
+	}
+	limit := q.arg(sq.Limit)
+
+	var parts []string
+	for _, src := range searchSources {
+		if len(sq.Types) > 0 && !slices.Contains(sq.Types, src.typ) {
+			continue
+		}
+		scores := make([]string, 0, len(terms))
+		matches := make([]string, 0, len(terms)*2)
+		for _, t := range terms {
+			scores = append(scores, "search_score("+src.name+", "+t.text+", "+t.prefixes+")")
+			// %> — похожесть запроса на одно из слов названия выше порога pg_trgm.word_similarity_threshold
+			matches = append(matches,
+				src.name+" %> "+t.text,
+				"to_tsvector('simple', "+src.name+") @@ "+t.prefixes)
+		}
+		// Каждый тип ограничиваем отдельно, чтобы общая сортировка не перебирала все совпадения
+		parts = append(parts, `(SELECT '`+src.typ+`' AS type, `+src.id+` AS id, `+src.name+` AS title, `+src.subtitle+` AS subtitle,
+				GREATEST(`+strings.Join(scores, ", ")+`) AS score
+			FROM `+src.from+`
+			WHERE `+src.alive+` AND (`+strings.Join(matches, " OR ")+`)
+			ORDER BY score DESC
+			LIMIT `+limit+`)`)
+	}
+	if len(parts) == 0 {
+		return []SearchHit{}, nil
+	}
+
+	query := `SELECT type, id, title, subtitle, score FROM (` + strings.Join(parts, " UNION ALL ") + `) hits
+		ORDER BY score DESC, title ASC
+		LIMIT ` + limit
+
+	rows, err := r.db.QueryContext(ctx, query, q.args...)
+	if err != nil {
+		return nil, fmt.Errorf("repo: ошибка поиска: %w", err)
+	}
+	defer rows.Close()
+
+	hits := make([]SearchHit, 0)
+	for rows.Next() {
+		var h SearchHit
+		if err := rows.Scan(&h.Type, &h.ID, &h.Title, &h.Subtitle, &h.Score); err != nil {
+			return nil, fmt.Errorf("repo: ошибка сканирования результата поиска: %w", err)
+		}
+		hits = append(hits, h)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repo: ошибка итерации результатов поиска: %w", err)
+	}
+	return hits, nil
+}
+
+// prefixQuery строит запрос to_tsquery, в котором каждое слово ищется как префикс («иван пет» → «иван:* & пет:*»).
+// В запрос попадают только буквы и цифры, поэтому ввод пользователя не может нарушить его синтаксис.
+func prefixQuery(term string) string {
+	words := strings.FieldsFunc(strings.ToLower(term), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, w := range words {
+		words[i] = w + ":*"
+	}
+	return strings.Join(words, " & ")
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// SettingsRepository хранит системные настройки, которые администратор меняет через API.
+type SettingsRepository struct {
+	db *sql.DB
+}
+
+// NewSettingsRepository создает новый экземпляр репозитория настроек.
+func NewSettingsRepository(db *sql.DB) *SettingsRepository {
+	return &SettingsRepository{db: db}
+}
+
+// Get возвращает значение настройки; если она не задана — def.
+func (r *SettingsRepository) Get(ctx context.Context, key, def string) (string, error) {
+	var value string
+	err := r.db.QueryRowContext(ctx, "SELECT value FROM app_settings WHERE key = $1", key).Scan(&value)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return def, nil
+		}
+		return "", fmt.Errorf("repo: ошибка при получении настройки '%s': %w", key, err)
+	}
+	return value, nil
+}
+
+// Set создает или обновляет настройку.
+func (r *SettingsRepository) Set(ctx context.Context, key, value, updatedBy string) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO app_settings (key, value, updated_by, updated_at)
+		VALUES ($1, $2, NULLIF($3, ''), NOW())
+		ON CONFLICT (key) DO UPDATE
+		SET value = EXCLUDED.value, updated_by = EXCLUDED.updated_by, updated_at = NOW()`,
+		key, value, updatedBy,
+	)
+	if err != nil {
+		return fmt.Errorf("repo: не удалось сохранить настройку '%s': %w", key, err)
+	}
+	return nil
+}
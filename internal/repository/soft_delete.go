@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// notFoundMessages — тексты ошибки «не найдено» для таблиц с мягким удалением.
+var notFoundMessages = map[string]string{
+	"athletes":       "атлет с ID %d не найден",
+	"competitions":   "соревнование с ID %d не найдено",
+	"participations": "запись об участии с ID %d не найдена",
+}
+
+// isDeleted проверяет по снимку строки (см. snapshot), помечена ли она как удаленная.
+func isDeleted(row json.RawMessage) bool {
+	var state struct {
+		DeletedAt *string `json:"deleted_at"`
+	}
+	return json.Unmarshal(row, &state) == nil && state.DeletedAt != nil
+}
+
+// setDeleted помечает строку удаленной (deleted = true) или восстанавливает ее
+// и записывает действие в журнал аудита в той же транзакции.
+// expectedVersion — версия строки, которую видел клиент (AnyVersion — без проверки).
+func setDeleted(ctx context.Context, db *sql.DB, entity, table string, id int, deleted bool, expectedVersion int) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("repo: не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback()
+
+	before, err := snapshot(ctx, tx, table, "id", id)
+	if err != nil {
+		return err
+	}
+	if before == nil {
+		return NotFound(notFoundMessages[table], id)
+	}
+
+	action := "delete"
+	if deleted {
+		// Повторное удаление выглядит для клиента так же, как удаление несуществующей записи
+		if isDeleted(before) {
+			return NotFound(notFoundMessages[table], id)
+		}
+	} else {
+		if !isDeleted(before) {
+			return Conflict("запись с ID %d не удалена, восстанавливать нечего", id)
+		}
+		action = "restore"
+	}
+	if err := checkVersion(before, expectedVersion); err != nil {
+		return err
+	}
+
+	query := `UPDATE ` + table + ` SET deleted_at = CASE WHEN $2 THEN NOW() END WHERE id = $1`
+	if _, err := tx.ExecContext(ctx, query, id, deleted); err != nil {
+		return dbError("не удалось изменить признак удаления", err)
+	}
+
+	if err := recordChange(ctx, tx, entity, table, id, action, before); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("repo: ошибка фиксации транзакции: %w", err)
+	}
+	return nil
+}
+
+// purge безвозвратно удаляет мягко удаленную строку. Если задан participationColumn,
+// сначала явно удаляются участия, ссылающиеся на строку, а до них — их результаты;
+// каждое удаление попадает в журнал аудита. Ненулевой cutoff защищает участия, срок хранения которых
+// не истек: если есть неудаленные участия или удаленные не раньше cutoff, возвращается конфликт
+// и ничего не удаляется. С нулевым cutoff удаляются все участия.
+func purge(ctx context.Context, db *sql.DB, entity, table, participationColumn string, id int, cutoff time.Time) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("repo: не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback()
+
+	before, err := snapshot(ctx, tx, table, "id", id)
+	if err != nil {
+		return err
+	}
+	if before == nil {
+		return NotFound(notFoundMessages[table], id)
+	}
+	if !isDeleted(before) {
+		return Conflict("запись с ID %d не удалена: окончательно удалить можно только удаленную запись", id)
+	}
+
+	if participationColumn != "" {
+		if !cutoff.IsZero() {
+			kept, err := queryIDs(ctx, tx, `SELECT id FROM participations
+				WHERE `+participationColumn+` = $1 AND (deleted_at IS NULL OR deleted_at >= $2)`, id, cutoff)
+			if err != nil {
+				return err
+			}
+			if len(kept) > 0 {
+				return Conflict("у записи с ID %d есть участия, которые еще нельзя удалить (%d)", id, len(kept))
+			}
+		}
+		ids, err := queryIDs(ctx, tx, `SELECT id FROM participations WHERE `+participationColumn+` = $1 ORDER BY id`, id)
+		if err != nil {
+			return err
+		}
+		for _, pid := range ids {
+			if err := deleteParticipation(ctx, tx, pid); err != nil {
+				return err
+			}
+		}
+	}
+
+	if table == "participations" {
+		err = deleteParticipation(ctx, tx, id)
+	} else {
+		err = deleteRow(ctx, tx, entity, table, id)
+	}
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("repo: ошибка фиксации транзакции: %w", err)
+	}
+	return nil
+}
+
+// deleteParticipation удаляет участие вместе с результатами. Результаты удаляются явно, а не
+// каскадом внешнего ключа, чтобы их последнее состояние попало в журнал аудита.
+func deleteParticipation(ctx context.Context, tx *sql.Tx, id int) error {
+	results, err := queryIDs(ctx, tx, `SELECT id FROM results WHERE participation_id = $1 ORDER BY id`, id)
+	if err != nil {
+		return err
+	}
+	for _, rid := range results {
+		if err := deleteRow(ctx, tx, "result", "results", rid); err != nil {
+			return err
+		}
+	}
+	return deleteRow(ctx, tx, "participation", "participations", id)
+}
+
+// deleteRow удаляет строку в транзакции и записывает в журнал ее последнее состояние.
+func deleteRow(ctx context.Context, tx *sql.Tx, entity, table string, id int) error {
+	before, err := snapshot(ctx, tx, table, "id", id)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM `+table+` WHERE id = $1`, id); err != nil {
+		return dbError("не удалось окончательно удалить запись", err)
+	}
+	return recordChange(ctx, tx, entity, table, id, "purge", before)
+}
+
+// listDeletedBefore возвращает ID строк таблицы, мягко удаленных раньше cutoff.
+func listDeletedBefore(ctx context.Context, db *sql.DB, table string, cutoff time.Time) ([]int, error) {
+	return queryIDs(ctx, db, `SELECT id FROM `+table+` WHERE deleted_at < $1 ORDER BY id`, cutoff)
+}
+
+// idQueryer — общее у *sql.DB и *sql.Tx для выборки списка ID.
+type idQueryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// queryIDs выполняет запрос, возвращающий один столбец с ID.
+func queryIDs(ctx context.Context, q idQueryer, query string, args ...interface{}) ([]int, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("repo: ошибка при выборке ID: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make([]int, 0)
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("repo: ошибка сканирования ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repo: ошибка итерации ID: %w", err)
+	}
+	return ids, nil
+}
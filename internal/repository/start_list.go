@@ -0,0 +1,283 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// BibRange — диапазон стартовых номеров, закрепленный за категорией.
+type BibRange struct {
+	Category string `json:"category"`
+	From     int    `json:"from"`
+	To       int    `json:"to"`
+}
+
+// BibSettings описывает правила выдачи стартовых номеров на соревновании.
+type BibSettings struct {
+	CompetitionID int        `json:"competition_id"`
+	Mode          string     `json:"mode"` // 'sequential', 'random', 'ranges'
+	StartNumber   int        `json:"start_number"`
+	Ranges        []BibRange `json:"ranges"`
+}
+
+// StartListEntry — строка стартового протокола.
+type StartListEntry struct {
+	ParticipationID int        `json:"participation_id"`
+	StartOrder      int        `json:"start_order"` // 0 — порядок еще не сформирован
+	StartTime       *time.Time `json:"start_time,omitempty"`
+	Bib             int        `json:"bib"` // 0 — номер еще не выдан
+	AthleteID       int        `json:"athlete_id"`
+	AthleteName     string     `json:"athlete_name"`
+	Club            string     `json:"club"`
+	Category        string     `json:"category"`
+	Event           string     `json:"event"`
+	SeedResult      *float64   `json:"seed_result,omitempty"`
+}
+
+// StartListRepository управляет стартовыми номерами и порядком старта участников.
+type StartListRepository struct {
+	db *sql.DB
+}
+
+// NewStartListRepository создает новый экземпляр репозитория стартовых протоколов.
+func NewStartListRepository(db *sql.DB) *StartListRepository {
+	return &StartListRepository{db: db}
+}
+
+// --- НАСТРОЙКИ НОМЕРОВ ---
+
+// GetBibSettings возвращает настройки выдачи номеров.
+// Если соревнование не настраивалось, возвращаются значения по умолчанию (по порядку с 1).
+func (r *StartListRepository) GetBibSettings(ctx context.Context, competitionID int) (*BibSettings, error) {
+	settings := &BibSettings{CompetitionID: competitionID, Mode: "sequential", StartNumber: 1}
+
+	err := r.db.QueryRowContext(ctx,
+		"SELECT mode, start_number FROM bib_settings WHERE competition_id = $1",
+		competitionID,
+	).Scan(&settings.Mode, &settings.StartNumber)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("repo: ошибка при получении настроек номеров: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT category, range_start, range_end
+		FROM bib_ranges
+		WHERE competition_id = $1
+		ORDER BY range_start`,
+		competitionID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("repo: ошибка при получении диапазонов номеров: %w", err)
+	}
+	defer rows.Close()
+
+	settings.Ranges = make([]BibRange, 0)
+	for rows.Next() {
+		var br BibRange
+		if err := rows.Scan(&br.Category, &br.From, &br.To); err != nil {
+			return nil, fmt.Errorf("repo: ошибка сканирования диапазона: %w", err)
+		}
+		settings.Ranges = append(settings.Ranges, br)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repo: ошибка итерации строк: %w", err)
+	}
+	return settings, nil
+}
+
+// SaveBibSettings сохраняет настройки и полностью заменяет диапазоны номеров.
+func (r *StartListRepository) SaveBibSettings(ctx context.Context, s *BibSettings) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("repo: не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO bib_settings (competition_id, mode, start_number)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (competition_id) DO UPDATE
+		SET mode = EXCLUDED.mode, start_number = EXCLUDED.start_number`,
+		s.CompetitionID, s.Mode, s.StartNumber,
+	)
+	if err != nil {
+		return fmt.Errorf("repo: не удалось сохранить настройки номеров: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM bib_ranges WHERE competition_id = $1", s.CompetitionID); err != nil {
+		return fmt.Errorf("repo: не удалось очистить диапазоны номеров: %w", err)
+	}
+	for _, br := range s.Ranges {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO bib_ranges (competition_id, category, range_start, range_end)
+			VALUES ($1, $2, $3, $4)`,
+			s.CompetitionID, br.Category, br.From, br.To,
+		)
+		if err != nil {
+			return fmt.Errorf("repo: не удалось сохранить диапазон '%s': %w", br.Category, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("repo: ошибка фиксации транзакции: %w", err)
+	}
+	return nil
+}
+
+// --- СТАРТОВЫЙ ПРОТОКОЛ ---
+
+// ListEntries возвращает допущенных участников соревнования (пустой event — все дисциплины)
+// в порядке старта; участники без сформированного порядка идут в конце.
+func (r *StartListRepository) ListEntries(ctx context.Context, competitionID int, event string) ([]StartListEntry, error) {
+	query := `
+		SELECT p.id, COALESCE(p.start_order, 0), p.start_time, COALESCE(p.bib, 0),
+		       a.id, a.full_name, COALESCE(cl.name, ''),
+		       COALESCE(p.category, ''), COALESCE(p.event, ''), p.seed_result
+		FROM participations p
+		JOIN athletes a ON a.id = p.athlete_id
+		LEFT JOIN clubs cl ON cl.id = a.club_id
+		WHERE p.competition_id = $1 AND ($2 = '' OR p.event = $2)
+		  AND p.deleted_at IS NULL AND a.deleted_at IS NULL
+		  -- В весовых категориях участвуют только прошедшие взвешивание
+		  AND (p.weight_class_id IS NULL OR p.weigh_in_status IN ('passed', 'moved'))
+		ORDER BY p.event NULLS LAST, p.start_order NULLS LAST, p.id`
+
+	rows, err := r.db.QueryContext(ctx, query, competitionID, event)
+	if err != nil {
+		return nil, fmt.Errorf("repo: ошибка при получении стартового протокола: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]StartListEntry, 0)
+	for rows.Next() {
+		var (
+			e         StartListEntry
+			startTime sql.NullTime
+			seed      sql.NullFloat64
+		)
+		err := rows.Scan(
+			&e.ParticipationID, &e.StartOrder, &startTime, &e.Bib,
+			&e.AthleteID, &e.AthleteName, &e.Club,
+			&e.Category, &e.Event, &seed,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("repo: ошибка сканирования строки протокола: %w", err)
+		}
+		if startTime.Valid {
+			e.StartTime = &startTime.Time
+		}
+		if seed.Valid {
+			e.SeedResult = &seed.Float64
+		}
+		entries = append(entries, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repo: ошибка итерации строк: %w", err)
+	}
+	return entries, nil
+}
+
+// BibHolders возвращает все занятые на соревновании номера (номер → ID записи об участии),
+// включая удаленных и не прошедших взвешивание участников: их номера нельзя выдать повторно.
+func (r *StartListRepository) BibHolders(ctx context.Context, competitionID int) (map[int]int, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT bib, id FROM participations WHERE competition_id = $1 AND bib IS NOT NULL", competitionID)
+	if err != nil {
+		return nil, fmt.Errorf("repo: ошибка при получении занятых номеров: %w", err)
+	}
+	defer rows.Close()
+
+	holders := make(map[int]int)
+	for rows.Next() {
+		var bib, id int
+		if err := rows.Scan(&bib, &id); err != nil {
+			return nil, fmt.Errorf("repo: ошибка сканирования номера: %w", err)
+		}
+		holders[bib] = id
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repo: ошибка итерации номеров: %w", err)
+	}
+	return holders, nil
+}
+
+// SaveBibs записывает выданные стартовые номера в одной транзакции.
+// Ключ — ID записи об участии, значение — номер.
+func (r *StartListRepository) SaveBibs(ctx context.Context, competitionID int, bibs map[int]int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("repo: не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Состояние до изменения нужно журналу аудита; снимок заодно блокирует строки
+	before := make(map[int]json.RawMessage, len(bibs))
+	for id := range bibs {
+		if before[id], err = snapshot(ctx, tx, "participations", "id", id); err != nil {
+			return err
+		}
+	}
+
+	// Сначала снимаем старые номера, чтобы перестановки не нарушали уникальный индекс
+	for id := range bibs {
+		if _, err := tx.ExecContext(ctx,
+			"UPDATE participations SET bib = NULL WHERE id = $1 AND competition_id = $2", id, competitionID,
+		); err != nil {
+			return fmt.Errorf("repo: не удалось сбросить номер: %w", err)
+		}
+	}
+	for id, bib := range bibs {
+		if _, err := tx.ExecContext(ctx,
+			"UPDATE participations SET bib = $3 WHERE id = $1 AND competition_id = $2", id, competitionID, bib,
+		); err != nil {
+			return dbError(fmt.Sprintf("не удалось присвоить номер %d", bib), err)
+		}
+	}
+	for id := range bibs {
+		if err := recordChange(ctx, tx, "participation", "participations", id, "assign_bib", before[id]); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("repo: ошибка фиксации транзакции: %w", err)
+	}
+	return nil
+}
+
+// SaveStartOrder записывает порядок и время старта участников в одной транзакции.
+func (r *StartListRepository) SaveStartOrder(ctx context.Context, competitionID int, entries []StartListEntry) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("repo: не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, e := range entries {
+		before, err := snapshot(ctx, tx, "participations", "id", e.ParticipationID)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `
+			UPDATE participations SET start_order = $3, start_time = $4
+			WHERE id = $1 AND competition_id = $2`,
+			e.ParticipationID, competitionID, e.StartOrder, e.StartTime,
+		)
+		if err != nil {
+			return fmt.Errorf("repo: не удалось сохранить порядок старта: %w", err)
+		}
+		if err := recordChange(ctx, tx, "participation", "participations", e.ParticipationID, "start_order", before); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("repo: ошибка фиксации транзакции: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// RefreshToken описывает сохраненный refresh-токен. Сам токен в базе не хранится — только его хеш.
+type RefreshToken struct {
+	ID        int
+	UserID    int
+	FamilyID  string
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+}
+
+// TokenRepository управляет refresh-токенами и списком отозванных access-токенов.
+type TokenRepository struct {
+	db *sql.DB
+}
+
+// NewTokenRepository создает новый экземпляр репозитория токенов.
+func NewTokenRepository(db *sql.DB) *TokenRepository {
+	return &TokenRepository{db: db}
+}
+
+// --- REFRESH-ТОКЕНЫ ---
+
+// CreateRefreshToken сохраняет хеш нового refresh-токена.
+func (r *TokenRepository) CreateRefreshToken(ctx context.Context, t *RefreshToken) error {
+	query := `
+		INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id`
+
+	if err := r.db.QueryRowContext(ctx, query, t.UserID, t.FamilyID, t.TokenHash, t.ExpiresAt).Scan(&t.ID); err != nil {
+		return fmt.Errorf("repo: не удалось сохранить refresh-токен: %w", err)
+	}
+	return nil
+}
+
+// GetRefreshToken ищет refresh-токен по хешу.
+func (r *TokenRepository) GetRefreshToken(ctx context.Context, tokenHash string) (*RefreshToken, error) {
+	query := `
+		SELECT id, user_id, family_id, token_hash, expires_at, used_at, revoked_at
+		FROM refresh_tokens
+		WHERE token_hash = $1`
+
+	t := &RefreshToken{}
+	err := r.db.QueryRowContext(ctx, query, tokenHash).Scan(
+		&t.ID, &t.UserID, &t.FamilyID, &t.TokenHash, &t.ExpiresAt, &t.UsedAt, &t.RevokedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, NotFound("refresh-токен не найден")
+		}
+		return nil, fmt.Errorf("repo: ошибка при поиске refresh-токена: %w", err)
+	}
+	return t, nil
+}
+
+// MarkRefreshTokenUsed помечает токен как обмененный.
+// Возвращает false, если токен уже был использован или отозван (гонка параллельных обменов).
+func (r *TokenRepository) MarkRefreshTokenUsed(ctx context.Context, id int) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE refresh_tokens SET used_at = NOW()
+		WHERE id = $1 AND used_at IS NULL AND revoked_at IS NULL`,
+		id,
+	)
+	if err != nil {
+		return false, fmt.Errorf("repo: не удалось обновить refresh-токен: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected == 1, nil
+}
+
+// RevokeFamily отзывает все токены семейства (выход или обнаружение повторного использования).
+func (r *TokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	_, err := r.db.ExecContext(ctx,
+		"UPDATE refresh_tokens SET revoked_at = NOW() WHERE family_id = $1 AND revoked_at IS NULL",
+		familyID,
+	)
+	if err != nil {
+		return fmt.Errorf("repo: не удалось отозвать семейство токенов: %w", err)
+	}
+	return nil
+}
+
+// RevokeUserTokens отзывает все refresh-токены пользователя (например, при блокировке аккаунта).
+func (r *TokenRepository) RevokeUserTokens(ctx context.Context, userID int) error {
+	_, err := r.db.ExecContext(ctx,
+		"UPDATE refresh_tokens SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL",
+		userID,
+	)
+	if err != nil {
+		return fmt.Errorf("repo: не удалось отозвать токены пользователя: %w", err)
+	}
+	return nil
+}
+
+// --- DENYLIST ACCESS-ТОКЕНОВ ---
+
+// RevokeAccessToken добавляет jti access-токена в denylist до истечения его срока.
+// Заодно удаляются записи, срок которых уже истек: такие токены отклонит проверка exp.
+func (r *TokenRepository) RevokeAccessToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	if _, err := r.db.ExecContext(ctx, "DELETE FROM revoked_tokens WHERE expires_at < NOW()"); err != nil {
+		return fmt.Errorf("repo: не удалось очистить denylist: %w", err)
+	}
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO revoked_tokens (jti, expires_at)
+		VALUES ($1, $2)
+		ON CONFLICT (jti) DO NOTHING`,
+		jti, expiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("repo: не удалось отозвать access-токен: %w", err)
+	}
+	return nil
+}
+
+// IsAccessTokenRevoked проверяет, находится ли jti в denylist.
+func (r *TokenRepository) IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	var revoked bool
+	err := r.db.QueryRowContext(ctx,
+		"SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $1)", jti,
+	).Scan(&revoked)
+	if err != nil {
+		return false, fmt.Errorf("repo: ошибка проверки denylist: %w", err)
+	}
+	return revoked, nil
+}
+
+// --- ТОКЕНЫ ИЗ ПИСЕМ ---
+
+// Назначение токенов из писем.
+const (
+	EmailTokenVerify = "verify"
+	EmailTokenReset  = "reset"
+)
+
+// CreateEmailToken сохраняет хеш одноразового токена из письма.
+// Ранее выданные неиспользованные токены того же назначения аннулируются:
+// действует только ссылка из последнего письма.
+func (r *TokenRepository) CreateEmailToken(ctx context.Context, userID int, purpose, tokenHash string, expiresAt time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("repo: не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		"UPDATE email_tokens SET used_at = NOW() WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL",
+		userID, purpose,
+	); err != nil {
+		return fmt.Errorf("repo: не удалось аннулировать старые токены: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO email_tokens (user_id, purpose, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4)`,
+		userID, purpose, tokenHash, expiresAt,
+	); err != nil {
+		return fmt.Errorf("repo: не удалось сохранить токен: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("repo: ошибка фиксации транзакции: %w", err)
+	}
+	return nil
+}
+
+// ConsumeEmailToken атомарно погашает действующий токен и возвращает ID пользователя.
+// Использованный, просроченный или неизвестный токен дает ошибку.
+func (r *TokenRepository) ConsumeEmailToken(ctx context.Context, purpose, tokenHash string) (int, error) {
+	var userID int
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE email_tokens SET used_at = NOW()
+		WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING user_id`,
+		tokenHash, purpose,
+	).Scan(&userID)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, NotFound("ссылка недействительна или устарела")
+		}
+		return 0, fmt.Errorf("repo: ошибка при проверке токена: %w", err)
+	}
+	return userID, nil
+}
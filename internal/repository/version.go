@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// EntityVersion — одна версия записи: снимок строки и период, в течение которого он был действующим.
+type EntityVersion struct {
+	Version   int             `json:"version"`
+	ValidFrom *time.Time      `json:"valid_from"` // nil — версия существовала до начала ведения истории
+	ValidTo   *time.Time      `json:"valid_to"`   // nil — действующая версия
+	ChangedBy string          `json:"changed_by,omitempty"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// versionedTables — таблицы, для которых ведется история версий, и имена их сущностей.
+var versionedTables = map[string]string{
+	"athletes":     "athlete",
+	"competitions": "competition",
+}
+
+// VersionRepository читает историю версий записей.
+// Версии пишутся в recordChange, в той же транзакции, что и изменение.
+type VersionRepository struct {
+	db *sql.DB
+}
+
+// NewVersionRepository создает новый экземпляр репозитория версий.
+func NewVersionRepository(db *sql.DB) *VersionRepository {
+	return &VersionRepository{db: db}
+}
+
+// saveVersion закрывает действующую версию записи и, если запись не удалена окончательно (after != nil),
+// добавляет новую версию со снимком after.
+func saveVersion(ctx context.Context, tx *sql.Tx, entity string, id int, after json.RawMessage, changedBy string) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE entity_versions SET valid_to = NOW()
+		WHERE entity = $1 AND entity_id = $2 AND valid_to IS NULL`,
+		entity, id,
+	)
+	if err != nil {
+		return fmt.Errorf("repo: не удалось закрыть версию записи: %w", err)
+	}
+	if after == nil {
+		return nil
+	}
+
+	// Номер версии берется из столбца version самой строки (его увеличивает триггер), чтобы номер
+	// в истории совпадал с ETag; счетчик по истории — только для таблиц без этого столбца
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO entity_versions (entity, entity_id, version, data, changed_by, valid_from)
+		SELECT $1, $2, COALESCE(($3::jsonb ->> 'version')::int, MAX(version) + 1, 1), $3, NULLIF($4, ''), NOW()
+		FROM entity_versions WHERE entity = $1 AND entity_id = $2`,
+		entity, id, []byte(after), changedBy,
+	)
+	if err != nil {
+		return fmt.Errorf("repo: не удалось сохранить версию записи: %w", err)
+	}
+	return nil
+}
+
+// History возвращает все версии записи, начиная с первой.
+func (r *VersionRepository) History(ctx context.Context, entity string, id int) ([]EntityVersion, error) {
+	query := `
+		SELECT version, valid_from, valid_to, COALESCE(changed_by, ''), data
+		FROM entity_versions
+		WHERE entity = $1 AND entity_id = $2
+		ORDER BY version ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, entity, id)
+	if err != nil {
+		return nil, fmt.Errorf("repo: ошибка при получении истории версий: %w", err)
+	}
+	defer rows.Close()
+
+	versions := make([]EntityVersion, 0)
+	for rows.Next() {
+		v, err := scanVersion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("repo: ошибка сканирования версии: %w", err)
+		}
+		versions = append(versions, *v)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repo: ошибка итерации версий: %w", err)
+	}
+	return versions, nil
+}
+
+// Get возвращает конкретную версию записи.
+func (r *VersionRepository) Get(ctx context.Context, entity string, id, version int) (*EntityVersion, error) {
+	query := `
+		SELECT version, valid_from, valid_to, COALESCE(changed_by, ''), data
+		FROM entity_versions
+		WHERE entity = $1 AND entity_id = $2 AND version = $3`
+
+	v, err := scanVersion(r.db.QueryRowContext(ctx, query, entity, id, version))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, NotFound("версия %d записи с ID %d не найдена", version, id)
+		}
+		return nil, fmt.Errorf("repo: ошибка при получении версии: %w", err)
+	}
+	return v, nil
+}
+
+// scanVersion читает одну строку версии.
+func scanVersion(row rowScanner) (*EntityVersion, error) {
+	v := &EntityVersion{}
+	var (
+		from, to sql.NullTime
+		data     []byte
+	)
+	if err := row.Scan(&v.Version, &from, &to, &v.ChangedBy, &data); err != nil {
+		return nil, err
+	}
+	if from.Valid {
+		v.ValidFrom = &from.Time
+	}
+	if to.Valid {
+		v.ValidTo = &to.Time
+	}
+	v.Data = data
+	return v, nil
+}
+
+// asOfSource — подзапрос, разворачивающий версию, действовавшую на момент $2, в строку таблицы.
+// Так выборка «на дату» использует те же столбцы, что и выборка текущего состояния.
+func asOfSource(table string) string {
+	return `(
+		SELECT t.* FROM entity_versions v, jsonb_populate_record(NULL::` + table + `, v.data) t
+		WHERE v.entity = '` + versionedTables[table] + `' AND v.entity_id = $1
+		  AND (v.valid_from IS NULL OR v.valid_from <= $2)
+		  AND (v.valid_to IS NULL OR v.valid_to > $2)
+	) AS ` + table
+}
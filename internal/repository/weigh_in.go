@@ -0,0 +1,311 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// WeightClass описывает весовую категорию соревнования для одного пола.
+// Спортсмен попадает в категорию, если его вес больше MinWeight и не превышает MaxWeight.
+type WeightClass struct {
+	ID            int      `json:"id"`
+	CompetitionID int      `json:"competition_id"`
+	Gender        string   `json:"gender"`
+	Name          string   `json:"name"`
+	MinWeight     float64  `json:"min_weight"`
+	MaxWeight     *float64 `json:"max_weight,omitempty"` // nil — без верхней границы
+}
+
+// WeighInRules описывает правила взвешивания соревнования.
+type WeighInRules struct {
+	CompetitionID        int     `json:"competition_id"`
+	ToleranceKg          float64 `json:"tolerance_kg"`
+	ReweighWindowMinutes int     `json:"reweigh_window_minutes"`
+	OverweightAction     string  `json:"overweight_action"` // 'move' или 'disqualify'
+}
+
+// WeighIn — одна попытка взвешивания участника.
+type WeighIn struct {
+	ID              int       `json:"id"`
+	ParticipationID int       `json:"participation_id"`
+	WeightClassID   int       `json:"weight_class_id"`
+	MeasuredWeight  float64   `json:"measured_weight"`
+	MeasuredAt      time.Time `json:"measured_at"`
+	Official        string    `json:"official"`
+	Outcome         string    `json:"outcome"` // 'passed', 'reweigh', 'moved', 'disqualified'
+	Notes           string    `json:"notes"`
+}
+
+// WeighInRepository управляет весовыми категориями и протоколом взвешивания.
+type WeighInRepository struct {
+	db *sql.DB
+}
+
+// NewWeighInRepository создает новый экземпляр репозитория взвешиваний.
+func NewWeighInRepository(db *sql.DB) *WeighInRepository {
+	return &WeighInRepository{db: db}
+}
+
+// --- ВЕСОВЫЕ КАТЕГОРИИ ---
+
+// ListClasses возвращает весовые категории соревнования, упорядоченные по полу и весу.
+func (r *WeighInRepository) ListClasses(ctx context.Context, competitionID int) ([]WeightClass, error) {
+	query := `
+		SELECT id, competition_id, gender, name, min_weight, max_weight
+		FROM weight_classes
+		WHERE competition_id = $1
+		ORDER BY gender, min_weight`
+
+	rows, err := r.db.QueryContext(ctx, query, competitionID)
+	if err != nil {
+		return nil, fmt.Errorf("repo: ошибка при получении весовых категорий: %w", err)
+	}
+	defer rows.Close()
+
+	classes := make([]WeightClass, 0)
+	for rows.Next() {
+		wc, err := scanWeightClass(rows)
+		if err != nil {
+			return nil, fmt.Errorf("repo: ошибка сканирования весовой категории: %w", err)
+		}
+		classes = append(classes, *wc)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repo: ошибка итерации строк: %w", err)
+	}
+	return classes, nil
+}
+
+// GetClass возвращает весовую категорию по ID.
+func (r *WeighInRepository) GetClass(ctx context.Context, id int) (*WeightClass, error) {
+	query := `
+		SELECT id, competition_id, gender, name, min_weight, max_weight
+		FROM weight_classes
+		WHERE id = $1`
+
+	wc, err := scanWeightClass(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, NotFound("весовая категория с ID %d не найдена", id)
+		}
+		return nil, fmt.Errorf("repo: ошибка при получении весовой категории: %w", err)
+	}
+	return wc, nil
+}
+
+// FindClassForWeight подбирает категорию соревнования, в которую попадает указанный вес.
+// Возвращает nil, если подходящей категории нет.
+func (r *WeighInRepository) FindClassForWeight(ctx context.Context, competitionID int, gender string, weight float64) (*WeightClass, error) {
+	query := `
+		SELECT id, competition_id, gender, name, min_weight, max_weight
+		FROM weight_classes
+		WHERE competition_id = $1 AND gender = $2
+		  AND $3 > min_weight AND (max_weight IS NULL OR $3 <= max_weight)
+		ORDER BY min_weight
+		LIMIT 1`
+
+	wc, err := scanWeightClass(r.db.QueryRowContext(ctx, query, competitionID, gender, weight))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("repo: ошибка при подборе весовой категории: %w", err)
+	}
+	return wc, nil
+}
+
+// CreateClass добавляет весовую категорию.
+func (r *WeighInRepository) CreateClass(ctx context.Context, wc *WeightClass) error {
+	query := `
+		INSERT INTO weight_classes (competition_id, gender, name, min_weight, max_weight)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id`
+
+	err := r.db.QueryRowContext(ctx, query,
+		wc.CompetitionID, wc.Gender, wc.Name, wc.MinWeight, wc.MaxWeight,
+	).Scan(&wc.ID)
+
+	if err != nil {
+		return dbError("не удалось создать весовую категорию", err)
+	}
+	return nil
+}
+
+// DeleteClass удаляет весовую категорию соревнования.
+func (r *WeighInRepository) DeleteClass(ctx context.Context, competitionID, id int) error {
+	result, err := r.db.ExecContext(ctx,
+		"DELETE FROM weight_classes WHERE id = $1 AND competition_id = $2", id, competitionID,
+	)
+	if err != nil {
+		return dbError("ошибка при удалении весовой категории", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return NotFound("весовая категория с ID %d не найдена", id)
+	}
+	return nil
+}
+
+// scanWeightClass читает одну строку таблицы weight_classes.
+func scanWeightClass(row rowScanner) (*WeightClass, error) {
+	var (
+		wc        WeightClass
+		maxWeight sql.NullFloat64
+	)
+	if err := row.Scan(&wc.ID, &wc.CompetitionID, &wc.Gender, &wc.Name, &wc.MinWeight, &maxWeight); err != nil {
+		return nil, err
+	}
+	if maxWeight.Valid {
+		wc.MaxWeight = &maxWeight.Float64
+	}
+	return &wc, nil
+}
+
+// --- ПРАВИЛА ВЗВЕШИВАНИЯ ---
+
+// GetRules возвращает правила взвешивания; если они не заданы — строгие правила по умолчанию.
+func (r *WeighInRepository) GetRules(ctx context.Context, competitionID int) (*WeighInRules, error) {
+	rules := &WeighInRules{CompetitionID: competitionID, OverweightAction: "disqualify"}
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT tolerance_kg, reweigh_window_minutes, overweight_action
+		FROM weigh_in_rules
+		WHERE competition_id = $1`,
+		competitionID,
+	).Scan(&rules.ToleranceKg, &rules.ReweighWindowMinutes, &rules.OverweightAction)
+
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("repo: ошибка при получении правил взвешивания: %w", err)
+	}
+	return rules, nil
+}
+
+// SaveRules создает или обновляет правила взвешивания соревнования.
+func (r *WeighInRepository) SaveRules(ctx context.Context, rules *WeighInRules) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO weigh_in_rules (competition_id, tolerance_kg, reweigh_window_minutes, overweight_action)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (competition_id) DO UPDATE
+		SET tolerance_kg = EXCLUDED.tolerance_kg,
+		    reweigh_window_minutes = EXCLUDED.reweigh_window_minutes,
+		    overweight_action = EXCLUDED.overweight_action`,
+		rules.CompetitionID, rules.ToleranceKg, rules.ReweighWindowMinutes, rules.OverweightAction,
+	)
+	if err != nil {
+		return fmt.Errorf("repo: не удалось сохранить правила взвешивания: %w", err)
+	}
+	return nil
+}
+
+// --- ПРОТОКОЛ ВЗВЕШИВАНИЯ ---
+
+// ListWeighIns возвращает все попытки взвешивания участника в хронологическом порядке.
+func (r *WeighInRepository) ListWeighIns(ctx context.Context, participationID int) ([]WeighIn, error) {
+	query := `
+		SELECT id, participation_id, COALESCE(weight_class_id, 0), measured_weight, measured_at,
+		       official, outcome, COALESCE(notes, '')
+		FROM weigh_ins
+		WHERE participation_id = $1
+		ORDER BY measured_at ASC, id ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, participationID)
+	if err != nil {
+		return nil, fmt.Errorf("repo: ошибка при получении протокола взвешивания: %w", err)
+	}
+	defer rows.Close()
+
+	weighIns := make([]WeighIn, 0)
+	for rows.Next() {
+		var wi WeighIn
+		err := rows.Scan(
+			&wi.ID, &wi.ParticipationID, &wi.WeightClassID, &wi.MeasuredWeight, &wi.MeasuredAt,
+			&wi.Official, &wi.Outcome, &wi.Notes,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("repo: ошибка сканирования взвешивания: %w", err)
+		}
+		weighIns = append(weighIns, wi)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repo: ошибка итерации строк: %w", err)
+	}
+	return weighIns, nil
+}
+
+// RecordWeighIn сохраняет попытку взвешивания и обновляет категорию и статус участника
+// в одной транзакции, чтобы протокол и допуск не расходились.
+// classID и attempts — категория участника и число его попыток, по которым сервис вычислил исход:
+// запись участника блокируется, и если взвешивание уже завершено или эти данные успели измениться
+// (параллельная попытка), возвращается конфликт.
+func (r *WeighInRepository) RecordWeighIn(ctx context.Context, wi *WeighIn, classID, attempts int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("repo: не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback()
+
+	var (
+		status          string
+		currentClass    int
+		currentAttempts int
+	)
+	err = tx.QueryRowContext(ctx, `
+		SELECT p.weigh_in_status, COALESCE(p.weight_class_id, 0),
+		       (SELECT COUNT(*) FROM weigh_ins w WHERE w.participation_id = p.id)
+		FROM participations p
+		WHERE p.id = $1 AND p.deleted_at IS NULL
+		FOR UPDATE`,
+		wi.ParticipationID,
+	).Scan(&status, &currentClass, &currentAttempts)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return NotFound("запись об участии с ID %d не найдена", wi.ParticipationID)
+		}
+		return fmt.Errorf("repo: ошибка при чтении статуса взвешивания: %w", err)
+	}
+	switch status {
+	case "passed", "moved", "disqualified":
+		return Conflict("взвешивание участника уже завершено (%s)", status)
+	}
+	if currentClass != classID || currentAttempts != attempts {
+		return Conflict("данные взвешивания участника изменились, повторите попытку")
+	}
+
+	before, err := snapshot(ctx, tx, "participations", "id", wi.ParticipationID)
+	if err != nil {
+		return err
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO weigh_ins (participation_id, weight_class_id, measured_weight, official, outcome, notes)
+		VALUES ($1, NULLIF($2, 0), $3, $4, $5, NULLIF($6, ''))
+		RETURNING id, measured_at`,
+		wi.ParticipationID, wi.WeightClassID, wi.MeasuredWeight, wi.Official, wi.Outcome, wi.Notes,
+	).Scan(&wi.ID, &wi.MeasuredAt)
+	if err != nil {
+		return dbError("не удалось сохранить взвешивание", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE participations
+		SET weigh_in_status = $2, weight_class_id = COALESCE(NULLIF($3, 0), weight_class_id)
+		WHERE id = $1`,
+		wi.ParticipationID, wi.Outcome, wi.WeightClassID,
+	)
+	if err != nil {
+		return fmt.Errorf("repo: не удалось обновить статус участника: %w", err)
+	}
+	if err := recordChange(ctx, tx, "participation", "participations", wi.ParticipationID, "weigh_in", before); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("repo: ошибка фиксации транзакции: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strings"
+	"time"
+
+	"sport-manager/internal/auth"
+	"sport-manager/internal/repository"
+)
+
+// CreatedAPIKey — новый ключ вместе с секретом. Секрет возвращается только один раз.
+type CreatedAPIKey struct {
+	*repository.APIKey
+	Key string `json:"api_key"`
+}
+
+// APIKeyService выпускает и отзывает API-ключи для систем хронометража и табло.
+type APIKeyService struct {
+	repo  *repository.APIKeyRepository
+	audit *repository.AuditRepository
+}
+
+// NewAPIKeyService создает новый экземпляр сервиса API-ключей.
+func NewAPIKeyService(repo *repository.APIKeyRepository, audit *repository.AuditRepository) *APIKeyService {
+	return &APIKeyService{repo: repo, audit: audit}
+}
+
+// List возвращает все ключи, включая отозванные и просроченные (без секретов).
+func (s *APIKeyService) List(ctx context.Context) ([]repository.APIKey, error) {
+	return s.repo.List(ctx)
+}
+
+// Create выпускает ключ с ограниченным набором прав в области (например, только
+// result:enter на соревнование 7). Ключу нельзя выдать право, которого нет у создателя,
+// а управление пользователями и ролями ключам недоступно.
+func (s *APIKeyService) Create(ctx context.Context, actor *auth.Claims, ip string, k *repository.APIKey) (*CreatedAPIKey, error) {
+	k.Name = strings.TrimSpace(k.Name)
+	var v validator
+	v.Required("name", k.Name)
+	v.MaxLen("name", k.Name, 100)
+	v.Check(len(k.Permissions) > 0, "permissions", CodeRequired, "укажите хотя бы одно право")
+
+	scope := auth.Scope{}
+	switch k.ScopeType {
+	case auth.ScopeGlobal:
+		k.ScopeID = 0
+	case auth.ScopeCompetition, auth.ScopeClub:
+		v.Check(k.ScopeID > 0, "scope_id", CodeRequired, "для области '%s' обязателен scope_id", k.ScopeType)
+		if k.ScopeType == auth.ScopeCompetition {
+			scope.CompetitionID = k.ScopeID
+		} else {
+			scope.ClubID = k.ScopeID
+		}
+	default:
+		v.Add("scope_type", CodeEnum, "неизвестная область '%s'", k.ScopeType)
+	}
+
+	for _, p := range k.Permissions {
+		perm := auth.Permission(p)
+		if !auth.IsValidPermission(perm) {
+			v.Add("permissions", CodeEnum, "неизвестное право '%s'", p)
+		}
+		if perm == auth.PermUserManage || perm == auth.PermRoleAssign {
+			v.Add("permissions", CodeInvalid, "право '%s' нельзя выдать API-ключу", p)
+		}
+	}
+	if k.ExpiresAt != nil {
+		v.Check(k.ExpiresAt.After(time.Now()), "expires_at", CodeOutOfRange, "срок действия ключа должен быть в будущем")
+	}
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+
+	// Право, которого нет у создателя, ключу выдать нельзя
+	for _, p := range k.Permissions {
+		if !actor.HasPermission(auth.Permission(p), scope) {
+			return nil, forbidden("нельзя выдать ключу право %s, которого нет у вас", p)
+		}
+	}
+
+	key, prefix, hash, err := auth.NewAPIKey()
+	if err != nil {
+		return nil, err
+	}
+	k.Prefix = prefix
+	k.KeyHash = hash
+	k.CreatedBy = actor.Username
+	if err := s.repo.Create(ctx, k); err != nil {
+		return nil, err
+	}
+
+	s.record(ctx, actor.Username, ip, k.ID, "create", k)
+	return &CreatedAPIKey{APIKey: k, Key: key}, nil
+}
+
+// Revoke отзывает ключ: следующие запросы с ним получат 401.
+func (s *APIKeyService) Revoke(ctx context.Context, actor *auth.Claims, ip string, id int) error {
+	if err := s.repo.Revoke(ctx, id); err != nil {
+		return err
+	}
+	s.record(ctx, actor.Username, ip, id, "revoke", nil)
+	return nil
+}
+
+// record пишет событие в журнал аудита; сбой аудита не отменяет действие.
+func (s *APIKeyService) record(ctx context.Context, actor, ip string, id int, action string, after interface{}) {
+	entry := &repository.AuditEntry{Username: actor, IP: ip, Entity: "api_key", EntityID: id, Action: action}
+	if after != nil {
+		entry.After, _ = json.Marshal(after)
+	}
+	if err := s.audit.Record(ctx, entry); err != nil {
+		log.Printf("Ошибка записи аудита: %v", err)
+	}
+}
@@ -2,9 +2,10 @@ package service
 
 import (
 	"context"
-	"errors"
+	"encoding/json"
 	"fmt"
 	"sport-manager/internal/repository"
+	"time"
 )
 
 // AthleteService реализует бизнес-логику для работы со спортсменами.
@@ -21,9 +22,8 @@ func NewAthleteService(repo *repository.AthleteRepository) *AthleteService {
 
 // CreateAthlete проверяет данные и создает нового спортсмена.
 func (s *AthleteService) CreateAthlete(ctx context.Context, athlete *repository.Athlete) error {
-	// Валидация: имя не может быть пустым
-	if athlete.FullName == "" {
-		return errors.New("валидация: ФИО спортсмена обязательно для заполнения")
+	if err := validateAthlete(athlete); err != nil {
+		return err
 	}
 
 	// Вызов слоя данных
@@ -33,22 +33,47 @@ func (s *AthleteService) CreateAthlete(ctx context.Context, athlete *repository.
 	return nil
 }
 
-// ListAll возвращает список всех спортсменов.
-func (s *AthleteService) ListAll(ctx context.Context) ([]repository.Athlete, error) {
-	athletes, err := s.repo.ListAll(ctx)
+// List возвращает страницу списка спортсменов по фильтру (по умолчанию — по ID).
+func (s *AthleteService) List(ctx context.Context, f repository.AthleteFilter, req PageRequest) ([]repository.Athlete, *repository.PageInfo, error) {
+	v := &validator{}
+	if f.Gender != "" {
+		v.OneOf("gender", f.Gender, genders...)
+	}
+	v.Check(f.BirthYearFrom == 0 || f.BirthYearTo == 0 || f.BirthYearFrom <= f.BirthYearTo,
+		"birth_year_from", CodeOutOfRange, "нижняя граница не может быть больше верхней")
+	page := pageFor(req, s.repo.SortFields(), "id", v)
+	if err := v.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	athletes, info, err := s.repo.List(ctx, f, page)
+	if err != nil {
+		return nil, nil, fmt.Errorf("service: ошибка получения списка: %w", err)
+	}
+	return athletes, info, nil
+}
+
+// GetByID возвращает данные конкретного спортсмена по ID; удаленного — только при includeDeleted.
+func (s *AthleteService) GetByID(ctx context.Context, id int, includeDeleted bool) (*repository.Athlete, error) {
+	if id <= 0 {
+		return nil, invalidField("id", CodeInvalid, "некорректный ID спортсмена")
+	}
+
+	athlete, err := s.repo.Find(ctx, id, includeDeleted)
 	if err != nil {
-		return nil, fmt.Errorf("service: ошибка получения списка: %w", err)
+		return nil, fmt.Errorf("service: ошибка при поиске атлета: %w", err)
 	}
-	return athletes, nil
+	return athlete, nil
 }
 
-// GetByID возвращает данные конкретного спортсмена по ID.
-func (s *AthleteService) GetByID(ctx context.Context, id int) (*repository.Athlete, error) {
+// GetAsOf возвращает спортсмена в том виде, в каком он был на момент at
+// (например, чтобы проверить возрастную категорию на дату прошедшего соревнования).
+func (s *AthleteService) GetAsOf(ctx context.Context, id int, at time.Time) (*repository.Athlete, error) {
 	if id <= 0 {
-		return nil, errors.New("валидация: некорректный ID спортсмена")
+		return nil, invalidField("id", CodeInvalid, "некорректный ID спортсмена")
 	}
 
-	athlete, err := s.repo.GetByID(ctx, id)
+	athlete, err := s.repo.FindAsOf(ctx, id, at)
 	if err != nil {
 		return nil, fmt.Errorf("service: ошибка при поиске атлета: %w", err)
 	}
@@ -56,28 +81,114 @@ func (s *AthleteService) GetByID(ctx context.Context, id int) (*repository.Athle
 }
 
 // Update обновляет информацию о спортсмене, предварительно проверяя данные.
-func (s *AthleteService) Update(ctx context.Context, athlete *repository.Athlete) error {
+// expectedVersion — версия из If-Match (repository.AnyVersion — без проверки).
+func (s *AthleteService) Update(ctx context.Context, athlete *repository.Athlete, expectedVersion int) error {
 	if athlete.ID <= 0 {
-		return errors.New("валидация: ID спортсмена обязателен для обновления")
+		return invalidField("id", CodeRequired, "ID спортсмена обязателен для обновления")
 	}
-	if athlete.FullName == "" {
-		return errors.New("валидация: ФИО не может быть пустым")
+	if err := validateAthlete(athlete); err != nil {
+		return err
 	}
 
-	if err := s.repo.Update(ctx, athlete); err != nil {
+	if err := s.repo.Update(ctx, athlete, expectedVersion); err != nil {
 		return fmt.Errorf("service: не удалось обновить данные: %w", err)
 	}
 	return nil
 }
 
-// Delete удаляет спортсмена из системы.
-func (s *AthleteService) Delete(ctx context.Context, id int) error {
+// Patch применяет к спортсмену JSON Merge Patch (RFC 7396): меняются только переданные поля.
+// Каждое поле проверяется отдельно; id, version и deleted_at менять нельзя.
+func (s *AthleteService) Patch(ctx context.Context, id int, fields map[string]json.RawMessage, expectedVersion int) (*repository.Athlete, error) {
+	if id <= 0 {
+		return nil, invalidField("id", CodeInvalid, "некорректный ID спортсмена")
+	}
+
+	p := newMergePatch(fields, "id", "version", "deleted_at")
+	patch := repository.AthletePatch{
+		FullName:  p.String("full_name", false),
+		BirthDate: p.Date("birth_date"),
+		Gender:    p.String("gender", false),
+		IsActive:  p.Bool("is_active"),
+		Address:   p.String("address", true),
+		ClubID:    p.Int("club_id", true),
+	}
+	if patch.FullName != nil {
+		checkFullName(&p.validator, *patch.FullName)
+	}
+	if patch.BirthDate != nil {
+		p.DateBetween("birth_date", *patch.BirthDate, minBirthDate, time.Now())
+	}
+	if patch.Gender != nil {
+		p.OneOf("gender", *patch.Gender, genders...)
+	}
+	if patch.Address != nil {
+		p.MaxLen("address", *patch.Address, 255)
+	}
+	if patch.ClubID != nil {
+		p.NotNegative("club_id", float64(*patch.ClubID))
+	}
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+
+	athlete, err := s.repo.Patch(ctx, id, patch, expectedVersion)
+	if err != nil {
+		return nil, fmt.Errorf("service: не удалось обновить данные: %w", err)
+	}
+	return athlete, nil
+}
+
+// validateAthlete проверяет все поля спортсмена при создании и полном обновлении.
+func validateAthlete(a *repository.Athlete) error {
+	var v validator
+	checkFullName(&v, a.FullName)
+	v.RequiredDate("birth_date", a.BirthDate)
+	v.DateBetween("birth_date", a.BirthDate, minBirthDate, time.Now())
+	v.Required("gender", a.Gender)
+	v.OneOf("gender", a.Gender, genders...)
+	v.MaxLen("address", a.Address, 255)
+	v.NotNegative("club_id", float64(a.ClubID))
+	return v.Err()
+}
+
+// checkFullName — правила для ФИО: обязательно, не длиннее столбца full_name.
+func checkFullName(v *validator, name string) {
+	v.Required("full_name", name)
+	v.MaxLen("full_name", name, 255)
+}
+
+// Delete мягко удаляет спортсмена: его участия и результаты сохраняются и возвращаются при восстановлении.
+func (s *AthleteService) Delete(ctx context.Context, id, expectedVersion int) error {
 	if id <= 0 {
-		return errors.New("валидация: некорректный ID для удаления")
+		return invalidField("id", CodeInvalid, "некорректный ID для удаления")
 	}
 
-	if err := s.repo.Delete(ctx, id); err != nil {
+	if err := s.repo.Delete(ctx, id, expectedVersion); err != nil {
 		return fmt.Errorf("service: ошибка при удалении: %w", err)
 	}
 	return nil
 }
+
+// Restore восстанавливает мягко удаленного спортсмена.
+func (s *AthleteService) Restore(ctx context.Context, id int) error {
+	if id <= 0 {
+		return invalidField("id", CodeInvalid, "некорректный ID спортсмена")
+	}
+
+	if err := s.repo.Restore(ctx, id); err != nil {
+		return fmt.Errorf("service: ошибка при восстановлении: %w", err)
+	}
+	return nil
+}
+
+// Purge безвозвратно удаляет ранее удаленного спортсмена вместе с участиями и результатами.
+func (s *AthleteService) Purge(ctx context.Context, id int) error {
+	if id <= 0 {
+		return invalidField("id", CodeInvalid, "некорректный ID спортсмена")
+	}
+
+	if err := s.repo.Purge(ctx, id); err != nil {
+		return fmt.Errorf("service: ошибка при окончательном удалении: %w", err)
+	}
+	return nil
+}
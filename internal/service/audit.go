@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+
+	"sport-manager/internal/repository"
+)
+
+// Ограничения размера выборки журнала аудита
+const (
+	auditDefaultLimit = 100
+	auditMaxLimit     = 1000
+)
+
+// AuditService предоставляет просмотр журнала аудита и проверку его целостности.
+// Сами записи пишут репозитории в транзакциях изменений.
+type AuditService struct {
+	repo *repository.AuditRepository
+}
+
+// NewAuditService создает новый экземпляр сервиса аудита.
+func NewAuditService(repo *repository.AuditRepository) *AuditService {
+	return &AuditService{repo: repo}
+}
+
+// List возвращает записи журнала по фильтру (сначала новые).
+func (s *AuditService) List(ctx context.Context, f repository.AuditFilter) ([]repository.AuditEntry, error) {
+	if !f.From.IsZero() && !f.To.IsZero() && !f.From.Before(f.To) {
+		return nil, invalidField("from", CodeOutOfRange, "начало периода должно быть раньше конца")
+	}
+	if f.Limit <= 0 {
+		f.Limit = auditDefaultLimit
+	}
+	if f.Limit > auditMaxLimit {
+		f.Limit = auditMaxLimit
+	}
+	return s.repo.List(ctx, f)
+}
+
+// Verify проверяет цепочку хешей: изменение или удаление записи в обход приложения
+// делает цепочку недействительной начиная с поврежденной записи.
+func (s *AuditService) Verify(ctx context.Context) (*repository.AuditVerification, error) {
+	return s.repo.Verify(ctx)
+}
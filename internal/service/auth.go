@@ -2,74 +2,457 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"sport-manager/internal/auth"
 	"sport-manager/internal/repository"
 	"sport-manager/pkg/config"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
+// ErrUnauthorized возвращается, когда личность пользователя не подтверждена:
+// неверные данные второго шага входа, истекший или уже завершенный вход.
+var ErrUnauthorized = errors.New("требуется аутентификация")
+
+// unauthorized возвращает ErrUnauthorized с пояснением для клиента.
+func unauthorized(format string, args ...interface{}) error {
+	return &repository.DomainError{Kind: ErrUnauthorized, Format: format, Args: args}
+}
+
+// TokenPair — результат успешного входа или продления сессии.
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"` // Время жизни access-токена в секундах
+	// MustChangePassword — до смены пароля токен пропускает только /me, /me/password и выход
+	MustChangePassword bool `json:"must_change_password"`
+	// MFASetupRequired — политика требует 2FA: до ее подключения доступны только /me/2fa/* и выход
+	MFASetupRequired bool `json:"mfa_setup_required"`
+}
+
+// minPasswordLength — минимальная длина пароля при регистрации и смене.
+const minPasswordLength = 6
+
+// dummyPasswordHash — bcrypt-хеш случайной строки для проверки несуществующих логинов.
+const dummyPasswordHash = "$2a$10$Wrma93PYFDia5FcZRkaSEuIO306h9A.DkF1NKT5GBgahsXbPWx1ia"
+
 // AuthService содержит бизнес-логику управления пользователями и сессиями.
 type AuthService struct {
-	repo *repository.AuthRepository
-	cfg  *config.Config
+	repo      *repository.AuthRepository
+	tokenRepo *repository.TokenRepository
+	roles     *RoleService
+	mail      *AccountMailer
+	guard     *LoginGuard
+	mfa       *MFAService
+	keys      *auth.KeySet
+	cfg       *config.Config
+
+	// Вход через OpenID Connect (oidc == nil — не настроен)
+	oidc       *auth.OIDCProvider
+	identities *repository.IdentityRepository
+	oidcRoles  map[string]string // Группа провайдера → локальная роль
 }
 
 // NewAuthService создает новый экземпляр сервиса аутентификации.
-func NewAuthService(repo *repository.AuthRepository, cfg *config.Config) *AuthService {
-	return &AuthService{repo: repo, cfg: cfg}
+func NewAuthService(
+	repo *repository.AuthRepository,
+	tokenRepo *repository.TokenRepository,
+	roles *RoleService,
+	mail *AccountMailer,
+	guard *LoginGuard,
+	mfa *MFAService,
+	oidc *auth.OIDCProvider,
+	identities *repository.IdentityRepository,
+	keys *auth.KeySet,
+	cfg *config.Config,
+) *AuthService {
+	return &AuthService{
+		repo: repo, tokenRepo: tokenRepo, roles: roles, mail: mail, guard: guard, mfa: mfa, keys: keys, cfg: cfg,
+		oidc: oidc, identities: identities, oidcRoles: parseRoleMapping(cfg.OIDCRoleMapping),
+	}
+}
+
+// JWKS возвращает публичные ключи, которыми можно проверить выданные токены.
+func (s *AuthService) JWKS() auth.JWKS {
+	return s.keys.JWKS()
 }
 
-// Login проверяет учетные данные пользователя и возвращает JWT-токен при успехе.
-func (s *AuthService) Login(ctx context.Context, username, password string) (string, error) {
+// Login проверяет учетные данные пользователя и возвращает пару токенов при успехе.
+// ip — адрес клиента для счетчика неудачных входов. Пока вход заблокирован,
+// возвращается *LockedError, причем одинаково для существующих и несуществующих логинов.
+// Если у пользователя подключена 2FA, вместо токенов возвращается *LoginChallenge:
+// вход завершается вызовом CompleteLogin с кодом второго фактора.
+func (s *AuthService) Login(ctx context.Context, username, password, ip string) (*TokenPair, *LoginChallenge, error) {
 	log.Printf("Попытка входа пользователя: %s", username)
 
-	// 1. Ищем пользователя в базе данных
+	// 1. Проверяем блокировку до проверки пароля, чтобы перебор не продолжался во время нее
+	if err := s.guard.Check(ctx, username, ip); err != nil {
+		log.Printf("Ошибка входа: %s (IP %s) заблокирован", username, ip)
+		return nil, nil, err
+	}
+
+	// 2. Ищем пользователя в базе данных
 	user, err := s.repo.GetByUsername(ctx, username)
 	if err != nil {
+		// Сравнение с фиктивным хешем выравнивает время ответа для несуществующих логинов
+		bcrypt.CompareHashAndPassword([]byte(dummyPasswordHash), []byte(password))
 		log.Printf("Ошибка входа: пользователь %s не найден", username)
-		return "", fmt.Errorf("неверные учетные данные")
+		s.guard.Failure(ctx, username, ip)
+		return nil, nil, fmt.Errorf("неверные учетные данные")
 	}
 
-	// 2. Проверяем соответствие введенного пароля сохраненному хешу
+	// 3. Проверяем соответствие введенного пароля сохраненному хешу
 	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password))
 	if err != nil {
 		log.Printf("Ошибка входа: неверный пароль для %s", username)
-		return "", fmt.Errorf("неверные учетные данные")
+		s.guard.Failure(ctx, username, ip)
+		return nil, nil, fmt.Errorf("неверные учетные данные")
+	}
+	if !user.IsActive {
+		log.Printf("Ошибка входа: аккаунт %s заблокирован", username)
+		return nil, nil, fmt.Errorf("неверные учетные данные")
+	}
+
+	// 4. Со включенной 2FA пароль — только первый шаг. Счетчик неудач не сбрасываем
+	// до ввода кода, иначе перебор кодов не приводил бы к блокировке.
+	if user.TwoFactorEnabled {
+		challenge, err := s.mfa.StartLogin(ctx, user.ID, ip)
+		if err != nil {
+			log.Printf("Ошибка создания входа с 2FA: %v", err)
+			return nil, nil, fmt.Errorf("ошибка сервера при авторизации")
+		}
+		log.Printf("Вход %s: пароль принят, ожидается код 2FA", username)
+		return nil, challenge, nil
 	}
+	s.guard.Success(ctx, username)
 
-	// 3. Генерируем токен доступа на основе данных пользователя
-	token, err := auth.GenerateToken(user, s.cfg)
+	// 5. Каждый вход открывает новое семейство refresh-токенов
+	familyID, err := auth.NewFamilyID()
+	if err != nil {
+		log.Printf("Ошибка генерации семейства токенов: %v", err)
+		return nil, nil, fmt.Errorf("ошибка сервера при авторизации")
+	}
+
+	// 6. Генерируем пару токенов на основе данных пользователя
+	pair, err := s.issueTokens(ctx, user, familyID)
 	if err != nil {
 		log.Printf("Ошибка генерации токена: %v", err)
-		return "", fmt.Errorf("ошибка сервера при авторизации")
+		return nil, nil, fmt.Errorf("ошибка сервера при авторизации")
 	}
 
 	log.Printf("Успешный вход: %s (Роль: %s)", username, user.Role)
-	return token, nil
+	return pair, nil, nil
 }
 
-// Register выполняет безопасную регистрацию нового пользователя.
-func (s *AuthService) Register(ctx context.Context, username, email, password string) error {
-	if len(password) < 6 {
-		return fmt.Errorf("пароль слишком короткий (минимум 6 символов)")
+// CompleteLogin завершает вход с 2FA: проверяет код из приложения или резервный код
+// по токену, выданному Login. Неверные коды учитываются в счетчике блокировки входа.
+func (s *AuthService) CompleteLogin(ctx context.Context, challengeToken, code, ip string) (*TokenPair, error) {
+	challenge, err := s.mfa.PendingLogin(ctx, challengeToken)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.repo.GetByID(ctx, challenge.UserID)
+	if err != nil || !user.IsActive {
+		return nil, unauthorized("неверные учетные данные")
+	}
+	if err := s.guard.Check(ctx, user.Username, ip); err != nil {
+		return nil, err
+	}
+
+	ok, err := s.mfa.Verify(ctx, user.ID, code)
+	if err != nil {
+		log.Printf("Ошибка проверки кода 2FA: %v", err)
+		return nil, fmt.Errorf("ошибка сервера при авторизации")
+	}
+	if !ok {
+		log.Printf("Ошибка входа: неверный код 2FA для %s", user.Username)
+		s.mfa.FailLogin(ctx, challenge)
+		s.guard.Failure(ctx, user.Username, ip)
+		return nil, unauthorized("неверный код подтверждения")
+	}
+
+	// Токен второго шага одноразовый: из двух параллельных запросов пройдет только один
+	if ok, err := s.mfa.FinishLogin(ctx, challenge); err != nil || !ok {
+		return nil, unauthorized("вход уже завершен, начните заново")
+	}
+	s.guard.Success(ctx, user.Username)
+
+	familyID, err := auth.NewFamilyID()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка сервера при авторизации")
+	}
+	pair, err := s.issueTokens(ctx, user, familyID)
+	if err != nil {
+		log.Printf("Ошибка генерации токена: %v", err)
+		return nil, fmt.Errorf("ошибка сервера при авторизации")
+	}
+
+	log.Printf("Успешный вход с 2FA: %s (Роль: %s)", user.Username, user.Role)
+	return pair, nil
+}
+
+// Refresh обменивает refresh-токен на новую пару токенов (ротация).
+// Повторное предъявление уже обмененного токена означает его кражу:
+// в этом случае отзывается все семейство, и владелец должен войти заново.
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	stored, err := s.tokenRepo.GetRefreshToken(ctx, auth.HashToken(refreshToken))
+	if err != nil {
+		return nil, fmt.Errorf("недействительный refresh-токен")
+	}
+
+	if stored.RevokedAt != nil {
+		return nil, fmt.Errorf("refresh-токен отозван")
+	}
+	if stored.UsedAt != nil {
+		log.Printf("ВНИМАНИЕ: повторное использование refresh-токена (пользователь %d), семейство отозвано", stored.UserID)
+		if err := s.tokenRepo.RevokeFamily(ctx, stored.FamilyID); err != nil {
+			log.Printf("Ошибка отзыва семейства токенов: %v", err)
+		}
+		return nil, fmt.Errorf("refresh-токен уже использован")
+	}
+	if time.Now().After(stored.ExpiresAt) {
+		return nil, fmt.Errorf("refresh-токен просрочен")
+	}
+
+	// Помечаем токен использованным атомарно: из двух параллельных обменов пройдет только один
+	ok, err := s.tokenRepo.MarkRefreshTokenUsed(ctx, stored.ID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка сервера при продлении сессии")
+	}
+	if !ok {
+		if err := s.tokenRepo.RevokeFamily(ctx, stored.FamilyID); err != nil {
+			log.Printf("Ошибка отзыва семейства токенов: %v", err)
+		}
+		return nil, fmt.Errorf("refresh-токен уже использован")
+	}
+
+	// Роль перечитываем из базы, чтобы изменения прав вступали в силу при продлении
+	user, err := s.repo.GetByID(ctx, stored.UserID)
+	if err != nil || !user.IsActive {
+		return nil, fmt.Errorf("недействительный refresh-токен")
+	}
+
+	return s.issueTokens(ctx, user, stored.FamilyID)
+}
+
+// Logout завершает сессию: access-токен попадает в denylist до истечения срока,
+// а семейство переданного refresh-токена отзывается.
+func (s *AuthService) Logout(ctx context.Context, claims *auth.Claims, refreshToken string) error {
+	if claims != nil && claims.ID != "" && claims.ExpiresAt != nil {
+		if err := s.tokenRepo.RevokeAccessToken(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+			return fmt.Errorf("service: %w", err)
+		}
+	}
+
+	if refreshToken != "" {
+		stored, err := s.tokenRepo.GetRefreshToken(ctx, auth.HashToken(refreshToken))
+		if err != nil {
+			return nil // Неизвестный токен — выходить уже не из чего
+		}
+		// Нельзя завершить чужую сессию, предъявив ее refresh-токен
+		if claims != nil && stored.UserID != claims.UserID {
+			return fmt.Errorf("refresh-токен принадлежит другому пользователю")
+		}
+		if err := s.tokenRepo.RevokeFamily(ctx, stored.FamilyID); err != nil {
+			return fmt.Errorf("service: %w", err)
+		}
+	}
+	return nil
+}
+
+// issueTokens выпускает access-токен и новый refresh-токен в указанном семействе.
+func (s *AuthService) issueTokens(ctx context.Context, user *repository.User, familyID string) (*TokenPair, error) {
+	// Роли с областью действия попадают в токен и перечитываются при каждом продлении
+	grants, err := s.roles.Grants(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Пока обязательная по политике 2FA не подключена, токен годится только для ее подключения
+	mfaSetup := false
+	if !user.TwoFactorEnabled {
+		if mfaSetup, err = s.mfa.Required(ctx, user, grants); err != nil {
+			return nil, err
+		}
+	}
+
+	accessToken, err := auth.GenerateToken(user, grants, mfaSetup, s.keys, s.cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, hash, err := auth.NewRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	err = s.tokenRepo.CreateRefreshToken(ctx, &repository.RefreshToken{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(s.cfg.RefreshTokenDuration),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:        accessToken,
+		RefreshToken:       refreshToken,
+		TokenType:          "Bearer",
+		ExpiresIn:          int(s.cfg.JWTExpirationDuration.Seconds()),
+		MustChangePassword: user.MustChangePassword,
+		MFASetupRequired:   mfaSetup,
+	}, nil
+}
+
+// ChangePassword меняет пароль после подтверждения текущего.
+// Все прочие сессии пользователя завершаются, а вызывающему выдается новая пара токенов
+// (в том числе без флага принудительной смены пароля).
+func (s *AuthService) ChangePassword(ctx context.Context, claims *auth.Claims, currentPassword, newPassword string) (*TokenPair, error) {
+	user, err := s.repo.GetByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(currentPassword)); err != nil {
+		return nil, invalidField("current_password", CodeInvalid, "текущий пароль указан неверно")
+	}
+	if currentPassword == newPassword {
+		return nil, invalidField("new_password", CodeInvalid, "новый пароль должен отличаться от текущего")
+	}
+
+	hash, err := hashPassword(newPassword)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdatePassword(ctx, user.ID, hash, false); err != nil {
+		return nil, err
+	}
+	user.MustChangePassword = false
+
+	// Старые сессии больше не действительны: refresh-токены отзываются, текущий access — в denylist
+	if err := s.tokenRepo.RevokeUserTokens(ctx, user.ID); err != nil {
+		return nil, err
+	}
+	if err := s.Logout(ctx, claims, ""); err != nil {
+		return nil, err
+	}
+
+	familyID, err := auth.NewFamilyID()
+	if err != nil {
+		return nil, err
+	}
+	return s.issueTokens(ctx, user, familyID)
+}
+
+// hashPassword проверяет длину пароля и возвращает его bcrypt-хеш.
+func hashPassword(password string) (string, error) {
+	if len(password) < minPasswordLength {
+		return "", invalidField("password", CodeOutOfRange, "пароль слишком короткий (минимум %d символов)", minPasswordLength)
 	}
 
-	// Хешируем пароль перед сохранением в базу данных.
 	// Никогда не храните пароли в открытом виде!
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
-		return fmt.Errorf("ошибка при обработке пароля: %w", err)
+		return "", fmt.Errorf("ошибка при обработке пароля: %w", err)
+	}
+	return string(hash), nil
+}
+
+// Register выполняет безопасную регистрацию нового пользователя и отправляет письмо
+// для подтверждения email. До подтверждения аккаунт доступен только для просмотра.
+func (s *AuthService) Register(ctx context.Context, username, email, password, language string) error {
+	var v validator
+	v.Required("username", username)
+	v.MaxLen("username", username, 50)
+	checkEmail(&v, email)
+	v.Check(len(password) >= minPasswordLength, "password", CodeOutOfRange,
+		"пароль слишком короткий (минимум %d символов)", minPasswordLength)
+	if err := v.Err(); err != nil {
+		return err
+	}
+
+	// Хешируем пароль перед сохранением в базу данных.
+	hashedPassword, err := hashPassword(password)
+	if err != nil {
+		return err
 	}
 
 	user := &repository.User{
 		Username:     username,
 		Email:        email,
-		PasswordHash: string(hashedPassword),
-		Role:         "user", // Роль по умолчанию
+		PasswordHash: hashedPassword,
+		Role:         auth.RoleViewer, // Роль по умолчанию: только просмотр
+		Language:     language,
+	}
+
+	if err := s.repo.CreateUser(ctx, user); err != nil {
+		return err
+	}
+
+	// Сбой почты не отменяет регистрацию: письмо можно запросить повторно
+	if err := s.mail.SendVerification(ctx, user); err != nil {
+		log.Printf("Ошибка отправки письма подтверждения для %s: %v", username, err)
+	}
+	return nil
+}
+
+// VerifyEmail подтверждает адрес по токену из письма.
+func (s *AuthService) VerifyEmail(ctx context.Context, token string) error {
+	userID, err := s.tokenRepo.ConsumeEmailToken(ctx, repository.EmailTokenVerify, auth.HashToken(token))
+	if err != nil {
+		return err
+	}
+	return s.repo.SetEmailVerified(ctx, userID)
+}
+
+// ResendVerification повторно отправляет письмо для подтверждения email текущему пользователю.
+func (s *AuthService) ResendVerification(ctx context.Context, claims *auth.Claims) error {
+	user, err := s.repo.GetByID(ctx, claims.UserID)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerified {
+		return repository.Conflict("адрес электронной почты уже подтвержден")
+	}
+	return s.mail.SendVerification(ctx, user)
+}
+
+// RequestPasswordReset отправляет ссылку для сброса пароля.
+// Ответ не зависит от того, существует ли адрес: так нельзя выяснить, кто зарегистрирован.
+func (s *AuthService) RequestPasswordReset(ctx context.Context, email string) {
+	user, err := s.repo.GetByEmail(ctx, email)
+	if err != nil || !user.IsActive {
+		log.Printf("Сброс пароля: активный пользователь с email %s не найден", email)
+		return
 	}
+	if err := s.mail.SendPasswordReset(ctx, user); err != nil {
+		log.Printf("Ошибка отправки письма сброса пароля для %s: %v", user.Username, err)
+	}
+}
 
-	return s.repo.CreateUser(ctx, user)
+// ResetPassword задает новый пароль по одноразовому токену из письма.
+// Все сессии пользователя завершаются; переход по ссылке заодно подтверждает email.
+func (s *AuthService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	// Пароль проверяем до погашения токена, чтобы ошибка ввода не сжигала ссылку
+	hash, err := hashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	userID, err := s.tokenRepo.ConsumeEmailToken(ctx, repository.EmailTokenReset, auth.HashToken(token))
+	if err != nil {
+		return err
+	}
+	if err := s.repo.UpdatePassword(ctx, userID, hash, false); err != nil {
+		return err
+	}
+	if err := s.repo.SetEmailVerified(ctx, userID); err != nil {
+		return err
+	}
+	return s.tokenRepo.RevokeUserTokens(ctx, userID)
 }
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"context"
+
+	"sport-manager/internal/repository"
+)
+
+// ClubService реализует бизнес-логику работы со спортивными клубами.
+type ClubService struct {
+	repo *repository.ClubRepository
+}
+
+// NewClubService создает новый экземпляр сервиса клубов.
+func NewClubService(repo *repository.ClubRepository) *ClubService {
+	return &ClubService{repo: repo}
+}
+
+// Create проверяет данные и добавляет новый клуб.
+func (s *ClubService) Create(ctx context.Context, c *repository.Club) error {
+	var v validator
+	v.Required("name", c.Name)
+	v.MaxLen("name", c.Name, 255)
+	v.MaxLen("city", c.City, 150)
+	if err := v.Err(); err != nil {
+		return err
+	}
+	return s.repo.Create(ctx, c)
+}
+
+// ListAll возвращает список всех клубов.
+func (s *ClubService) ListAll(ctx context.Context) ([]repository.Club, error) {
+	return s.repo.ListAll(ctx)
+}
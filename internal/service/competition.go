@@ -2,7 +2,7 @@ package service
 
 import (
 	"context"
-	"fmt"
+	"encoding/json"
 	"time"
 
 	"sport-manager/internal/repository"
@@ -10,66 +10,191 @@ import (
 
 // CompetitionService реализует бизнес-логику управления спортивными мероприятиями.
 type CompetitionService struct {
-	repo *repository.CompetitionRepository
+	repo      *repository.CompetitionRepository
+	entryRepo *repository.EntryStandardRepository
 }
 
 // NewCompetitionService создает новый экземпляр сервиса соревнований.
-func NewCompetitionService(repo *repository.CompetitionRepository) *CompetitionService {
-	return &CompetitionService{repo: repo}
+func NewCompetitionService(repo *repository.CompetitionRepository, entryRepo *repository.EntryStandardRepository) *CompetitionService {
+	return &CompetitionService{repo: repo, entryRepo: entryRepo}
 }
 
 // --- МЕТОДЫ БИЗНЕС-ЛОГИКИ ---
 
 // Create выполняет валидацию данных перед регистрацией нового соревнования.
 func (s *CompetitionService) Create(ctx context.Context, c *repository.Competition) error {
-	// Валидация обязательных полей
-	if len(c.Name) == 0 {
-		return fmt.Errorf("ошибка валидации: название соревнования обязательно")
-	}
-	if len(c.Location) == 0 {
-		return fmt.Errorf("ошибка валидации: место проведения обязательно")
-	}
-	if c.StartDate.IsZero() {
-		return fmt.Errorf("ошибка валидации: дата начала обязательна")
-	}
-
 	// Бизнес-правило: соревнование не может быть создано на прошедшую дату
-	if c.StartDate.Before(time.Now().Truncate(24 * time.Hour)) {
-		return fmt.Errorf("ошибка валидации: дата начала не может быть в прошлом")
+	if err := validateCompetition(c, time.Now()); err != nil {
+		return err
 	}
 
 	return s.repo.Create(ctx, c)
 }
 
-// GetByID возвращает детальную информацию о соревновании.
-func (s *CompetitionService) GetByID(ctx context.Context, id int) (*repository.Competition, error) {
+// GetByID возвращает детальную информацию о соревновании; об удаленном — только при includeDeleted.
+func (s *CompetitionService) GetByID(ctx context.Context, id int, includeDeleted bool) (*repository.Competition, error) {
 	if id <= 0 {
-		return nil, fmt.Errorf("ошибка: некорректный ID соревнования")
+		return nil, invalidField("id", CodeInvalid, "некорректный ID соревнования")
 	}
-	return s.repo.GetByID(ctx, id)
+	return s.repo.Find(ctx, id, includeDeleted)
 }
 
-// ListAll возвращает полный перечень запланированных мероприятий.
-func (s *CompetitionService) ListAll(ctx context.Context) ([]repository.Competition, error) {
-	return s.repo.ListAll(ctx)
+// GetAsOf возвращает соревнование в том виде, в каком оно было на момент at.
+func (s *CompetitionService) GetAsOf(ctx context.Context, id int, at time.Time) (*repository.Competition, error) {
+	if id <= 0 {
+		return nil, invalidField("id", CodeInvalid, "некорректный ID соревнования")
+	}
+	return s.repo.FindAsOf(ctx, id, at)
+}
+
+// List возвращает страницу перечня мероприятий по фильтру (по умолчанию — сначала новые).
+func (s *CompetitionService) List(ctx context.Context, f repository.CompetitionFilter, req PageRequest) ([]repository.Competition, *repository.PageInfo, error) {
+	v := &validator{}
+	v.Check(f.DateFrom.IsZero() || f.DateTo.IsZero() || !f.DateFrom.After(f.DateTo),
+		"date_from", CodeOutOfRange, "нижняя граница не может быть больше верхней")
+	page := pageFor(req, s.repo.SortFields(), "-start_date", v)
+	if err := v.Err(); err != nil {
+		return nil, nil, err
+	}
+	return s.repo.List(ctx, f, page)
 }
 
 // Update проверяет обновленные данные перед сохранением в базу.
-func (s *CompetitionService) Update(ctx context.Context, c *repository.Competition) error {
+// expectedVersion — версия из If-Match (repository.AnyVersion — без проверки).
+func (s *CompetitionService) Update(ctx context.Context, c *repository.Competition, expectedVersion int) error {
 	if c.ID <= 0 {
-		return fmt.Errorf("ошибка: для обновления необходим ID записи")
+		return invalidField("id", CodeRequired, "для обновления необходим ID записи")
 	}
-	if len(c.Name) == 0 {
-		return fmt.Errorf("ошибка валидации: название не может быть пустым")
+	// Уже прошедшее соревнование можно исправлять, поэтому нижней границы даты нет
+	if err := validateCompetition(c, minCompetitionDate); err != nil {
+		return err
 	}
 
-	return s.repo.Update(ctx, c)
+	return s.repo.Update(ctx, c, expectedVersion)
 }
 
-// Delete удаляет мероприятие из системы.
-func (s *CompetitionService) Delete(ctx context.Context, id int) error {
+// Patch применяет к соревнованию JSON Merge Patch (RFC 7396): меняются только переданные поля.
+func (s *CompetitionService) Patch(ctx context.Context, id int, fields map[string]json.RawMessage, expectedVersion int) (*repository.Competition, error) {
 	if id <= 0 {
-		return fmt.Errorf("ошибка: некорректный ID для удаления")
+		return nil, invalidField("id", CodeInvalid, "некорректный ID соревнования")
+	}
+
+	p := newMergePatch(fields, "id", "version", "deleted_at")
+	patch := repository.CompetitionPatch{
+		Name:      p.String("name", false),
+		Location:  p.String("location", false),
+		StartDate: p.Date("start_date"),
+		Level:     p.String("level", true),
+		SportID:   p.Int("sport_id", true),
+	}
+	if patch.Name != nil {
+		p.Required("name", *patch.Name)
+		p.MaxLen("name", *patch.Name, 255)
+	}
+	if patch.Location != nil {
+		p.Required("location", *patch.Location)
+		p.MaxLen("location", *patch.Location, 255)
+	}
+	if patch.StartDate != nil {
+		p.DateBetween("start_date", *patch.StartDate, minCompetitionDate, maxCompetitionDate())
+	}
+	if patch.Level != nil {
+		p.MaxLen("level", *patch.Level, 50)
+	}
+	if patch.SportID != nil {
+		p.NotNegative("sport_id", float64(*patch.SportID))
+	}
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+
+	return s.repo.Patch(ctx, id, patch, expectedVersion)
+}
+
+// minCompetitionDate — нижняя граница даты начала при исправлении уже проведенных соревнований.
+var minCompetitionDate = time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// maxCompetitionDate — соревнования планируются не более чем на 10 лет вперед.
+func maxCompetitionDate() time.Time {
+	return time.Now().AddDate(10, 0, 0)
+}
+
+// validateCompetition проверяет все поля соревнования; дата начала — не раньше earliest.
+func validateCompetition(c *repository.Competition, earliest time.Time) error {
+	var v validator
+	v.Required("name", c.Name)
+	v.MaxLen("name", c.Name, 255)
+	v.Required("location", c.Location)
+	v.MaxLen("location", c.Location, 255)
+	v.RequiredDate("start_date", c.StartDate)
+	v.DateBetween("start_date", c.StartDate, earliest, maxCompetitionDate())
+	v.MaxLen("level", c.Level, 50)
+	v.NotNegative("sport_id", float64(c.SportID))
+	return v.Err()
+}
+
+// Delete мягко удаляет мероприятие: участия и результаты сохраняются до восстановления или очистки.
+func (s *CompetitionService) Delete(ctx context.Context, id, expectedVersion int) error {
+	if id <= 0 {
+		return invalidField("id", CodeInvalid, "некорректный ID для удаления")
+	}
+	return s.repo.Delete(ctx, id, expectedVersion)
+}
+
+// Restore восстанавливает мягко удаленное мероприятие.
+func (s *CompetitionService) Restore(ctx context.Context, id int) error {
+	if id <= 0 {
+		return invalidField("id", CodeInvalid, "некорректный ID соревнования")
+	}
+	return s.repo.Restore(ctx, id)
+}
+
+// Purge безвозвратно удаляет ранее удаленное мероприятие вместе с участиями и результатами.
+func (s *CompetitionService) Purge(ctx context.Context, id int) error {
+	if id <= 0 {
+		return invalidField("id", CodeInvalid, "некорректный ID соревнования")
+	}
+	return s.repo.Purge(ctx, id)
+}
+
+// --- ОТБОРОЧНЫЕ НОРМАТИВЫ ---
+
+// ListEntryStandards возвращает отборочные нормативы соревнования.
+func (s *CompetitionService) ListEntryStandards(ctx context.Context, competitionID int) ([]repository.EntryStandard, error) {
+	if competitionID <= 0 {
+		return nil, invalidField("id", CodeInvalid, "некорректный ID соревнования")
+	}
+	return s.entryRepo.ListByCompetition(ctx, competitionID)
+}
+
+// CreateEntryStandard проверяет и добавляет отборочный норматив к соревнованию.
+func (s *CompetitionService) CreateEntryStandard(ctx context.Context, st *repository.EntryStandard) error {
+	if st.QualifyingPeriodDays == 0 {
+		st.QualifyingPeriodDays = 365 // Результат за последний год
+	}
+	var v validator
+	v.Required("event", st.Event)
+	v.MaxLen("event", st.Event, 100)
+	if st.Gender != "" {
+		v.OneOf("gender", st.Gender, genders...)
+	}
+	v.Positive("result_threshold", st.ResultThreshold)
+	v.NotNegative("qualifying_period_days", float64(st.QualifyingPeriodDays))
+	if err := v.Err(); err != nil {
+		return err
+	}
+
+	// Убеждаемся, что соревнование существует
+	if _, err := s.repo.GetByID(ctx, st.CompetitionID); err != nil {
+		return err
+	}
+	return s.entryRepo.Create(ctx, st)
+}
+
+// DeleteEntryStandard удаляет отборочный норматив соревнования.
+func (s *CompetitionService) DeleteEntryStandard(ctx context.Context, competitionID, id int) error {
+	if competitionID <= 0 || id <= 0 {
+		return invalidField("id", CodeInvalid, "некорректный ID для удаления")
 	}
-	return s.repo.Delete(ctx, id)
+	return s.entryRepo.Delete(ctx, competitionID, id)
 }
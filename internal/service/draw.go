@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"slices"
+
+	"sport-manager/internal/draw"
+	"sport-manager/internal/repository"
+)
+
+// Виды жеребьевок
+const (
+	DrawKindStartOrder = "start_order" // Случайный порядок старта
+	DrawKindBib        = "bib"         // Случайная выдача стартовых номеров
+)
+
+// DrawVerification — результат независимой проверки проведенной жеребьевки.
+type DrawVerification struct {
+	DrawID       int   `json:"draw_id"`
+	CommitmentOK bool  `json:"commitment_ok"` // Хеш раскрытого зерна совпадает с опубликованным
+	ResultOK     bool  `json:"result_ok"`     // Повторный прогон дал тот же результат
+	Recalculated []int `json:"recalculated"`
+	StoredResult []int `json:"stored_result"`
+}
+
+// DrawService проводит проверяемые жеребьевки по схеме «обязательство — раскрытие»:
+// хеш зерна публикуется заранее, зерно раскрывается после проведения,
+// а результат можно воспроизвести по сохраненным входным данным.
+type DrawService struct {
+	repo            *repository.DrawRepository
+	competitionRepo *repository.CompetitionRepository
+}
+
+// NewDrawService создает новый экземпляр сервиса жеребьевок.
+func NewDrawService(repo *repository.DrawRepository, competitionRepo *repository.CompetitionRepository) *DrawService {
+	return &DrawService{repo: repo, competitionRepo: competitionRepo}
+}
+
+// Commit создает жеребьевку: генерирует зерно и публикует его хеш.
+func (s *DrawService) Commit(ctx context.Context, competitionID int, kind, createdBy string) (*repository.Draw, error) {
+	var v validator
+	v.OneOf("kind", kind, DrawKindStartOrder, DrawKindBib)
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+	if _, err := s.competitionRepo.GetByID(ctx, competitionID); err != nil {
+		return nil, err
+	}
+
+	seed, err := draw.NewSeed()
+	if err != nil {
+		return nil, fmt.Errorf("service: %w", err)
+	}
+
+	d := &repository.Draw{
+		CompetitionID: competitionID,
+		Kind:          kind,
+		SeedHash:      draw.Commitment(seed),
+		Seed:          hex.EncodeToString(seed),
+		CreatedBy:     createdBy,
+	}
+	if err := s.repo.Create(ctx, d); err != nil {
+		return nil, err
+	}
+	return hideSeed(d), nil
+}
+
+// Get возвращает жеребьевку; зерно видно только после ее проведения.
+func (s *DrawService) Get(ctx context.Context, id int) (*repository.Draw, error) {
+	if id <= 0 {
+		return nil, invalidField("id", CodeInvalid, "некорректный ID жеребьевки")
+	}
+	d, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return hideSeed(d), nil
+}
+
+// ListByCompetition возвращает жеребьевки соревнования без нераскрытых зерен.
+func (s *DrawService) ListByCompetition(ctx context.Context, competitionID int) ([]repository.Draw, error) {
+	draws, err := s.repo.ListByCompetition(ctx, competitionID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range draws {
+		hideSeed(&draws[i])
+	}
+	return draws, nil
+}
+
+// Execute проводит заранее объявленную жеребьевку над items и раскрывает зерно.
+// subjects (необязательно) — кому достаются элементы результата по порядку.
+func (s *DrawService) Execute(ctx context.Context, drawID, competitionID int, kind string, items, subjects []int) ([]int, error) {
+	if drawID <= 0 {
+		return nil, invalidField("draw_id", CodeRequired, "для случайной операции требуется заранее объявленная жеребьевка")
+	}
+
+	d, err := s.repo.GetByID(ctx, drawID)
+	if err != nil {
+		return nil, err
+	}
+	if d.CompetitionID != competitionID || d.Kind != kind {
+		return nil, invalidField("draw_id", CodeInvalid, "жеребьевка %d предназначена для другой операции", drawID)
+	}
+	if d.Status != "committed" {
+		return nil, repository.Conflict("жеребьевка %d уже проведена", drawID)
+	}
+
+	seed, err := draw.DecodeSeed(d.Seed)
+	if err != nil {
+		return nil, fmt.Errorf("service: повреждено зерно жеребьевки %d: %w", drawID, err)
+	}
+
+	d.Items = slices.Clone(items)
+	d.Subjects = slices.Clone(subjects)
+	d.Result = draw.Shuffle(seed, items)
+
+	// Reveal атомарно переводит жеребьевку в 'revealed', повторно провести ее нельзя
+	if err := s.repo.Reveal(ctx, d); err != nil {
+		return nil, err
+	}
+	return d.Result, nil
+}
+
+// Verify повторно проводит жеребьевку по раскрытому зерну и сохраненным данным.
+func (s *DrawService) Verify(ctx context.Context, id int) (*DrawVerification, error) {
+	d, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if d.Status != "revealed" {
+		return nil, repository.Conflict("жеребьевка %d еще не проведена", id)
+	}
+
+	seed, err := draw.DecodeSeed(d.Seed)
+	if err != nil {
+		return nil, fmt.Errorf("service: повреждено зерно жеребьевки %d: %w", id, err)
+	}
+
+	recalculated := draw.Shuffle(seed, d.Items)
+	return &DrawVerification{
+		DrawID:       d.ID,
+		CommitmentOK: draw.Commitment(seed) == d.SeedHash,
+		ResultOK:     slices.Equal(recalculated, d.Result),
+		Recalculated: recalculated,
+		StoredResult: d.Result,
+	}, nil
+}
+
+// Replay проводит жеребьевку по произвольному зерну — для независимой проверки без доступа к БД.
+func (s *DrawService) Replay(seedHex string, items []int) (string, []int, error) {
+	seed, err := draw.DecodeSeed(seedHex)
+	if err != nil {
+		return "", nil, invalidField("seed", CodeInvalid, err.Error())
+	}
+	return draw.Commitment(seed), draw.Shuffle(seed, items), nil
+}
+
+// hideSeed скрывает зерно у жеребьевки, которая еще не проведена.
+func hideSeed(d *repository.Draw) *repository.Draw {
+	if d.Status != "revealed" {
+		d.Seed = ""
+	}
+	return d
+}
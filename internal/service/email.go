@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"sport-manager/internal/auth"
+	"sport-manager/internal/mailer"
+	"sport-manager/internal/repository"
+)
+
+// Сроки действия ссылок из писем.
+const (
+	verificationTokenTTL  = 48 * time.Hour
+	passwordResetTokenTTL = time.Hour
+)
+
+// AccountMailer выпускает одноразовые токены и отправляет письма со ссылками
+// для подтверждения email и сброса пароля.
+type AccountMailer struct {
+	mailer    mailer.Mailer
+	tokenRepo *repository.TokenRepository
+	baseURL   string
+}
+
+// NewAccountMailer создает отправитель служебных писем.
+func NewAccountMailer(m mailer.Mailer, tokenRepo *repository.TokenRepository, baseURL string) *AccountMailer {
+	return &AccountMailer{mailer: m, tokenRepo: tokenRepo, baseURL: strings.TrimRight(baseURL, "/")}
+}
+
+// SendVerification отправляет ссылку для подтверждения email.
+func (m *AccountMailer) SendVerification(ctx context.Context, user *repository.User) error {
+	return m.send(ctx, user, repository.EmailTokenVerify, verificationTokenTTL,
+		mailer.TemplateVerifyEmail, "/api/v1/auth/verify-email")
+}
+
+// SendPasswordReset отправляет ссылку для сброса пароля.
+func (m *AccountMailer) SendPasswordReset(ctx context.Context, user *repository.User) error {
+	return m.send(ctx, user, repository.EmailTokenReset, passwordResetTokenTTL,
+		mailer.TemplatePasswordReset, "/reset-password.html")
+}
+
+// send выпускает токен, сохраняет его хеш и отправляет письмо по шаблону на языке пользователя.
+func (m *AccountMailer) send(ctx context.Context, user *repository.User, purpose string, ttl time.Duration, template, path string) error {
+	token, hash, err := auth.NewRefreshToken()
+	if err != nil {
+		return err
+	}
+	expiresAt := time.Now().Add(ttl)
+	if err := m.tokenRepo.CreateEmailToken(ctx, user.ID, purpose, hash, expiresAt); err != nil {
+		return err
+	}
+
+	msg, err := mailer.Render(template, user.Language, user.Email, map[string]interface{}{
+		"Username":  user.Username,
+		"Link":      m.baseURL + path + "?token=" + url.QueryEscape(token),
+		"ExpiresAt": expiresAt,
+	})
+	if err != nil {
+		return err
+	}
+	if err := m.mailer.Send(ctx, msg); err != nil {
+		return fmt.Errorf("service: %w", err)
+	}
+
+	log.Printf("Письмо '%s' отправлено пользователю %s", template, user.Username)
+	return nil
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"sport-manager/internal/repository"
+)
+
+// FieldChange — изменение одного поля между двумя версиями записи.
+type FieldChange struct {
+	Field string          `json:"field"`
+	Old   json.RawMessage `json:"old"`
+	New   json.RawMessage `json:"new"`
+}
+
+// HistoryEntry — версия записи вместе с отличиями от предыдущей версии.
+type HistoryEntry struct {
+	repository.EntityVersion
+	Changes []FieldChange `json:"changes"`
+}
+
+// VersionDiff — отличия между двумя произвольными версиями записи.
+type VersionDiff struct {
+	From    int           `json:"from"`
+	To      int           `json:"to"`
+	Changes []FieldChange `json:"changes"`
+}
+
+// HistoryService показывает историю версий спортсменов и соревнований.
+type HistoryService struct {
+	versions *repository.VersionRepository
+}
+
+// NewHistoryService создает новый экземпляр сервиса истории версий.
+func NewHistoryService(versions *repository.VersionRepository) *HistoryService {
+	return &HistoryService{versions: versions}
+}
+
+// History возвращает все версии записи с изменениями относительно предыдущей версии.
+func (s *HistoryService) History(ctx context.Context, entity string, id int) ([]HistoryEntry, error) {
+	if id <= 0 {
+		return nil, invalidField("id", CodeInvalid, "некорректный ID записи")
+	}
+
+	versions, err := s.versions.History(ctx, entity, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, repository.NotFound("история записи с ID %d не найдена", id)
+	}
+
+	entries := make([]HistoryEntry, 0, len(versions))
+	var prev json.RawMessage
+	for _, v := range versions {
+		changes, err := diffFields(prev, v.Data)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, HistoryEntry{EntityVersion: v, Changes: changes})
+		prev = v.Data
+	}
+	return entries, nil
+}
+
+// Diff сравнивает версии from и to. Нулевой to — последняя версия, нулевой from — предыдущая перед to.
+func (s *HistoryService) Diff(ctx context.Context, entity string, id, from, to int) (*VersionDiff, error) {
+	if to == 0 {
+		versions, err := s.versions.History(ctx, entity, id)
+		if err != nil {
+			return nil, err
+		}
+		if len(versions) == 0 {
+			return nil, repository.NotFound("история записи с ID %d не найдена", id)
+		}
+		to = versions[len(versions)-1].Version
+	}
+	if from == 0 {
+		from = to - 1
+	}
+	if from <= 0 || from == to {
+		return nil, invalidField("from", CodeInvalid, "укажите две разные версии (from и to)")
+	}
+
+	older, err := s.versions.Get(ctx, entity, id, from)
+	if err != nil {
+		return nil, err
+	}
+	newer, err := s.versions.Get(ctx, entity, id, to)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := diffFields(older.Data, newer.Data)
+	if err != nil {
+		return nil, err
+	}
+	return &VersionDiff{From: from, To: to, Changes: changes}, nil
+}
+
+// diffFields возвращает поля, значения которых различаются в двух JSON-снимках (в порядке имен).
+// Пустой старый снимок означает создание записи: в изменения попадают все поля.
+func diffFields(older, newer json.RawMessage) ([]FieldChange, error) {
+	oldFields := map[string]json.RawMessage{}
+	newFields := map[string]json.RawMessage{}
+	if len(older) > 0 {
+		if err := json.Unmarshal(older, &oldFields); err != nil {
+			return nil, fmt.Errorf("service: поврежденный снимок версии: %w", err)
+		}
+	}
+	if err := json.Unmarshal(newer, &newFields); err != nil {
+		return nil, fmt.Errorf("service: поврежденный снимок версии: %w", err)
+	}
+
+	names := make([]string, 0, len(newFields))
+	for name := range newFields {
+		names = append(names, name)
+	}
+	for name := range oldFields {
+		if _, ok := newFields[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	changes := make([]FieldChange, 0)
+	for _, name := range names {
+		o, n := oldFields[name], newFields[name]
+		// Снимки получены из JSONB, поэтому одинаковые значения совпадают побайтно
+		if string(o) == string(n) {
+			continue
+		}
+		changes = append(changes, FieldChange{Field: name, Old: jsonOrNull(o), New: jsonOrNull(n)})
+	}
+	return changes, nil
+}
+
+// jsonOrNull заменяет отсутствующее значение на JSON null.
+func jsonOrNull(raw json.RawMessage) json.RawMessage {
+	if len(raw) == 0 {
+		return json.RawMessage("null")
+	}
+	return raw
+}
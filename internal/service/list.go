@@ -0,0 +1,54 @@
+package service
+
+import (
+	"slices"
+	"strings"
+
+	"sport-manager/internal/repository"
+)
+
+// Ограничения размера страницы списков
+const (
+	listDefaultLimit = 50
+	listMaxLimit     = 500
+)
+
+// PageRequest — параметры страницы списка в том виде, в каком их передал клиент.
+type PageRequest struct {
+	Sort   string // Поле сортировки; префикс '-' — по убыванию. Пустое — сортировка по умолчанию
+	Limit  int    // Размер страницы; 0 — по умолчанию
+	Cursor string // Курсор из ответа на предыдущую страницу
+}
+
+// pageFor проверяет параметры страницы: поле сортировки должно входить в белый список сущности,
+// курсор — относиться к той же сортировке. Размер страницы приводится к допустимому диапазону.
+func pageFor(req PageRequest, sortFields []string, defaultSort string, v *validator) repository.Page {
+	sort := req.Sort
+	if sort == "" {
+		sort = defaultSort
+	}
+	page := repository.Page{Limit: req.Limit}
+	page.Sort, page.Desc = strings.CutPrefix(sort, "-")
+	v.Check(slices.Contains(sortFields, page.Sort), "sort", CodeEnum,
+		"допустимые значения: %s (префикс '-' — по убыванию)", strings.Join(sortFields, ", "))
+
+	if page.Limit <= 0 {
+		page.Limit = listDefaultLimit
+	}
+	if page.Limit > listMaxLimit {
+		page.Limit = listMaxLimit
+	}
+
+	if req.Cursor != "" {
+		cursor, err := repository.DecodeCursor(req.Cursor)
+		switch {
+		case err != nil:
+			v.Add("cursor", CodeInvalid, "курсор поврежден")
+		case cursor.Sort != page.Sort || cursor.Desc != page.Desc:
+			v.Add("cursor", CodeInvalid, "курсор выдан для другой сортировки, начните с первой страницы")
+		default:
+			page.After = cursor
+		}
+	}
+	return page
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strings"
+	"time"
+
+	"sport-manager/internal/repository"
+	"sport-manager/pkg/config"
+)
+
+// loginFailureWindow — через сколько после последней неудачи счетчик начинается заново.
+const loginFailureWindow = 24 * time.Hour
+
+// LockedError возвращается, пока вход по учетной записи или IP-адресу временно заблокирован.
+// Для существующих и несуществующих логинов ответ одинаков.
+type LockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LockedError) Error() string {
+	return "слишком много неудачных попыток входа, повторите позже"
+}
+
+// LoginGuard защищает вход от перебора паролей: считает неудачи по логину и по IP
+// и при превышении порога блокирует вход с экспоненциально растущей длительностью.
+type LoginGuard struct {
+	repo  *repository.LoginAttemptRepository
+	audit *repository.AuditRepository
+
+	userThreshold int
+	ipThreshold   int
+	baseLockout   time.Duration
+	maxLockout    time.Duration
+}
+
+// NewLoginGuard создает защиту входа с порогами из конфигурации.
+func NewLoginGuard(repo *repository.LoginAttemptRepository, audit *repository.AuditRepository, cfg *config.Config) *LoginGuard {
+	return &LoginGuard{
+		repo:          repo,
+		audit:         audit,
+		userThreshold: cfg.LoginMaxFailures,
+		ipThreshold:   cfg.LoginIPMaxFailures,
+		baseLockout:   cfg.LoginLockoutBase,
+		maxLockout:    cfg.LoginLockoutMax,
+	}
+}
+
+// UserKey и IPKey формируют ключи счетчиков.
+func UserKey(username string) string { return "user:" + strings.ToLower(username) }
+func IPKey(ip string) string         { return "ip:" + ip }
+
+// Check возвращает *LockedError, если вход по логину или с IP-адреса заблокирован.
+func (g *LoginGuard) Check(ctx context.Context, username, ip string) error {
+	var latest *time.Time
+	for _, key := range g.keys(username, ip) {
+		until, err := g.repo.LockedUntil(ctx, key)
+		if err != nil {
+			return err
+		}
+		if until != nil && (latest == nil || until.After(*latest)) {
+			latest = until
+		}
+	}
+	if latest != nil {
+		return &LockedError{RetryAfter: time.Until(*latest).Round(time.Second)}
+	}
+	return nil
+}
+
+// Failure учитывает неудачный вход. При достижении порога ключ блокируется на
+// baseLockout, и каждая следующая неудача удваивает блокировку (но не дольше maxLockout).
+func (g *LoginGuard) Failure(ctx context.Context, username, ip string) {
+	thresholds := map[string]int{UserKey(username): g.userThreshold}
+	if ip != "" {
+		thresholds[IPKey(ip)] = g.ipThreshold
+	}
+
+	for key, threshold := range thresholds {
+		failures, err := g.repo.RecordFailure(ctx, key, loginFailureWindow)
+		if err != nil {
+			log.Printf("Ошибка учета неудачного входа: %v", err)
+			continue
+		}
+		if threshold <= 0 || failures < threshold {
+			continue
+		}
+
+		until := time.Now().Add(g.lockoutDuration(failures - threshold))
+		if err := g.repo.Lock(ctx, key, until); err != nil {
+			log.Printf("Ошибка блокировки входа: %v", err)
+			continue
+		}
+
+		log.Printf("ВНИМАНИЕ: вход заблокирован для %s до %s (неудач подряд: %d)", key, until.Format(time.RFC3339), failures)
+		g.record(ctx, "", ip, "lockout", map[string]interface{}{
+			"key": key, "failures": failures, "locked_until": until,
+		})
+	}
+}
+
+// Success сбрасывает счетчик учетной записи после успешного входа.
+// Счетчик IP не сбрасывается: с одного адреса могут перебирать разные логины.
+func (g *LoginGuard) Success(ctx context.Context, username string) {
+	if _, err := g.repo.Reset(ctx, UserKey(username)); err != nil {
+		log.Printf("Ошибка сброса счетчика входов: %v", err)
+	}
+}
+
+// Unlock снимает блокировку по ключу (действие администратора) и фиксирует это в аудите.
+func (g *LoginGuard) Unlock(ctx context.Context, actor, ip, key string) error {
+	if !strings.HasPrefix(key, "user:") && !strings.HasPrefix(key, "ip:") {
+		return invalidField("key", CodeInvalid, "ключ должен начинаться с 'user:' или 'ip:'")
+	}
+
+	removed, err := g.repo.Reset(ctx, key)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return repository.NotFound("для '%s' нет неудачных попыток входа", key)
+	}
+
+	g.record(ctx, actor, ip, "unlock", map[string]interface{}{"key": key})
+	return nil
+}
+
+// ListLocked возвращает действующие блокировки.
+func (g *LoginGuard) ListLocked(ctx context.Context) ([]repository.LoginFailure, error) {
+	return g.repo.ListLocked(ctx)
+}
+
+// lockoutDuration вычисляет длительность блокировки: base * 2^over, не более max.
+func (g *LoginGuard) lockoutDuration(over int) time.Duration {
+	d := g.baseLockout
+	for i := 0; i < over && d < g.maxLockout; i++ {
+		d *= 2
+	}
+	if d > g.maxLockout {
+		d = g.maxLockout
+	}
+	return d
+}
+
+// keys возвращает ключи счетчиков для попытки входа.
+func (g *LoginGuard) keys(username, ip string) []string {
+	keys := []string{UserKey(username)}
+	if ip != "" {
+		keys = append(keys, IPKey(ip))
+	}
+	return keys
+}
+
+// record пишет событие блокировки в журнал аудита; сбой аудита не мешает входу.
+func (g *LoginGuard) record(ctx context.Context, actor, ip, action string, details map[string]interface{}) {
+	data, _ := json.Marshal(details)
+	entry := &repository.AuditEntry{Username: actor, IP: ip, Entity: "login", Action: action, After: data}
+	if err := g.audit.Record(ctx, entry); err != nil {
+		log.Printf("Ошибка записи аудита: %v", err)
+	}
+}
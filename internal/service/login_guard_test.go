@@ -0,0 +1,26 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLockoutDuration(t *testing.T) {
+	g := &LoginGuard{baseLockout: time.Minute, maxLockout: time.Hour}
+	tests := []struct {
+		over int
+		want time.Duration
+	}{
+		{0, time.Minute},
+		{1, 2 * time.Minute},
+		{3, 8 * time.Minute},
+		{5, 32 * time.Minute},
+		{6, time.Hour}, // 64 минуты обрезаются до максимума
+		{1000, time.Hour},
+	}
+	for _, tt := range tests {
+		if got := g.lockoutDuration(tt.over); got != tt.want {
+			t.Errorf("lockoutDuration(%d) = %v, want %v", tt.over, got, tt.want)
+		}
+	}
+}
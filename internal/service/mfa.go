@@ -0,0 +1,345 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strconv"
+	"time"
+
+	"sport-manager/internal/auth"
+	"sport-manager/internal/repository"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// totpIssuer — название сервиса в приложении-аутентификаторе.
+	totpIssuer = "Sport Manager"
+	// recoveryCodeCount — сколько резервных кодов выдается за раз.
+	recoveryCodeCount = 10
+	// mfaChallengeTTL и mfaChallengeAttempts ограничивают второй шаг входа.
+	mfaChallengeTTL      = 5 * time.Minute
+	mfaChallengeAttempts = 5
+	// settingRequire2FA — ключ настройки «требовать 2FA от администраторов и организаторов».
+	settingRequire2FA = "require_2fa_privileged"
+)
+
+// TOTPEnrollment — данные для подключения приложения-аутентификатора.
+// URI кодируется в QR-код на стороне клиента.
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"provisioning_uri"`
+}
+
+// LoginChallenge — первый шаг входа пройден, нужен код второго фактора.
+type LoginChallenge struct {
+	Token     string `json:"challenge"`
+	ExpiresIn int    `json:"expires_in"`
+}
+
+// SecurityPolicy — настройки безопасности, которые меняет администратор.
+type SecurityPolicy struct {
+	// Require2FAPrivileged — пользователи с ролью администратора или организатора
+	// (глобальной или в любой области) обязаны подключить 2FA
+	Require2FAPrivileged bool `json:"require_2fa_privileged"`
+}
+
+// MFAService реализует двухфакторную аутентификацию по TOTP с резервными кодами.
+type MFAService struct {
+	repo     *repository.MFARepository
+	authRepo *repository.AuthRepository
+	settings *repository.SettingsRepository
+	roles    *RoleService
+	audit    *repository.AuditRepository
+}
+
+// NewMFAService создает новый экземпляр сервиса двухфакторной аутентификации.
+func NewMFAService(
+	repo *repository.MFARepository,
+	authRepo *repository.AuthRepository,
+	settings *repository.SettingsRepository,
+	roles *RoleService,
+	audit *repository.AuditRepository,
+) *MFAService {
+	return &MFAService{repo: repo, authRepo: authRepo, settings: settings, roles: roles, audit: audit}
+}
+
+// --- ПОДКЛЮЧЕНИЕ И ОТКЛЮЧЕНИЕ ---
+
+// Enroll выпускает новый секрет. 2FA начнет действовать только после Confirm,
+// поэтому незавершенное подключение не закроет пользователю доступ.
+func (s *MFAService) Enroll(ctx context.Context, claims *auth.Claims) (*TOTPEnrollment, error) {
+	user, err := s.authRepo.GetByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TwoFactorEnabled {
+		return nil, repository.Conflict("двухфакторная аутентификация уже подключена")
+	}
+
+	secret, err := auth.NewTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetPendingSecret(ctx, user.ID, secret); err != nil {
+		return nil, err
+	}
+
+	return &TOTPEnrollment{Secret: secret, URI: auth.TOTPProvisioningURI(totpIssuer, user.Username, secret)}, nil
+}
+
+// Confirm проверяет первый код из приложения, включает 2FA и возвращает резервные коды.
+// Коды показываются один раз: в базе хранятся только их хеши.
+func (s *MFAService) Confirm(ctx context.Context, claims *auth.Claims, ip, code string) ([]string, error) {
+	st, err := s.repo.GetTOTP(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if st.Enabled {
+		return nil, repository.Conflict("двухфакторная аутентификация уже подключена")
+	}
+	if st.Secret == "" {
+		return nil, repository.Conflict("сначала начните подключение (POST /me/2fa/enroll)")
+	}
+
+	step, ok := auth.ValidateTOTP(st.Secret, code, time.Now())
+	if !ok {
+		return nil, invalidField("code", CodeInvalid, "неверный код подтверждения")
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Enable(ctx, claims.UserID, step, hashes); err != nil {
+		return nil, err
+	}
+
+	s.record(ctx, claims.Username, ip, claims.UserID, "2fa_enable")
+	return codes, nil
+}
+
+// Disable отключает 2FA по паролю и действующему коду (TOTP или резервному).
+// Если политика требует 2FA для роли пользователя, отключить ее нельзя.
+func (s *MFAService) Disable(ctx context.Context, claims *auth.Claims, ip, password, code string) error {
+	user, err := s.authRepo.GetByID(ctx, claims.UserID)
+	if err != nil {
+		return err
+	}
+	if !user.TwoFactorEnabled {
+		return repository.Conflict("двухфакторная аутентификация не подключена")
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return invalidField("password", CodeInvalid, "пароль указан неверно")
+	}
+
+	required, err := s.Required(ctx, user, nil)
+	if err != nil {
+		return err
+	}
+	if required {
+		return forbidden("политика безопасности требует 2FA для вашей роли")
+	}
+
+	ok, err := s.Verify(ctx, user.ID, code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return invalidField("code", CodeInvalid, "неверный код подтверждения")
+	}
+
+	if err := s.repo.Disable(ctx, user.ID); err != nil {
+		return err
+	}
+	s.record(ctx, claims.Username, ip, user.ID, "2fa_disable")
+	return nil
+}
+
+// RegenerateRecoveryCodes заменяет резервные коды новыми; старые перестают действовать.
+func (s *MFAService) RegenerateRecoveryCodes(ctx context.Context, claims *auth.Claims, ip, code string) ([]string, error) {
+	ok, err := s.Verify(ctx, claims.UserID, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, invalidField("code", CodeInvalid, "неверный код подтверждения")
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.ReplaceRecoveryCodes(ctx, claims.UserID, hashes); err != nil {
+		return nil, err
+	}
+
+	s.record(ctx, claims.Username, ip, claims.UserID, "2fa_recovery_codes")
+	return codes, nil
+}
+
+// Reset отключает 2FA пользователю, потерявшему устройство и резервные коды (действие администратора).
+// Если политика требует 2FA, при следующем входе пользователь должен будет подключить ее заново.
+func (s *MFAService) Reset(ctx context.Context, actor *auth.Claims, ip string, userID int) error {
+	user, err := s.authRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !user.TwoFactorEnabled {
+		return repository.Conflict("у пользователя '%s' двухфакторная аутентификация не подключена", user.Username)
+	}
+
+	if err := s.repo.Disable(ctx, user.ID); err != nil {
+		return err
+	}
+	s.record(ctx, actor.Username, ip, user.ID, "2fa_reset")
+	return nil
+}
+
+// --- ПРОВЕРКА КОДОВ ---
+
+// Verify проверяет код второго фактора: 6 цифр из приложения или резервный код.
+// Принятый TOTP-код и погашенный резервный код повторно не действуют.
+func (s *MFAService) Verify(ctx context.Context, userID int, code string) (bool, error) {
+	st, err := s.repo.GetTOTP(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	if !st.Enabled {
+		return false, nil
+	}
+
+	// Код из приложения — ровно 6 цифр, резервный код длиннее
+	if _, err := strconv.Atoi(code); err == nil && len(code) == 6 {
+		step, ok := auth.ValidateTOTP(st.Secret, code, time.Now())
+		if !ok || step <= st.LastStep {
+			return false, nil
+		}
+		return s.repo.AcceptStep(ctx, userID, step)
+	}
+
+	used, err := s.repo.UseRecoveryCode(ctx, userID, auth.HashToken(auth.NormalizeRecoveryCode(code)))
+	if err != nil {
+		return false, err
+	}
+	if used {
+		log.Printf("Пользователь %d вошел по резервному коду", userID)
+	}
+	return used, nil
+}
+
+// StartLogin сохраняет незавершенный вход и возвращает токен для второго шага.
+func (s *MFAService) StartLogin(ctx context.Context, userID int, ip string) (*LoginChallenge, error) {
+	token, hash, err := auth.NewRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateChallenge(ctx, userID, hash, ip, time.Now().Add(mfaChallengeTTL)); err != nil {
+		return nil, err
+	}
+	return &LoginChallenge{Token: token, ExpiresIn: int(mfaChallengeTTL.Seconds())}, nil
+}
+
+// PendingLogin находит незавершенный вход по токену и проверяет, что он еще действует.
+func (s *MFAService) PendingLogin(ctx context.Context, token string) (*repository.MFAChallenge, error) {
+	c, err := s.repo.GetChallenge(ctx, auth.HashToken(token))
+	if err != nil {
+		return nil, unauthorized("вход не найден, начните заново")
+	}
+	if c.UsedAt != nil || time.Now().After(c.ExpiresAt) || c.Attempts >= mfaChallengeAttempts {
+		return nil, unauthorized("время на ввод кода истекло, начните вход заново")
+	}
+	return c, nil
+}
+
+// FailLogin учитывает неверный код; после mfaChallengeAttempts попыток вход нужно начинать заново.
+func (s *MFAService) FailLogin(ctx context.Context, c *repository.MFAChallenge) {
+	if _, err := s.repo.FailChallenge(ctx, c.ID); err != nil {
+		log.Printf("Ошибка учета неверного кода 2FA: %v", err)
+	}
+}
+
+// FinishLogin завершает вход. Возвращает false, если токен уже использован параллельным запросом.
+func (s *MFAService) FinishLogin(ctx context.Context, c *repository.MFAChallenge) (bool, error) {
+	return s.repo.ConsumeChallenge(ctx, c.ID)
+}
+
+// --- ПОЛИТИКА БЕЗОПАСНОСТИ ---
+
+// Required сообщает, обязан ли пользователь использовать 2FA по действующей политике.
+// grants можно не передавать: тогда роли с областью читаются из базы.
+func (s *MFAService) Required(ctx context.Context, user *repository.User, grants []auth.Grant) (bool, error) {
+	policy, err := s.Policy(ctx)
+	if err != nil || !policy.Require2FAPrivileged {
+		return false, err
+	}
+	if auth.IsPrivilegedRole(user.Role) {
+		return true, nil
+	}
+
+	if grants == nil {
+		if grants, err = s.roles.Grants(ctx, user.ID); err != nil {
+			return false, err
+		}
+	}
+	for _, g := range grants {
+		if auth.IsPrivilegedRole(g.Role) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Policy возвращает действующие настройки безопасности.
+func (s *MFAService) Policy(ctx context.Context) (*SecurityPolicy, error) {
+	value, err := s.settings.Get(ctx, settingRequire2FA, "false")
+	if err != nil {
+		return nil, err
+	}
+	require, _ := strconv.ParseBool(value)
+	return &SecurityPolicy{Require2FAPrivileged: require}, nil
+}
+
+// UpdatePolicy сохраняет настройки безопасности и фиксирует изменение в аудите.
+// Новая политика применяется к токенам при следующем входе или продлении сессии.
+func (s *MFAService) UpdatePolicy(ctx context.Context, actor *auth.Claims, ip string, p *SecurityPolicy) error {
+	before, err := s.Policy(ctx)
+	if err != nil {
+		return err
+	}
+	if err := s.settings.Set(ctx, settingRequire2FA, strconv.FormatBool(p.Require2FAPrivileged), actor.Username); err != nil {
+		return err
+	}
+
+	beforeData, _ := json.Marshal(before)
+	afterData, _ := json.Marshal(p)
+	entry := &repository.AuditEntry{
+		Username: actor.Username, IP: ip, Entity: "settings", Action: "update", Before: beforeData, After: afterData,
+	}
+	if err := s.audit.Record(ctx, entry); err != nil {
+		log.Printf("Ошибка записи аудита: %v", err)
+	}
+	return nil
+}
+
+// newRecoveryCodes генерирует резервные коды и их хеши для хранения.
+func newRecoveryCodes() (codes, hashes []string, err error) {
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := auth.NewRecoveryCode()
+		if err != nil {
+			return nil, nil, err
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, auth.HashToken(auth.NormalizeRecoveryCode(code)))
+	}
+	return codes, hashes, nil
+}
+
+// record пишет событие 2FA в журнал аудита; сбой аудита не отменяет действие.
+func (s *MFAService) record(ctx context.Context, actor, ip string, userID int, action string) {
+	entry := &repository.AuditEntry{Username: actor, IP: ip, Entity: "user", EntityID: userID, Action: action}
+	if err := s.audit.Record(ctx, entry); err != nil {
+		log.Printf("Ошибка записи аудита: %v", err)
+	}
+}
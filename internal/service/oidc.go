@@ -0,0 +1,325 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode"
+
+	"sport-manager/internal/auth"
+	"sport-manager/internal/repository"
+)
+
+// OIDCLoginTTL — сколько ждем возврата пользователя от провайдера.
+const OIDCLoginTTL = 10 * time.Minute
+
+// oidcHandoffTTL — сколько действует одноразовый код, который страница входа обменивает на токены.
+const oidcHandoffTTL = time.Minute
+
+// oidcRolePriority — при нескольких подходящих группах выбирается самая сильная роль.
+var oidcRolePriority = []string{
+	auth.RoleAdmin, auth.RoleOrganiser, auth.RoleJudge, auth.RoleCoach, auth.RoleAthlete, auth.RoleViewer,
+}
+
+// StartOIDC начинает вход через провайдера и возвращает адрес его страницы входа и state.
+// state нужно связать с браузером (cookie), чтобы возврат от провайдера принимался только в нем.
+// linkUserID не 0 — провайдер привязывается к учетной записи этого пользователя.
+func (s *AuthService) StartOIDC(ctx context.Context, linkUserID int) (authURL, state string, err error) {
+	if s.oidc == nil {
+		return "", "", repository.NotFound("вход через OIDC не настроен")
+	}
+
+	state, stateHash, err := auth.NewRefreshToken()
+	if err != nil {
+		return "", "", err
+	}
+	nonce, _, err := auth.NewRefreshToken()
+	if err != nil {
+		return "", "", err
+	}
+	verifier, challenge, err := auth.NewPKCE()
+	if err != nil {
+		return "", "", err
+	}
+
+	login := &repository.OIDCLogin{
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		LinkUserID:   linkUserID,
+		ExpiresAt:    time.Now().Add(OIDCLoginTTL),
+	}
+	if err := s.identities.CreateLogin(ctx, stateHash, login); err != nil {
+		return "", "", err
+	}
+	authURL, err = s.oidc.AuthCodeURL(ctx, state, nonce, challenge)
+	if err != nil {
+		return "", "", err
+	}
+	return authURL, state, nil
+}
+
+// CompleteOIDC завершает вход после возврата от провайдера: проверяет state, обменивает код
+// (с PKCE code_verifier) на ID-токен и находит, привязывает или создает локального пользователя.
+// Токены сразу не выпускаются: возвращается одноразовый код для ExchangeOIDCCode.
+// Как и при входе по паролю, при подключенной 2FA вместо кода возвращается *LoginChallenge.
+func (s *AuthService) CompleteOIDC(ctx context.Context, state, code string) (string, *LoginChallenge, error) {
+	if s.oidc == nil {
+		return "", nil, repository.NotFound("вход через OIDC не настроен")
+	}
+
+	login, err := s.identities.ConsumeLogin(ctx, auth.HashToken(state))
+	if err != nil {
+		return "", nil, err
+	}
+	identity, err := s.oidc.Exchange(ctx, code, login.CodeVerifier, login.Nonce)
+	if err != nil {
+		log.Printf("Ошибка входа через OIDC: %v", err)
+		return "", nil, unauthorized("провайдер не подтвердил вход")
+	}
+
+	link, err := s.identities.GetBySubject(ctx, identity.Issuer, identity.Subject)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var user *repository.User
+	switch {
+	case login.LinkUserID != 0:
+		// Привязка из профиля: учетная запись провайдера не должна принадлежать другому пользователю
+		if link != nil && link.UserID != login.LinkUserID {
+			return "", nil, repository.Conflict("эта учетная запись провайдера уже привязана к другому пользователю")
+		}
+		if user, err = s.repo.GetByID(ctx, login.LinkUserID); err != nil {
+			return "", nil, err
+		}
+		if link == nil {
+			err = s.identities.Link(ctx, &repository.UserIdentity{
+				UserID: user.ID, Issuer: identity.Issuer, Subject: identity.Subject, Email: identity.Email,
+			})
+			if err != nil {
+				return "", nil, err
+			}
+			log.Printf("Пользователь %s привязал вход через OIDC (%s)", user.Username, identity.Subject)
+		}
+	case link != nil:
+		if user, err = s.repo.GetByID(ctx, link.UserID); err != nil {
+			return "", nil, err
+		}
+		if err := s.identities.Touch(ctx, link.ID); err != nil {
+			log.Printf("Ошибка обновления внешней учетной записи: %v", err)
+		}
+	default:
+		if user, err = s.provisionOIDCUser(ctx, identity); err != nil {
+			return "", nil, err
+		}
+	}
+
+	if !user.IsActive {
+		return "", nil, forbidden("учетная запись заблокирована")
+	}
+	if err := s.syncOIDCRole(ctx, user, identity); err != nil {
+		return "", nil, err
+	}
+
+	// Провайдер подтвердил личность, но локальная 2FA, если подключена, все равно требуется
+	if user.TwoFactorEnabled {
+		challenge, err := s.mfa.StartLogin(ctx, user.ID, "")
+		if err != nil {
+			return "", nil, err
+		}
+		return "", challenge, nil
+	}
+
+	handoff, handoffHash, err := auth.NewRefreshToken()
+	if err != nil {
+		return "", nil, err
+	}
+	if err := s.identities.CreateHandoff(ctx, user.ID, handoffHash, time.Now().Add(oidcHandoffTTL)); err != nil {
+		return "", nil, err
+	}
+	return handoff, nil, nil
+}
+
+// ExchangeOIDCCode обменивает одноразовый код из CompleteOIDC на пару токенов.
+func (s *AuthService) ExchangeOIDCCode(ctx context.Context, code string) (*TokenPair, error) {
+	userID, err := s.identities.ConsumeHandoff(ctx, auth.HashToken(code))
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, unauthorized("код входа через OIDC не найден или истек, начните заново")
+	}
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive {
+		return nil, forbidden("учетная запись заблокирована")
+	}
+
+	familyID, err := auth.NewFamilyID()
+	if err != nil {
+		return nil, err
+	}
+	pair, err := s.issueTokens(ctx, user, familyID)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Успешный вход через OIDC: %s (Роль: %s)", user.Username, user.Role)
+	return pair, nil
+}
+
+// ListIdentities возвращает внешние учетные записи текущего пользователя.
+func (s *AuthService) ListIdentities(ctx context.Context, claims *auth.Claims) ([]repository.UserIdentity, error) {
+	return s.identities.ListByUser(ctx, claims.UserID)
+}
+
+// UnlinkIdentity отвязывает внешнюю учетную запись. Вход по паролю остается
+// (пользователю, созданному через OIDC, пароль можно задать через сброс по email).
+func (s *AuthService) UnlinkIdentity(ctx context.Context, claims *auth.Claims, id int) error {
+	return s.identities.Unlink(ctx, claims.UserID, id)
+}
+
+// provisionOIDCUser создает учетную запись при первом входе через провайдера.
+// Существующий аккаунт с тем же email автоматически не привязывается: иначе владелец
+// учетной записи у провайдера мог бы захватить чужой локальный аккаунт.
+func (s *AuthService) provisionOIDCUser(ctx context.Context, identity *auth.OIDCIdentity) (*repository.User, error) {
+	if !s.cfg.OIDCAutoProvision {
+		return nil, forbidden("учетная запись не найдена: войдите по паролю и привяжите провайдера в профиле")
+	}
+	if validateEmail(identity.Email) != nil {
+		return nil, unauthorized("провайдер не передал email пользователя")
+	}
+	if _, err := s.repo.GetByEmail(ctx, identity.Email); err == nil {
+		return nil, repository.Conflict("пользователь с email '%s' уже зарегистрирован: войдите по паролю и привяжите провайдера в профиле", identity.Email)
+	}
+
+	// Пароль случайный и никому не известен: входить можно через провайдера или задать пароль сбросом
+	password, _, err := auth.NewRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	hash, err := hashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	role := s.mappedRole(identity.Groups)
+	if role == "" {
+		role = s.cfg.OIDCDefaultRole
+	}
+	if !auth.IsValidRole(role) {
+		role = auth.RoleViewer
+	}
+
+	user := &repository.User{
+		Username:     s.uniqueUsername(ctx, identity),
+		Email:        identity.Email,
+		PasswordHash: hash,
+		Role:         role,
+	}
+	if err := s.repo.CreateUser(ctx, user); err != nil {
+		return nil, err
+	}
+
+	// Подтвержденный провайдером email повторно не проверяем
+	if identity.EmailVerified {
+		if err := s.repo.SetEmailVerified(ctx, user.ID); err != nil {
+			return nil, err
+		}
+		user.EmailVerified = true
+	} else if err := s.mail.SendVerification(ctx, user); err != nil {
+		log.Printf("Ошибка отправки письма подтверждения для %s: %v", user.Username, err)
+	}
+
+	err = s.identities.Link(ctx, &repository.UserIdentity{
+		UserID: user.ID, Issuer: identity.Issuer, Subject: identity.Subject, Email: identity.Email,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Создан пользователь %s по входу через OIDC (Роль: %s)", user.Username, user.Role)
+	return user, nil
+}
+
+// syncOIDCRole обновляет глобальную роль по группам провайдера. Если ни одна группа
+// не сопоставлена роли, роль не меняется: ее можно назначить вручную.
+func (s *AuthService) syncOIDCRole(ctx context.Context, user *repository.User, identity *auth.OIDCIdentity) error {
+	role := s.mappedRole(identity.Groups)
+	if role == "" || role == user.Role {
+		return nil
+	}
+
+	log.Printf("Роль %s изменена по группам провайдера: %s → %s", user.Username, user.Role, role)
+	user.Role = role
+	return s.repo.Update(ctx, user)
+}
+
+// mappedRole возвращает самую сильную роль, сопоставленную группам пользователя.
+func (s *AuthService) mappedRole(groups []string) string {
+	matched := make(map[string]bool)
+	for _, g := range groups {
+		if role, ok := s.oidcRoles[g]; ok {
+			matched[role] = true
+		}
+	}
+	for _, role := range oidcRolePriority {
+		if matched[role] {
+			return role
+		}
+	}
+	return ""
+}
+
+// uniqueUsername строит имя пользователя из preferred_username или email и добавляет
+// числовой суффикс, если имя занято.
+func (s *AuthService) uniqueUsername(ctx context.Context, identity *auth.OIDCIdentity) string {
+	base := identity.Username
+	if base == "" {
+		base = strings.SplitN(identity.Email, "@", 2)[0]
+	}
+	base = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '.' || r == '_' || r == '-' {
+			return r
+		}
+		return -1
+	}, base)
+	if runes := []rune(base); len(runes) > 40 {
+		base = string(runes[:40])
+	}
+	if base == "" {
+		base = "user"
+	}
+
+	name := base
+	for i := 2; ; i++ {
+		if _, err := s.repo.GetByUsername(ctx, name); err != nil {
+			return name
+		}
+		name = fmt.Sprintf("%s-%d", base, i)
+	}
+}
+
+// parseRoleMapping разбирает OIDC_ROLE_MAPPING вида "federation-admins=admin,judges=judge".
+// Неизвестные роли пропускаются с предупреждением.
+func parseRoleMapping(mapping string) map[string]string {
+	roles := make(map[string]string)
+	for _, pair := range strings.Split(mapping, ",") {
+		group, role, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			continue
+		}
+		group, role = strings.TrimSpace(group), strings.TrimSpace(role)
+		if !auth.IsValidRole(role) {
+			log.Printf("ВНИМАНИЕ: OIDC_ROLE_MAPPING: неизвестная роль '%s' для группы '%s'", role, group)
+			continue
+		}
+		roles[group] = role
+	}
+	return roles
+}
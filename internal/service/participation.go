@@ -3,16 +3,24 @@ package service
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"sport-manager/internal/repository"
 )
 
+// defaultQualifyingPeriod — период, за который ищется подтверждение заявочного
+// результата, если у дисциплины нет отборочного норматива.
+const defaultQualifyingPeriod = 365 * 24 * time.Hour
+
 // ParticipationService управляет логикой регистрации атлетов на соревнования.
 // Он координирует работу нескольких репозиториев для обеспечения целостности связей.
 type ParticipationService struct {
 	repo            *repository.ParticipationRepository
 	athleteRepo     *repository.AthleteRepository
 	competitionRepo *repository.CompetitionRepository
+	entryRepo       *repository.EntryStandardRepository
+	resultRepo      *repository.ResultRepository
+	weighInRepo     *repository.WeighInRepository
 }
 
 // NewParticipationService инициализирует сервис со всеми необходимыми зависимостями.
@@ -20,11 +28,17 @@ func NewParticipationService(
 	repo *repository.ParticipationRepository,
 	athleteRepo *repository.AthleteRepository,
 	competitionRepo *repository.CompetitionRepository,
+	entryRepo *repository.EntryStandardRepository,
+	resultRepo *repository.ResultRepository,
+	weighInRepo *repository.WeighInRepository,
 ) *ParticipationService {
 	return &ParticipationService{
 		repo:            repo,
 		athleteRepo:     athleteRepo,
 		competitionRepo: competitionRepo,
+		entryRepo:       entryRepo,
+		resultRepo:      resultRepo,
+		weighInRepo:     weighInRepo,
 	}
 }
 
@@ -33,50 +47,253 @@ func NewParticipationService(
 // Create регистрирует спортсмена на соревнование, предварительно проверяя их существование.
 func (s *ParticipationService) Create(ctx context.Context, p *repository.Participation) error {
 	// 1. Первичная валидация входных данных
-	if p.AthleteID == 0 || p.CompetitionID == 0 {
-		return fmt.Errorf("ошибка валидации: ID атлета и ID соревнования обязательны")
+	var v validator
+	v.RequiredID("athlete_id", p.AthleteID)
+	v.RequiredID("competition_id", p.CompetitionID)
+	if p.SeedResult != nil {
+		v.Check(p.Event != "", "event", CodeRequired, "для заявочного результата необходимо указать дисциплину")
+		v.NotNegative("seed_result", *p.SeedResult)
+	}
+	v.MaxLen("event", p.Event, 100)
+	v.NotNegative("weight_class_id", float64(p.WeightClassID))
+	if err := v.Err(); err != nil {
+		return err
 	}
 
 	// 2. Проверка существования атлета
 	// Это предотвращает создание "битых" связей в базе данных
-	if _, err := s.athleteRepo.GetByID(ctx, p.AthleteID); err != nil {
-		return fmt.Errorf("service: указанный атлет не найден: %w", err)
+	athlete, err := s.athleteRepo.GetByID(ctx, p.AthleteID)
+	if err != nil {
+		return referenceError(err, "athlete_id", "спортсмен не найден")
 	}
 
 	// 3. Проверка существования соревнования
 	if _, err := s.competitionRepo.GetByID(ctx, p.CompetitionID); err != nil {
-		return fmt.Errorf("service: указанное соревнование не найдено: %w", err)
+		return referenceError(err, "competition_id", "соревнование не найдено")
+	}
+
+	// 4. Проверка отборочного норматива и подтверждение заявочного результата.
+	// Флаг подтверждения выставляет только система, значение из запроса не учитывается
+	p.SeedVerified = false
+	if p.Event != "" {
+		if err := s.checkEntry(ctx, p, athlete.Gender); err != nil {
+			return err
+		}
+	}
+
+	// 5. Весовая категория должна принадлежать соревнованию и соответствовать полу спортсмена
+	if p.WeightClassID != 0 {
+		class, err := s.weighInRepo.GetClass(ctx, p.WeightClassID)
+		if err != nil {
+			return referenceError(err, "weight_class_id", "весовая категория не найдена")
+		}
+		if class.CompetitionID != p.CompetitionID || class.Gender != athlete.Gender {
+			return invalidField("weight_class_id", CodeInvalid, "весовая категория '%s' не подходит для участника", class.Name)
+		}
 	}
 
-	// 4. Сохранение записи в БД
+	// 6. Сохранение записи в БД
 	return s.repo.Create(ctx, p)
 }
 
-// ListAll возвращает расширенный список участий (с именами атлетов и названиями турниров).
-func (s *ParticipationService) ListAll(ctx context.Context) ([]repository.Participation, error) {
-	participations, err := s.repo.ListAll(ctx)
+// checkEntry проверяет заявочный результат по отборочному нормативу дисциплины
+// и сверяет его с результатами спортсмена, сохраненными в системе.
+// Если подтверждающего результата нет, заявка принимается с флагом SeedVerified = false.
+func (s *ParticipationService) checkEntry(ctx context.Context, p *repository.Participation, gender string) error {
+	standard, err := s.entryRepo.Find(ctx, p.CompetitionID, p.Event, gender)
+	if err != nil {
+		return fmt.Errorf("service: не удалось проверить отборочный норматив: %w", err)
+	}
+
+	lowerIsBetter := true
+	period := defaultQualifyingPeriod
+	if standard != nil {
+		if p.SeedResult == nil {
+			return invalidField("seed_result", CodeRequired, "для дисциплины '%s' требуется заявочный результат", p.Event)
+		}
+		if !meetsThreshold(*p.SeedResult, standard.ResultThreshold, standard.LowerIsBetter) {
+			return invalidField("seed_result", CodeOutOfRange, "заявочный результат %.2f не выполняет норматив %.2f",
+				*p.SeedResult, standard.ResultThreshold)
+		}
+		lowerIsBetter = standard.LowerIsBetter
+		period = time.Duration(standard.QualifyingPeriodDays) * 24 * time.Hour
+	}
+
+	if p.SeedResult == nil {
+		p.SeedVerified = false
+		return nil
+	}
+
+	// Результат подтвержден, если в системе есть результат не хуже заявленного за отборочный период
+	best, err := s.resultRepo.BestResult(ctx, p.AthleteID, p.Event, time.Now().Add(-period), lowerIsBetter)
+	if err != nil {
+		return fmt.Errorf("service: не удалось проверить заявочный результат: %w", err)
+	}
+	p.SeedVerified = best != nil && meetsThreshold(*best, *p.SeedResult, lowerIsBetter)
+	return nil
+}
+
+// meetsThreshold сообщает, что значение не хуже порога с учетом направления сравнения.
+func meetsThreshold(value, threshold float64, lowerIsBetter bool) bool {
+	if lowerIsBetter {
+		return value <= threshold
+	}
+	return value >= threshold
+}
+
+// List возвращает страницу расширенного списка участий (с именами атлетов и названиями турниров)
+// по фильтру; по умолчанию — по ID.
+func (s *ParticipationService) List(ctx context.Context, f repository.ParticipationFilter, req PageRequest) ([]repository.Participation, *repository.PageInfo, error) {
+	v := &validator{}
+	page := pageFor(req, s.repo.SortFields(), "id", v)
+	if err := v.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	participations, info, err := s.repo.List(ctx, f, page)
 	if err != nil {
-		return nil, fmt.Errorf("service: не удалось получить список регистраций: %w", err)
+		return nil, nil, fmt.Errorf("service: не удалось получить список регистраций: %w", err)
 	}
-	return participations, nil
+	return participations, info, nil
 }
 
 // UpdatePlace фиксирует результат (место), занятое атлетом.
 func (s *ParticipationService) UpdatePlace(ctx context.Context, id int, place int) error {
 	if id <= 0 {
-		return fmt.Errorf("ошибка: некорректный ID записи")
+		return invalidField("id", CodeInvalid, "некорректный ID записи")
 	}
 	if place <= 0 {
-		return fmt.Errorf("ошибка валидации: занятое место должно быть положительным числом")
+		return invalidField("place", CodeOutOfRange, "занятое место должно быть положительным числом")
 	}
 
 	return s.repo.UpdatePlace(ctx, id, place)
 }
 
-// Delete аннулирует участие атлета в соревновании.
+// Delete аннулирует участие атлета в соревновании (мягкое удаление).
 func (s *ParticipationService) Delete(ctx context.Context, id int) error {
 	if id <= 0 {
-		return fmt.Errorf("ошибка: некорректный ID для удаления")
+		return invalidField("id", CodeInvalid, "некорректный ID для удаления")
 	}
 	return s.repo.Delete(ctx, id)
 }
+
+// Restore восстанавливает аннулированное участие.
+func (s *ParticipationService) Restore(ctx context.Context, id int) error {
+	if id <= 0 {
+		return invalidField("id", CodeInvalid, "некорректный ID записи")
+	}
+	return s.repo.Restore(ctx, id)
+}
+
+// Purge безвозвратно удаляет аннулированное участие вместе с результатом.
+func (s *ParticipationService) Purge(ctx context.Context, id int) error {
+	if id <= 0 {
+		return invalidField("id", CodeInvalid, "некорректный ID записи")
+	}
+	return s.repo.Purge(ctx, id)
+}
+
+// --- ПОСЕВ ПО ЗАБЕГАМ ---
+
+// HeatEntry — участник забега с назначенной дорожкой.
+type HeatEntry struct {
+	Lane int `json:"lane"`
+	repository.Participation
+}
+
+// Heat — один забег (заплыв, группа) дисциплины.
+type Heat struct {
+	Number  int         `json:"number"`
+	Entries []HeatEntry `json:"entries"`
+}
+
+// maxLanes — наибольшее число дорожек в забеге (на стандартном стадионе их 8–9, в бассейне — до 10)
+const maxLanes = 12
+
+// SeedHeats распределяет участников дисциплины по забегам на основе заявочных результатов.
+// Сильнейшие участники попадают в последний забег, внутри забега лучшие получают центральные дорожки.
+// Участники без заявочного результата распределяются в первые забеги.
+func (s *ParticipationService) SeedHeats(ctx context.Context, competitionID int, event string, lanes int) ([]Heat, error) {
+	var v validator
+	v.RequiredID("id", competitionID)
+	v.Required("event", event)
+	v.Positive("lanes", float64(lanes))
+	v.Check(lanes <= maxLanes, "lanes", CodeOutOfRange, "не больше %d дорожек", maxLanes)
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+
+	lowerIsBetter, err := eventDirection(ctx, s.entryRepo, competitionID, event)
+	if err != nil {
+		return nil, err
+	}
+
+	// Список уже отсортирован от лучшего заявочного результата к худшему
+	entries, err := s.repo.ListByCompetition(ctx, competitionID, event, lowerIsBetter)
+	if err != nil {
+		return nil, fmt.Errorf("service: не удалось получить участников: %w", err)
+	}
+	if len(entries) == 0 {
+		return []Heat{}, nil
+	}
+
+	return seedHeats(entries, lanes), nil
+}
+
+// seedHeats раскладывает участников, отсортированных от лучшего к худшему, по забегам.
+// Участники делятся между забегами поровну (9 участников на 8 дорожках — забеги по 4 и 5),
+// лишние достаются последним забегам; забеги заполняются с последнего.
+func seedHeats(entries []repository.Participation, lanes int) []Heat {
+	heatCount := (len(entries) + lanes - 1) / lanes
+	heats := make([]Heat, heatCount)
+	laneOrder := centerOutLanes(lanes)
+
+	base, extra := len(entries)/heatCount, len(entries)%heatCount
+	next := 0
+	for heatIdx := heatCount - 1; heatIdx >= 0; heatIdx-- {
+		size := base
+		if heatIdx >= heatCount-extra {
+			size++
+		}
+		for i, p := range entries[next : next+size] {
+			heats[heatIdx].Entries = append(heats[heatIdx].Entries, HeatEntry{
+				Lane:          laneOrder[i],
+				Participation: p,
+			})
+		}
+		next += size
+		heats[heatIdx].Number = heatIdx + 1
+	}
+	return heats
+}
+
+// eventDirection определяет, лучше ли меньший результат в дисциплине (время),
+// по отборочному нормативу соревнования. Без норматива считаем дисциплину беговой.
+func eventDirection(ctx context.Context, entryRepo *repository.EntryStandardRepository, competitionID int, event string) (bool, error) {
+	standards, err := entryRepo.ListByCompetition(ctx, competitionID)
+	if err != nil {
+		return false, fmt.Errorf("service: не удалось получить нормативы: %w", err)
+	}
+	for _, st := range standards {
+		if st.Event == event {
+			return st.LowerIsBetter, nil
+		}
+	}
+	return true, nil
+}
+
+// centerOutLanes возвращает порядок дорожек от центра к краям: для 8 дорожек — 4, 5, 3, 6, 2, 7, 1, 8.
+func centerOutLanes(lanes int) []int {
+	order := make([]int, 0, lanes)
+	center := (lanes + 1) / 2
+	order = append(order, center)
+	for offset := 1; len(order) < lanes; offset++ {
+		if lane := center + offset; lane <= lanes {
+			order = append(order, lane)
+		}
+		if lane := center - offset; lane >= 1 && len(order) < lanes {
+			order = append(order, lane)
+		}
+	}
+	return order
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"sport-manager/internal/repository"
+)
+
+func TestCenterOutLanes(t *testing.T) {
+	tests := []struct {
+		lanes int
+		want  []int
+	}{
+		{1, []int{1}},
+		{2, []int{1, 2}},
+		{5, []int{3, 4, 2, 5, 1}},
+		{6, []int{3, 4, 2, 5, 1, 6}},
+		{8, []int{4, 5, 3, 6, 2, 7, 1, 8}},
+	}
+	for _, tt := range tests {
+		if got := centerOutLanes(tt.lanes); !slices.Equal(got, tt.want) {
+			t.Errorf("centerOutLanes(%d) = %v, want %v", tt.lanes, got, tt.want)
+		}
+	}
+}
+
+func TestSeedHeats(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries int
+		lanes   int
+		sizes   []int // Число участников в забегах по порядку
+	}{
+		{"один неполный забег", 3, 8, []int{3}},
+		{"ровно один забег", 8, 8, []int{8}},
+		{"один лишний участник", 9, 8, []int{4, 5}},
+		{"два полных забега", 16, 8, []int{8, 8}},
+		{"остаток в последних забегах", 17, 8, []int{5, 6, 6}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Участники отсортированы от лучшего к худшему: ID совпадает с местом в посеве
+			entries := make([]repository.Participation, tt.entries)
+			for i := range entries {
+				entries[i].ID = i + 1
+			}
+
+			heats := seedHeats(entries, tt.lanes)
+			sizes := make([]int, len(heats))
+			for i, h := range heats {
+				sizes[i] = len(h.Entries)
+				if h.Number != i+1 {
+					t.Errorf("забег %d получил номер %d", i+1, h.Number)
+				}
+				// Внутри забега дорожки раздаются от центра: сильнейший — на центральной
+				if want := centerOutLanes(tt.lanes)[:len(h.Entries)]; !slices.Equal(heatLanes(h), want) {
+					t.Errorf("дорожки забега %d = %v, want %v", h.Number, heatLanes(h), want)
+				}
+			}
+			if !slices.Equal(sizes, tt.sizes) {
+				t.Fatalf("размеры забегов = %v, want %v", sizes, tt.sizes)
+			}
+
+			last := heats[len(heats)-1]
+			if last.Entries[0].ID != 1 {
+				t.Errorf("лучший участник в последнем забеге = %d, want 1", last.Entries[0].ID)
+			}
+		})
+	}
+}
+
+func TestSeedHeatsLanesRange(t *testing.T) {
+	// Проверка параметров выполняется до обращения к базе, поэтому хватает пустого сервиса
+	var s ParticipationService
+	for _, lanes := range []int{0, -1, maxLanes + 1, 2000000000} {
+		_, err := s.SeedHeats(context.Background(), 1, "100m", lanes)
+		var ve *ValidationError
+		if !errors.As(err, &ve) || len(ve.Fields) != 1 || ve.Fields[0].Field != "lanes" {
+			t.Errorf("SeedHeats(lanes=%d) error = %v, want ошибку валидации поля lanes", lanes, err)
+		}
+	}
+}
+
+func heatLanes(h Heat) []int {
+	lanes := make([]int, len(h.Entries))
+	for i, e := range h.Entries {
+		lanes[i] = e.Lane
+	}
+	return lanes
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+)
+
+// mergePatch разбирает документ JSON Merge Patch (RFC 7396) поле за полем.
+// Отсутствующее поле не меняется, null сбрасывает необязательное поле в пустое значение.
+// Ошибки типов копятся во встроенном validator вместе с правилами, которые проверяет сервис.
+type mergePatch struct {
+	validator
+	fields   map[string]json.RawMessage
+	readOnly map[string]bool
+	used     map[string]bool
+}
+
+// newMergePatch готовит разбор патча; readOnly — поля, которые есть в ресурсе, но менять их нельзя.
+func newMergePatch(fields map[string]json.RawMessage, readOnly ...string) *mergePatch {
+	p := &mergePatch{fields: fields, readOnly: map[string]bool{}, used: map[string]bool{}}
+	for _, name := range readOnly {
+		p.readOnly[name] = true
+	}
+	return p
+}
+
+// value возвращает сырое значение поля; ok == false — поля нет в патче или оно не может быть null.
+func (p *mergePatch) value(name string, nullable bool) (raw json.RawMessage, isNull, ok bool) {
+	p.used[name] = true
+	raw, present := p.fields[name]
+	if !present {
+		return nil, false, false
+	}
+	if string(raw) == "null" {
+		if !nullable {
+			p.Add(name, CodeRequired, "обязательное поле, null недопустим")
+			return nil, true, false
+		}
+		return nil, true, true
+	}
+	return raw, false, true
+}
+
+// decode разбирает значение поля в dst, запоминая ошибку типа с сообщением message.
+func (p *mergePatch) decode(name string, raw json.RawMessage, dst interface{}, message string) bool {
+	if err := json.Unmarshal(raw, dst); err != nil {
+		p.Add(name, CodeInvalid, message)
+		return false
+	}
+	return true
+}
+
+// String возвращает новое значение строкового поля; null у необязательного поля — пустая строка.
+func (p *mergePatch) String(name string, nullable bool) *string {
+	raw, isNull, ok := p.value(name, nullable)
+	if !ok {
+		return nil
+	}
+	var v string
+	if !isNull && !p.decode(name, raw, &v, "должно быть строкой") {
+		return nil
+	}
+	return &v
+}
+
+// Int возвращает новое значение целочисленного поля; null у необязательного поля — 0.
+func (p *mergePatch) Int(name string, nullable bool) *int {
+	raw, isNull, ok := p.value(name, nullable)
+	if !ok {
+		return nil
+	}
+	var v int
+	if !isNull && !p.decode(name, raw, &v, "должно быть целым числом") {
+		return nil
+	}
+	return &v
+}
+
+// Float возвращает новое значение числового поля (поле обязательное).
+func (p *mergePatch) Float(name string) *float64 {
+	raw, _, ok := p.value(name, false)
+	if !ok {
+		return nil
+	}
+	var v float64
+	if !p.decode(name, raw, &v, "должно быть числом") {
+		return nil
+	}
+	return &v
+}
+
+// Bool возвращает новое значение логического поля (поле обязательное).
+func (p *mergePatch) Bool(name string) *bool {
+	raw, _, ok := p.value(name, false)
+	if !ok {
+		return nil
+	}
+	var v bool
+	if !p.decode(name, raw, &v, "должно быть true или false") {
+		return nil
+	}
+	return &v
+}
+
+// Date возвращает новое значение поля-даты: RFC 3339 или ГГГГ-ММ-ДД (поле обязательное).
+func (p *mergePatch) Date(name string) *time.Time {
+	raw, _, ok := p.value(name, false)
+	if !ok {
+		return nil
+	}
+	var s string
+	if !p.decode(name, raw, &s, "должно быть датой") {
+		return nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return &t
+	}
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		p.Add(name, CodeInvalid, "должно быть датой в формате ГГГГ-ММ-ДД или RFC 3339")
+		return nil
+	}
+	return &t
+}
+
+// Err возвращает все нарушения патча; поля, которые сервис не запросил, считаются неизвестными.
+func (p *mergePatch) Err() error {
+	names := make([]string, 0, len(p.fields))
+	for name := range p.fields {
+		names = append(names, name)
+	}
+	sort.Strings(names) // Стабильный порядок ошибок при нескольких лишних полях
+	for _, name := range names {
+		if p.readOnly[name] {
+			p.Add(name, CodeReadOnly, "поле нельзя изменить")
+		} else if !p.used[name] {
+			p.Add(name, CodeUnknown, "неизвестное поле")
+		}
+	}
+	return p.validator.Err()
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestMergePatch(t *testing.T) {
+	fields := map[string]json.RawMessage{
+		"event":   json.RawMessage(`null`),
+		"place":   json.RawMessage(`3`),
+		"score":   json.RawMessage(`null`),
+		"notes":   json.RawMessage(`42`),
+		"id":      json.RawMessage(`7`),
+		"surname": json.RawMessage(`"Иванов"`),
+	}
+	p := newMergePatch(fields, "id")
+
+	if event := p.String("event", true); event == nil || *event != "" {
+		t.Errorf("null у необязательного поля должен сбрасывать его в пустое значение, got %v", event)
+	}
+	if place := p.Int("place", true); place == nil || *place != 3 {
+		t.Errorf("place = %v, want 3", place)
+	}
+	if score := p.Float("score"); score != nil {
+		t.Errorf("null у обязательного поля не должен давать значение, got %v", *score)
+	}
+	if notes := p.String("notes", true); notes != nil {
+		t.Errorf("число вместо строки не должно давать значение, got %q", *notes)
+	}
+	if category := p.String("category", true); category != nil {
+		t.Errorf("отсутствующее поле не должно меняться, got %q", *category)
+	}
+
+	var verr *ValidationError
+	if err := p.Err(); !errors.As(err, &verr) {
+		t.Fatalf("Err() = %v, want *ValidationError", err)
+	}
+	want := map[string]string{
+		"score":   CodeRequired,
+		"notes":   CodeInvalid,
+		"id":      CodeReadOnly,
+		"surname": CodeUnknown,
+	}
+	got := make(map[string]string, len(verr.Fields))
+	for _, f := range verr.Fields {
+		got[f.Field] = f.Code
+	}
+	if len(got) != len(want) {
+		t.Errorf("ошибки полей = %v, want %v", got, want)
+	}
+	for field, code := range want {
+		if got[field] != code {
+			t.Errorf("код ошибки поля %q = %q, want %q", field, got[field], code)
+		}
+	}
+}
+
+func TestMergePatchValid(t *testing.T) {
+	p := newMergePatch(map[string]json.RawMessage{"score": json.RawMessage(`9.58`)}, "id")
+	if score := p.Float("score"); score == nil || *score != 9.58 {
+		t.Errorf("score = %v, want 9.58", score)
+	}
+	if err := p.Err(); err != nil {
+		t.Errorf("Err() = %v, want nil", err)
+	}
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"sport-manager/internal/repository"
+)
+
+// purgeTarget — таблица с мягким удалением, которую обслуживает очистка.
+type purgeTarget struct {
+	name        string
+	listDeleted func(ctx context.Context, cutoff time.Time) ([]int, error)
+	purge       func(ctx context.Context, id int, cutoff time.Time) error
+}
+
+// PurgeJob периодически безвозвратно удаляет записи, мягко удаленные дольше срока хранения.
+// Спортсмены и соревнования, у которых остались неудаленные или недавно удаленные участия,
+// пропускаются с записью в лог: удалить их вместе с участиями и результатами можно
+// только явным запросом администратора.
+// Как и любое удаление, очистка попадает в журнал аудита.
+type PurgeJob struct {
+	targets   []purgeTarget
+	retention time.Duration
+}
+
+// NewPurgeJob создает задачу очистки. Участия очищаются первыми, чтобы их собственный
+// срок хранения отсчитывался от их удаления, а не от удаления спортсмена или соревнования.
+func NewPurgeJob(
+	participations *repository.ParticipationRepository,
+	athletes *repository.AthleteRepository,
+	competitions *repository.CompetitionRepository,
+	retention time.Duration,
+) *PurgeJob {
+	return &PurgeJob{
+		targets: []purgeTarget{
+			{name: "участия", listDeleted: participations.ListDeletedBefore, purge: func(ctx context.Context, id int, _ time.Time) error {
+				return participations.Purge(ctx, id)
+			}},
+			{name: "спортсмены", listDeleted: athletes.ListDeletedBefore, purge: athletes.PurgeUnreferenced},
+			{name: "соревнования", listDeleted: competitions.ListDeletedBefore, purge: competitions.PurgeUnreferenced},
+		},
+		retention: retention,
+	}
+}
+
+// Run выполняет очистку сразу и затем с интервалом interval, пока не отменен ctx.
+func (j *PurgeJob) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		j.PurgeExpired(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PurgeExpired удаляет записи, срок хранения которых истек. Ошибка по одной записи
+// не останавливает очистку остальных. Возвращает число удаленных записей.
+func (j *PurgeJob) PurgeExpired(ctx context.Context) int {
+	cutoff := time.Now().Add(-j.retention)
+	purged := 0
+
+	for _, t := range j.targets {
+		ids, err := t.listDeleted(ctx, cutoff)
+		if err != nil {
+			log.Printf("Очистка удаленных записей (%s): %v", t.name, err)
+			continue
+		}
+		for _, id := range ids {
+			if err := t.purge(ctx, id, cutoff); err != nil {
+				if errors.Is(err, repository.ErrConflict) {
+					log.Printf("Очистка удаленных записей (%s), ID %d пропущен: %v", t.name, id, err)
+				} else {
+					log.Printf("Очистка удаленных записей (%s), ID %d: %v", t.name, id, err)
+				}
+				continue
+			}
+			purged++
+		}
+	}
+
+	if purged > 0 {
+		log.Printf("Очистка удаленных записей: окончательно удалено %d", purged)
+	}
+	return purged
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+
+	"sport-manager/internal/repository"
+)
+
+// RankService реализует бизнес-логику присвоения спортивных разрядов.
+type RankService struct {
+	repo *repository.RankRepository
+}
+
+// NewRankService создает новый экземпляр сервиса разрядов.
+func NewRankService(repo *repository.RankRepository) *RankService {
+	return &RankService{repo: repo}
+}
+
+// --- СПРАВОЧНИК РАЗРЯДОВ ---
+
+// ListRanks возвращает справочник разрядов.
+func (s *RankService) ListRanks(ctx context.Context) ([]repository.Rank, error) {
+	return s.repo.ListRanks(ctx)
+}
+
+// CreateRank проверяет данные и добавляет разряд в справочник.
+func (s *RankService) CreateRank(ctx context.Context, rk *repository.Rank) error {
+	if err := validateRank(rk); err != nil {
+		return err
+	}
+	return s.repo.CreateRank(ctx, rk)
+}
+
+// UpdateRank проверяет данные и обновляет разряд.
+func (s *RankService) UpdateRank(ctx context.Context, rk *repository.Rank) error {
+	if rk.ID <= 0 {
+		return invalidField("id", CodeRequired, "для обновления необходим ID разряда")
+	}
+	if err := validateRank(rk); err != nil {
+		return err
+	}
+	return s.repo.UpdateRank(ctx, rk)
+}
+
+// validateRank проверяет общие для создания и обновления правила.
+func validateRank(rk *repository.Rank) error {
+	var v validator
+	v.Required("name", rk.Name)
+	v.MaxLen("name", rk.Name, 100)
+	v.NotNegative("validity_months", float64(rk.ValidityMonths))
+	return v.Err()
+}
+
+// --- НОРМАТИВЫ ---
+
+// ListStandards возвращает все разрядные нормативы.
+func (s *RankService) ListStandards(ctx context.Context) ([]repository.RankStandard, error) {
+	return s.repo.ListStandards(ctx)
+}
+
+// CreateStandard проверяет и сохраняет новый норматив.
+func (s *RankService) CreateStandard(ctx context.Context, st *repository.RankStandard) error {
+	var v validator
+	v.RequiredID("sport_id", st.SportID)
+	v.RequiredID("rank_id", st.RankID)
+	v.Required("event", st.Event)
+	v.MaxLen("event", st.Event, 100)
+	v.Required("gender", st.Gender)
+	v.OneOf("gender", st.Gender, genders...)
+	v.MaxLen("competition_level", st.CompetitionLevel, 50)
+	// Норматив без условий выполнялся бы любым результатом
+	v.Check(st.ResultThreshold != nil || st.MaxPlace != nil, "result_threshold", CodeRequired,
+		"укажите порог результата или максимальное место")
+	if st.MaxPlace != nil {
+		v.Positive("max_place", float64(*st.MaxPlace))
+	}
+	if err := v.Err(); err != nil {
+		return err
+	}
+	return s.repo.CreateStandard(ctx, st)
+}
+
+// DeleteStandard удаляет норматив.
+func (s *RankService) DeleteStandard(ctx context.Context, id int) error {
+	if id <= 0 {
+		return invalidField("id", CodeInvalid, "некорректный ID для удаления")
+	}
+	return s.repo.DeleteStandard(ctx, id)
+}
+
+// --- ПРЕДЛОЖЕНИЯ И ИСТОРИЯ ---
+
+// ListProposals возвращает предложения о присвоении с фильтром по статусу.
+func (s *RankService) ListProposals(ctx context.Context, status string) ([]repository.RankProposal, error) {
+	switch status {
+	case "", "pending", "approved", "rejected":
+	default:
+		return nil, invalidField("status", CodeEnum, "допустимые значения: pending, approved, rejected")
+	}
+	return s.repo.ListProposals(ctx, status)
+}
+
+// ApproveProposal утверждает присвоение разряда администратором.
+func (s *RankService) ApproveProposal(ctx context.Context, id int, approvedBy string) (*repository.AthleteRank, error) {
+	if id <= 0 {
+		return nil, invalidField("id", CodeInvalid, "некорректный ID предложения")
+	}
+	return s.repo.ApproveProposal(ctx, id, approvedBy)
+}
+
+// RejectProposal отклоняет предложение о присвоении разряда.
+func (s *RankService) RejectProposal(ctx context.Context, id int, decidedBy string) error {
+	if id <= 0 {
+		return invalidField("id", CodeInvalid, "некорректный ID предложения")
+	}
+	return s.repo.RejectProposal(ctx, id, decidedBy)
+}
+
+// AthleteHistory возвращает историю разрядов спортсмена с датами присвоения и окончания.
+func (s *RankService) AthleteHistory(ctx context.Context, athleteID int) ([]repository.AthleteRank, error) {
+	if athleteID <= 0 {
+		return nil, invalidField("id", CodeInvalid, "некорректный ID спортсмена")
+	}
+	return s.repo.ListAthleteRanks(ctx, athleteID)
+}
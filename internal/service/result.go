@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"sport-manager/internal/repository"
+)
+
+// ResultService отвечает за фиксацию результатов и запуск проверки разрядных нормативов.
+type ResultService struct {
+	repo     *repository.ResultRepository
+	rankRepo *repository.RankRepository
+}
+
+// NewResultService создает новый экземпляр сервиса результатов.
+func NewResultService(repo *repository.ResultRepository, rankRepo *repository.RankRepository) *ResultService {
+	return &ResultService{repo: repo, rankRepo: rankRepo}
+}
+
+// --- БИЗНЕС-ЛОГИКА ---
+
+// Save сохраняет результат и возвращает предложение о присвоении разряда,
+// если результат выполняет норматив (иначе nil). expectedVersion — версия перезаписываемого
+// результата из If-Match (0 — результата еще нет, repository.AnyVersion — без проверки).
+func (s *ResultService) Save(ctx context.Context, res *repository.Result, expectedVersion int) (*repository.RankProposal, error) {
+	if res.ParticipationID <= 0 {
+		return nil, invalidField("id", CodeInvalid, "некорректный ID записи об участии")
+	}
+	var v validator
+	v.MaxLen("event", res.Event, 100)
+	v.NotNegative("place", float64(res.Place))
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Save(ctx, res, expectedVersion); err != nil {
+		return nil, fmt.Errorf("service: не удалось сохранить результат: %w", err)
+	}
+	return s.propose(ctx, res.ID), nil
+}
+
+// Patch применяет к существующему результату JSON Merge Patch (RFC 7396): меняются только переданные поля.
+// Как и Save, возвращает предложение о присвоении разряда, если новый результат выполняет норматив.
+func (s *ResultService) Patch(ctx context.Context, participationID int, fields map[string]json.RawMessage, expectedVersion int) (*repository.Result, *repository.RankProposal, error) {
+	if participationID <= 0 {
+		return nil, nil, invalidField("id", CodeInvalid, "некорректный ID записи об участии")
+	}
+
+	p := newMergePatch(fields, "id", "participation_id", "version")
+	patch := repository.ResultPatch{
+		Event: p.String("event", true),
+		Place: p.Int("place", true),
+		Score: p.Float("score"),
+		Notes: p.String("notes", true),
+	}
+	if patch.Event != nil {
+		p.MaxLen("event", *patch.Event, 100)
+	}
+	if patch.Place != nil {
+		p.NotNegative("place", float64(*patch.Place))
+	}
+	if err := p.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	res, err := s.repo.Patch(ctx, participationID, patch, expectedVersion)
+	if err != nil {
+		return nil, nil, fmt.Errorf("service: не удалось обновить результат: %w", err)
+	}
+	return res, s.propose(ctx, res.ID), nil
+}
+
+// propose проверяет сохраненный результат на выполнение разрядных нормативов.
+// Проверка не должна мешать сохранению результата: при ошибке результат уже записан,
+// а предложение можно сформировать позже.
+func (s *ResultService) propose(ctx context.Context, resultID int) *repository.RankProposal {
+	proposal, err := s.rankRepo.ProposeFromResult(ctx, resultID)
+	if err != nil {
+		log.Printf("Ошибка проверки разрядных нормативов для результата %d: %v", resultID, err)
+		return nil
+	}
+	if proposal != nil {
+		log.Printf("Сформировано предложение %d о присвоении разряда атлету %d", proposal.ID, proposal.AthleteID)
+	}
+	return proposal
+}
+
+// GetByParticipationID возвращает результат по ID записи об участии.
+func (s *ResultService) GetByParticipationID(ctx context.Context, participationID int) (*repository.Result, error) {
+	if participationID <= 0 {
+		return nil, invalidField("id", CodeInvalid, "некорректный ID записи об участии")
+	}
+	return s.repo.GetByParticipationID(ctx, participationID)
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"sport-manager/internal/auth"
+	"sport-manager/internal/repository"
+)
+
+// ErrForbidden возвращается, когда у пользователя нет прав на операцию.
+var ErrForbidden = errors.New("недостаточно прав")
+
+// forbidden возвращает ErrForbidden с пояснением, какого права не хватило.
+func forbidden(format string, args ...interface{}) error {
+	return &repository.DomainError{Kind: ErrForbidden, Format: format, Args: args}
+}
+
+// RoleService управляет назначением ролей с областью действия.
+// Выдавать роли могут не только администраторы: организатор соревнования назначает
+// судей своего соревнования, но не может выдать больше прав, чем имеет сам.
+type RoleService struct {
+	repo     *repository.RoleRepository
+	authRepo *repository.AuthRepository
+}
+
+// NewRoleService создает новый экземпляр сервиса ролей.
+func NewRoleService(repo *repository.RoleRepository, authRepo *repository.AuthRepository) *RoleService {
+	return &RoleService{repo: repo, authRepo: authRepo}
+}
+
+// List возвращает роли пользователя. Смотреть можно свои роли или, имея глобальное право role:assign, чужие.
+func (s *RoleService) List(ctx context.Context, viewer *auth.Claims, userID int) ([]repository.RoleAssignment, error) {
+	if viewer == nil || (viewer.UserID != userID && !viewer.HasPermission(auth.PermRoleAssign, auth.Scope{})) {
+		return nil, forbidden("недостаточно прав для просмотра ролей пользователя")
+	}
+	return s.repo.ListByUser(ctx, userID)
+}
+
+// Assign выдает пользователю роль в указанной области.
+func (s *RoleService) Assign(ctx context.Context, assigner *auth.Claims, ra *repository.RoleAssignment) error {
+	var v validator
+	v.Check(auth.IsValidRole(ra.Role), "role", CodeEnum, "неизвестная роль '%s'", ra.Role)
+	switch ra.ScopeType {
+	case auth.ScopeGlobal:
+		ra.ScopeID = 0
+	case auth.ScopeCompetition, auth.ScopeClub:
+		v.Check(ra.ScopeID > 0, "scope_id", CodeRequired, "для области '%s' обязателен scope_id", ra.ScopeType)
+	default:
+		v.Add("scope_type", CodeEnum, "неизвестная область '%s'", ra.ScopeType)
+	}
+	if err := v.Err(); err != nil {
+		return err
+	}
+
+	if !assigner.CanDelegate(toGrant(*ra)) {
+		return forbidden("недостаточно прав для управления этой ролью")
+	}
+	if _, err := s.authRepo.GetByID(ctx, ra.UserID); err != nil {
+		return err
+	}
+
+	ra.GrantedBy = assigner.Username
+	return s.repo.Assign(ctx, ra)
+}
+
+// Revoke отзывает роль. Отозвать можно только ту роль, которую разрешено выдать.
+func (s *RoleService) Revoke(ctx context.Context, assigner *auth.Claims, userID, id int) error {
+	ra, err := s.repo.GetByID(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if !assigner.CanDelegate(toGrant(*ra)) {
+		return forbidden("недостаточно прав для управления этой ролью")
+	}
+	return s.repo.Revoke(ctx, userID, id)
+}
+
+// Grants возвращает роли пользователя в виде, пригодном для записи в токен.
+func (s *RoleService) Grants(ctx context.Context, userID int) ([]auth.Grant, error) {
+	roles, err := s.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	grants := make([]auth.Grant, 0, len(roles))
+	for _, ra := range roles {
+		grants = append(grants, toGrant(ra))
+	}
+	return grants, nil
+}
+
+// toGrant преобразует запись из базы в роль для проверки прав.
+func toGrant(ra repository.RoleAssignment) auth.Grant {
+	return auth.Grant{Role: ra.Role, ScopeType: ra.ScopeType, ScopeID: ra.ScopeID}
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	"sport-manager/internal/repository"
+	"sport-manager/internal/translit"
+)
+
+// Ограничения поиска: подсказки при вводе показывают немного записей,
+// а слишком короткий запрос совпадает почти со всем.
+const (
+	searchDefaultLimit = 10
+	searchMaxLimit     = 50
+	searchMinLength    = 2
+	searchMaxLength    = 100
+)
+
+// SearchService ищет спортсменов, соревнования и клубы по названию с учетом опечаток и транслитерации.
+type SearchService struct {
+	repo *repository.SearchRepository
+}
+
+// NewSearchService создает новый экземпляр сервиса поиска.
+func NewSearchService(repo *repository.SearchRepository) *SearchService {
+	return &SearchService{repo: repo}
+}
+
+// Search ищет записи типов types (пустой список — все) по строке query. Запрос ищется
+// и в исходном написании, и в транслитерации, так что «Ivanov» находит «Иванов» и наоборот.
+func (s *SearchService) Search(ctx context.Context, query string, types []string, limit int) ([]repository.SearchHit, error) {
+	query = strings.TrimSpace(query)
+
+	var v validator
+	v.Required("q", query)
+	v.Check(utf8.RuneCountInString(query) >= searchMinLength, "q", CodeOutOfRange, "не короче %d символов", searchMinLength)
+	v.MaxLen("q", query, searchMaxLength)
+	for _, t := range types {
+		v.OneOf("type", t, repository.SearchTypes...)
+	}
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = searchDefaultLimit
+	}
+	if limit > searchMaxLimit {
+		limit = searchMaxLimit
+	}
+
+	return s.repo.Search(ctx, repository.SearchQuery{
+		Terms: translit.Variants(query),
+		Types: types,
+		Limit: limit,
+	})
+}
@@ -0,0 +1,307 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"sport-manager/internal/repository"
+)
+
+// Способы формирования порядка старта
+const (
+	StartOrderRandom         = "random"          // Жеребьевка
+	StartOrderRanking        = "ranking"         // Сильнейшие стартуют первыми
+	StartOrderReverseRanking = "reverse_ranking" // Сильнейшие стартуют последними
+)
+
+// StartListParams задает параметры формирования стартового протокола.
+type StartListParams struct {
+	Event           string     `json:"event"`
+	Order           string     `json:"order"`
+	FirstStart      *time.Time `json:"first_start,omitempty"` // Время старта первого участника (для раздельного старта)
+	IntervalSeconds int        `json:"interval_seconds"`      // Интервал между стартами; 0 — общий старт
+	DrawID          int        `json:"draw_id"`               // Объявленная жеребьевка для порядка 'random'
+}
+
+// StartListService выдает стартовые номера и формирует стартовые протоколы.
+type StartListService struct {
+	repo            *repository.StartListRepository
+	competitionRepo *repository.CompetitionRepository
+	entryRepo       *repository.EntryStandardRepository
+	draws           *DrawService // Все случайные операции проводятся через проверяемые жеребьевки
+}
+
+// NewStartListService создает новый экземпляр сервиса стартовых протоколов.
+func NewStartListService(
+	repo *repository.StartListRepository,
+	competitionRepo *repository.CompetitionRepository,
+	entryRepo *repository.EntryStandardRepository,
+	draws *DrawService,
+) *StartListService {
+	return &StartListService{repo: repo, competitionRepo: competitionRepo, entryRepo: entryRepo, draws: draws}
+}
+
+// --- НАСТРОЙКИ НОМЕРОВ ---
+
+// GetBibSettings возвращает настройки выдачи номеров соревнования.
+func (s *StartListService) GetBibSettings(ctx context.Context, competitionID int) (*repository.BibSettings, error) {
+	if competitionID <= 0 {
+		return nil, invalidField("id", CodeInvalid, "некорректный ID соревнования")
+	}
+	return s.repo.GetBibSettings(ctx, competitionID)
+}
+
+// SaveBibSettings проверяет и сохраняет настройки выдачи номеров.
+func (s *StartListService) SaveBibSettings(ctx context.Context, settings *repository.BibSettings) error {
+	if settings.StartNumber == 0 {
+		settings.StartNumber = 1
+	}
+	var v validator
+	v.OneOf("mode", settings.Mode, "sequential", "random", "ranges")
+	v.Positive("start_number", float64(settings.StartNumber))
+	if settings.Mode == "ranges" {
+		v.Check(len(settings.Ranges) > 0, "ranges", CodeRequired, "для режима 'ranges' необходимо задать диапазоны")
+	}
+
+	// Диапазоны не должны пересекаться, иначе два участника получат один номер
+	ranges := slices.Clone(settings.Ranges)
+	slices.SortFunc(ranges, func(a, b repository.BibRange) int { return a.From - b.From })
+	for i, br := range ranges {
+		if br.Category == "" || br.From <= 0 || br.To < br.From {
+			v.Add("ranges", CodeInvalid, "некорректный диапазон номеров для категории '%s'", br.Category)
+		}
+		if i > 0 && br.From <= ranges[i-1].To {
+			v.Add("ranges", CodeOutOfRange, "диапазоны '%s' и '%s' пересекаются", ranges[i-1].Category, br.Category)
+		}
+	}
+	if err := v.Err(); err != nil {
+		return err
+	}
+
+	if _, err := s.competitionRepo.GetByID(ctx, settings.CompetitionID); err != nil {
+		return err
+	}
+	return s.repo.SaveBibSettings(ctx, settings)
+}
+
+// --- ВЫДАЧА НОМЕРОВ ---
+
+// AssignBibs выдает стартовые номера участникам соревнования по настройкам.
+// Уже выданные номера сохраняются, если не передан флаг reassign.
+// В режиме 'random' номера разыгрываются в объявленной жеребьевке drawID.
+func (s *StartListService) AssignBibs(ctx context.Context, competitionID int, reassign bool, drawID int) ([]repository.StartListEntry, error) {
+	settings, err := s.GetBibSettings(ctx, competitionID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := s.repo.ListEntries(ctx, competitionID, "")
+	if err != nil {
+		return nil, fmt.Errorf("service: не удалось получить участников: %w", err)
+	}
+
+	// 1. Отделяем участников с уже выданными номерами
+	pending := make([]repository.StartListEntry, 0, len(entries))
+	pendingIDs := make(map[int]bool, len(entries))
+	for _, e := range entries {
+		if e.Bib > 0 && !reassign {
+			continue
+		}
+		pending = append(pending, e)
+		pendingIDs[e.ParticipationID] = true
+	}
+	if len(pending) == 0 {
+		return entries, nil
+	}
+
+	// Занятыми считаются номера всех записей соревнования, в том числе скрытых из стартового протокола
+	// (удаленных и не прошедших взвешивание); освобождаются только номера перераспределяемых участников
+	holders, err := s.repo.BibHolders(ctx, competitionID)
+	if err != nil {
+		return nil, fmt.Errorf("service: не удалось получить занятые номера: %w", err)
+	}
+	used := make(map[int]bool, len(holders))
+	for bib, id := range holders {
+		if !pendingIDs[id] {
+			used[bib] = true
+		}
+	}
+
+	// 2. Распределяем номера согласно режиму
+	bibs := make(map[int]int, len(pending))
+	switch settings.Mode {
+	case "sequential":
+		next := settings.StartNumber
+		for _, e := range pending {
+			for used[next] {
+				next++
+			}
+			bibs[e.ParticipationID] = next
+			used[next] = true
+		}
+
+	case "random":
+		// Пул свободных номеров размером с количество участников разыгрывается между ними
+		pool := make([]int, 0, len(pending))
+		for n := settings.StartNumber; len(pool) < len(pending); n++ {
+			if !used[n] {
+				pool = append(pool, n)
+			}
+		}
+		subjects := make([]int, len(pending))
+		for i, e := range pending {
+			subjects[i] = e.ParticipationID
+		}
+		drawn, err := s.draws.Execute(ctx, drawID, competitionID, DrawKindBib, pool, subjects)
+		if err != nil {
+			return nil, err
+		}
+		for i, id := range subjects {
+			bibs[id] = drawn[i]
+		}
+
+	case "ranges":
+		ranges := make(map[string]repository.BibRange, len(settings.Ranges))
+		for _, br := range settings.Ranges {
+			ranges[br.Category] = br
+		}
+		for _, e := range pending {
+			category := entryCategory(e)
+			br, ok := ranges[category]
+			if !ok {
+				return nil, repository.Conflict("для категории '%s' не задан диапазон номеров", category)
+			}
+			next := br.From
+			for next <= br.To && used[next] {
+				next++
+			}
+			if next > br.To {
+				return nil, repository.Conflict("диапазон номеров категории '%s' исчерпан", category)
+			}
+			bibs[e.ParticipationID] = next
+			used[next] = true
+		}
+	}
+
+	// 3. Сохраняем и возвращаем обновленный протокол
+	if err := s.repo.SaveBibs(ctx, competitionID, bibs); err != nil {
+		return nil, fmt.Errorf("service: не удалось сохранить номера: %w", err)
+	}
+	return s.repo.ListEntries(ctx, competitionID, "")
+}
+
+// entryCategory возвращает категорию участника; если она не указана, категорией считается дисциплина.
+func entryCategory(e repository.StartListEntry) string {
+	if e.Category != "" {
+		return e.Category
+	}
+	return e.Event
+}
+
+// --- СТАРТОВЫЙ ПРОТОКОЛ ---
+
+// GenerateStartList формирует порядок старта участников дисциплины и,
+// для раздельного старта, рассчитывает время старта каждого участника.
+func (s *StartListService) GenerateStartList(ctx context.Context, competitionID int, params StartListParams) ([]repository.StartListEntry, error) {
+	if competitionID <= 0 {
+		return nil, invalidField("id", CodeInvalid, "некорректный ID соревнования")
+	}
+	var v validator
+	v.OneOf("order", params.Order, StartOrderRandom, StartOrderRanking, StartOrderReverseRanking)
+	v.NotNegative("interval_seconds", float64(params.IntervalSeconds))
+	if params.IntervalSeconds > 0 {
+		v.Check(params.FirstStart != nil, "first_start", CodeRequired, "для раздельного старта необходимо указать время первого старта")
+	}
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+
+	entries, err := s.repo.ListEntries(ctx, competitionID, params.Event)
+	if err != nil {
+		return nil, fmt.Errorf("service: не удалось получить участников: %w", err)
+	}
+
+	// 1. Упорядочиваем участников выбранным способом
+	switch params.Order {
+	case StartOrderRandom:
+		// Разыгрываем перестановку ID записей об участии в объявленной жеребьевке
+		ids := make([]int, len(entries))
+		byID := make(map[int]repository.StartListEntry, len(entries))
+		for i, e := range entries {
+			ids[i] = e.ParticipationID
+			byID[e.ParticipationID] = e
+		}
+		drawn, err := s.draws.Execute(ctx, params.DrawID, competitionID, DrawKindStartOrder, ids, nil)
+		if err != nil {
+			return nil, err
+		}
+		for i, id := range drawn {
+			entries[i] = byID[id]
+		}
+
+	case StartOrderRanking, StartOrderReverseRanking:
+		lowerIsBetter, err := eventDirection(ctx, s.entryRepo, competitionID, params.Event)
+		if err != nil {
+			return nil, err
+		}
+		sortBySeed(entries, lowerIsBetter)
+		if params.Order == StartOrderReverseRanking {
+			slices.Reverse(entries)
+		}
+
+	default:
+		return nil, invalidField("order", CodeEnum, "неизвестный способ формирования порядка '%s'", params.Order)
+	}
+
+	// 2. Проставляем порядковые номера и время старта
+	for i := range entries {
+		entries[i].StartOrder = i + 1
+		entries[i].StartTime = nil
+		if params.FirstStart != nil {
+			start := params.FirstStart.Add(time.Duration(i*params.IntervalSeconds) * time.Second)
+			entries[i].StartTime = &start
+		}
+	}
+
+	if err := s.repo.SaveStartOrder(ctx, competitionID, entries); err != nil {
+		return nil, fmt.Errorf("service: не удалось сохранить порядок старта: %w", err)
+	}
+	return entries, nil
+}
+
+// GetStartList возвращает сохраненный стартовый протокол.
+func (s *StartListService) GetStartList(ctx context.Context, competitionID int, event string) (*repository.Competition, []repository.StartListEntry, error) {
+	competition, err := s.competitionRepo.GetByID(ctx, competitionID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	entries, err := s.repo.ListEntries(ctx, competitionID, event)
+	if err != nil {
+		return nil, nil, fmt.Errorf("service: не удалось получить стартовый протокол: %w", err)
+	}
+	return competition, entries, nil
+}
+
+// sortBySeed сортирует участников от лучшего заявочного результата к худшему.
+// Участники без заявочного результата идут в конце, сохраняя исходный порядок.
+func sortBySeed(entries []repository.StartListEntry, lowerIsBetter bool) {
+	slices.SortStableFunc(entries, func(a, b repository.StartListEntry) int {
+		switch {
+		case a.SeedResult == nil && b.SeedResult == nil:
+			return 0
+		case a.SeedResult == nil:
+			return 1
+		case b.SeedResult == nil:
+			return -1
+		case *a.SeedResult == *b.SeedResult:
+			return 0
+		case meetsThreshold(*a.SeedResult, *b.SeedResult, lowerIsBetter):
+			return -1
+		default:
+			return 1
+		}
+	})
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"sport-manager/internal/auth"
+	"sport-manager/internal/mailer"
+	"sport-manager/internal/repository"
+)
+
+// UserService реализует управление учетными записями: администрирование и личный профиль.
+type UserService struct {
+	repo      *repository.AuthRepository
+	tokenRepo *repository.TokenRepository
+	roles     *RoleService
+	mail      *AccountMailer
+	guard     *LoginGuard
+}
+
+// NewUserService создает новый экземпляр сервиса пользователей.
+func NewUserService(
+	repo *repository.AuthRepository,
+	tokenRepo *repository.TokenRepository,
+	roles *RoleService,
+	mail *AccountMailer,
+	guard *LoginGuard,
+) *UserService {
+	return &UserService{repo: repo, tokenRepo: tokenRepo, roles: roles, mail: mail, guard: guard}
+}
+
+// Profile — данные текущего пользователя вместе с ролями с областью действия.
+type Profile struct {
+	*repository.User
+	Grants []auth.Grant `json:"grants"`
+}
+
+// --- АДМИНИСТРИРОВАНИЕ ---
+
+// List возвращает пользователей, отфильтрованных по имени или email.
+func (s *UserService) List(ctx context.Context, search string) ([]repository.User, error) {
+	return s.repo.List(ctx, strings.TrimSpace(search))
+}
+
+// Get возвращает пользователя по ID.
+func (s *UserService) Get(ctx context.Context, id int) (*repository.User, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// Update меняет email, глобальную роль и активность пользователя; isActive == nil оставляет активность прежней.
+// Администратор не может заблокировать себя или снять с себя роль администратора.
+// Заблокированный пользователь теряет все refresh-токены и не сможет продлить сессию.
+// При смене email адрес перестает считаться подтвержденным, и на него уходит письмо для подтверждения.
+func (s *UserService) Update(ctx context.Context, actor *auth.Claims, id int, email, role string, isActive *bool) error {
+	var v validator
+	checkEmail(&v, email)
+	v.Check(auth.IsValidRole(role), "role", CodeEnum, "неизвестная роль '%s'", role)
+	if err := v.Err(); err != nil {
+		return err
+	}
+
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	emailChanged := !strings.EqualFold(user.Email, email)
+	user.Email = email
+	user.Role = role
+	if isActive != nil {
+		user.IsActive = *isActive
+	}
+	if actor.UserID == user.ID && (user.Role != auth.RoleAdmin || !user.IsActive) {
+		return repository.Conflict("нельзя заблокировать себя или снять с себя права администратора")
+	}
+
+	if err := s.repo.Update(ctx, user); err != nil {
+		return err
+	}
+	if !user.IsActive {
+		if err := s.tokenRepo.RevokeUserTokens(ctx, user.ID); err != nil {
+			return err
+		}
+	}
+	if emailChanged {
+		user.EmailVerified = false
+		if err := s.mail.SendVerification(ctx, user); err != nil {
+			log.Printf("Ошибка отправки письма подтверждения для %s: %v", user.Username, err)
+		}
+	}
+	return nil
+}
+
+// ResetPassword задает пользователю временный пароль, который нужно сменить при следующем входе.
+func (s *UserService) ResetPassword(ctx context.Context, id int, password string) error {
+	hash, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.UpdatePassword(ctx, id, hash, true); err != nil {
+		return err
+	}
+	return s.tokenRepo.RevokeUserTokens(ctx, id)
+}
+
+// Delete удаляет учетную запись. Удалить самого себя нельзя.
+func (s *UserService) Delete(ctx context.Context, actor *auth.Claims, id int) error {
+	if actor.UserID == id {
+		return repository.Conflict("нельзя удалить собственную учетную запись")
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+// Unlock снимает блокировку входа с учетной записи пользователя.
+func (s *UserService) Unlock(ctx context.Context, actor *auth.Claims, ip string, id int) error {
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	return s.guard.Unlock(ctx, actor.Username, ip, UserKey(user.Username))
+}
+
+// ListLockouts возвращает действующие блокировки входа (по логинам и IP-адресам).
+func (s *UserService) ListLockouts(ctx context.Context) ([]repository.LoginFailure, error) {
+	return s.guard.ListLocked(ctx)
+}
+
+// ClearLockout снимает блокировку по ключу, например 'ip:203.0.113.7'.
+func (s *UserService) ClearLockout(ctx context.Context, actor *auth.Claims, ip, key string) error {
+	return s.guard.Unlock(ctx, actor.Username, ip, key)
+}
+
+// --- ЛИЧНЫЙ ПРОФИЛЬ ---
+
+// Me возвращает профиль текущего пользователя.
+func (s *UserService) Me(ctx context.Context, claims *auth.Claims) (*Profile, error) {
+	user, err := s.repo.GetByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	grants, err := s.roles.Grants(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &Profile{User: user, Grants: grants}, nil
+}
+
+// UpdateMe меняет email и язык писем текущего пользователя. Роль и активность сам пользователь не меняет.
+// Новый адрес нужно подтвердить заново: до этого аккаунт ограничен просмотром.
+func (s *UserService) UpdateMe(ctx context.Context, claims *auth.Claims, email, language string) (*Profile, error) {
+	var v validator
+	checkEmail(&v, email)
+	if language != "" {
+		v.OneOf("language", language, mailer.LangRU, mailer.LangEN)
+	}
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+	user, err := s.repo.GetByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	emailChanged := !strings.EqualFold(user.Email, email)
+	user.Email = email
+	user.Language = language
+	if err := s.repo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	profile, err := s.Me(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+	if emailChanged {
+		if err := s.mail.SendVerification(ctx, profile.User); err != nil {
+			log.Printf("Ошибка отправки письма подтверждения для %s: %v", user.Username, err)
+		}
+	}
+	return profile, nil
+}
+
+// validateEmail проверяет адрес электронной почты отдельно от остальных полей.
+func validateEmail(email string) error {
+	var v validator
+	checkEmail(&v, email)
+	return v.Err()
+}
+
+// checkEmail выполняет минимальную проверку адреса электронной почты.
+func checkEmail(v *validator, email string) {
+	at := strings.Index(email, "@")
+	v.Required("email", email)
+	v.Check(at > 0 && at < len(email)-1, "email", CodeInvalid, "некорректный email")
+	v.MaxLen("email", email, 100)
+}
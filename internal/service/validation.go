@@ -0,0 +1,168 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"sport-manager/internal/i18n"
+	"sport-manager/internal/repository"
+)
+
+// Коды правил валидации — стабильные значения поля code в ответе 422.
+const (
+	CodeRequired   = "required"      // Поле обязательно
+	CodeInvalid    = "invalid"       // Неверный тип или формат значения
+	CodeEnum       = "enum"          // Значение не из списка допустимых
+	CodeTooLong    = "too_long"      // Строка длиннее допустимого
+	CodeOutOfRange = "out_of_range"  // Число или дата вне допустимого диапазона
+	CodeReadOnly   = "read_only"     // Поле нельзя изменить
+	CodeUnknown    = "unknown_field" // Такого поля у ресурса нет
+)
+
+// genders — допустимые значения пола спортсмена и категорий ('m' — мужской, 'f' — женский).
+var genders = []string{"m", "f"}
+
+// minBirthDate — самая ранняя допустимая дата рождения спортсмена.
+var minBirthDate = time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// FieldError — нарушение правила для одного поля входных данных.
+type FieldError struct {
+	Field   string `json:"field"`   // Имя поля в JSON запроса
+	Code    string `json:"code"`    // Код правила (CodeRequired, CodeEnum, ...)
+	Message string `json:"message"` // Описание для человека (по-русски; см. Localized)
+
+	format string        // Формат сообщения — ключ каталога i18n
+	args   []interface{} // Аргументы формата
+}
+
+// newFieldError собирает нарушение, запоминая формат сообщения для перевода.
+func newFieldError(field, code, format string, args ...interface{}) FieldError {
+	return FieldError{Field: field, Code: code, Message: i18n.Tf(i18n.LangRU, format, args...), format: format, args: args}
+}
+
+// ValidationError — все нарушения правил во входных данных запроса.
+// Хендлеры отвечают на нее статусом 422 со списком полей.
+type ValidationError struct {
+	Fields []FieldError `json:"fields"`
+}
+
+// Error перечисляет нарушения в одну строку (для логов и старых клиентов).
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		parts = append(parts, f.Field+": "+f.Message)
+	}
+	return "ошибка валидации: " + strings.Join(parts, "; ")
+}
+
+// Localized возвращает нарушения с сообщениями на языке lang.
+func (e *ValidationError) Localized(lang string) []FieldError {
+	fields := make([]FieldError, len(e.Fields))
+	for i, f := range e.Fields {
+		fields[i] = f
+		if f.format != "" {
+			fields[i].Message = i18n.Tf(lang, f.format, f.args...)
+		} else {
+			fields[i].Message = i18n.T(lang, f.Message)
+		}
+	}
+	return fields
+}
+
+// invalidField возвращает ошибку валидации с единственным нарушением.
+func invalidField(field, code, format string, args ...interface{}) error {
+	return &ValidationError{Fields: []FieldError{newFieldError(field, code, format, args...)}}
+}
+
+// referenceError превращает «не найдено» для записи, на которую ссылается поле запроса,
+// в ошибку валидации этого поля: сам запрошенный ресурс при этом существует, и 404 ввел бы клиента в заблуждение.
+func referenceError(err error, field, message string) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return invalidField(field, CodeInvalid, message)
+	}
+	return err
+}
+
+// validator накапливает нарушения, чтобы клиент получил их все в одном ответе.
+// Для каждого поля запоминается только первое нарушение: «обязательно» важнее «слишком длинно».
+type validator struct {
+	errs []FieldError
+}
+
+// Add добавляет нарушение, если у поля его еще нет. Сообщение — формат fmt с аргументами.
+func (v *validator) Add(field, code, format string, args ...interface{}) {
+	if v.Failed(field) {
+		return
+	}
+	v.errs = append(v.errs, newFieldError(field, code, format, args...))
+}
+
+// Failed сообщает, есть ли уже нарушение у поля.
+func (v *validator) Failed(field string) bool {
+	for _, e := range v.errs {
+		if e.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// Check добавляет нарушение, если условие ok не выполнено.
+func (v *validator) Check(ok bool, field, code, format string, args ...interface{}) {
+	if !ok {
+		v.Add(field, code, format, args...)
+	}
+}
+
+// Required проверяет, что строка не пустая (пробелы не считаются).
+func (v *validator) Required(field, value string) {
+	v.Check(strings.TrimSpace(value) != "", field, CodeRequired, "обязательное поле")
+}
+
+// RequiredID проверяет, что передан ID связанной записи.
+func (v *validator) RequiredID(field string, id int) {
+	v.Check(id > 0, field, CodeRequired, "обязательное поле")
+}
+
+// RequiredDate проверяет, что дата передана.
+func (v *validator) RequiredDate(field string, t time.Time) {
+	v.Check(!t.IsZero(), field, CodeRequired, "обязательное поле")
+}
+
+// MaxLen проверяет длину строки в символах (ограничение столбца VARCHAR).
+func (v *validator) MaxLen(field, value string, max int) {
+	v.Check(utf8.RuneCountInString(value) <= max, field, CodeTooLong, "не длиннее %d символов", max)
+}
+
+// OneOf проверяет, что значение входит в список допустимых.
+func (v *validator) OneOf(field, value string, allowed ...string) {
+	v.Check(slices.Contains(allowed, value), field, CodeEnum, "допустимые значения: %s", strings.Join(allowed, ", "))
+}
+
+// DateBetween проверяет, что дата попадает в диапазон [from, to] (по календарным дням).
+func (v *validator) DateBetween(field string, t, from, to time.Time) {
+	day := t.Format("2006-01-02")
+	v.Check(day >= from.Format("2006-01-02") && day <= to.Format("2006-01-02"), field, CodeOutOfRange,
+		"дата должна быть в диапазоне с %s по %s", from.Format("2006-01-02"), to.Format("2006-01-02"))
+}
+
+// NotNegative проверяет, что число не отрицательное.
+func (v *validator) NotNegative(field string, value float64) {
+	v.Check(value >= 0, field, CodeOutOfRange, "не может быть отрицательным")
+}
+
+// Positive проверяет, что число больше нуля.
+func (v *validator) Positive(field string, value float64) {
+	v.Check(value > 0, field, CodeOutOfRange, "должно быть положительным")
+}
+
+// Err возвращает *ValidationError со всеми нарушениями или nil.
+func (v *validator) Err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return &ValidationError{Fields: v.errs}
+}
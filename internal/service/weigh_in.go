@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"sport-manager/internal/repository"
+)
+
+// WeighInService реализует процедуру официального взвешивания между регистрацией и стартом.
+type WeighInService struct {
+	repo              *repository.WeighInRepository
+	participationRepo *repository.ParticipationRepository
+	competitionRepo   *repository.CompetitionRepository
+}
+
+// NewWeighInService создает новый экземпляр сервиса взвешивания.
+func NewWeighInService(
+	repo *repository.WeighInRepository,
+	participationRepo *repository.ParticipationRepository,
+	competitionRepo *repository.CompetitionRepository,
+) *WeighInService {
+	return &WeighInService{repo: repo, participationRepo: participationRepo, competitionRepo: competitionRepo}
+}
+
+// --- ВЕСОВЫЕ КАТЕГОРИИ ---
+
+// ListClasses возвращает весовые категории соревнования.
+func (s *WeighInService) ListClasses(ctx context.Context, competitionID int) ([]repository.WeightClass, error) {
+	if competitionID <= 0 {
+		return nil, invalidField("id", CodeInvalid, "некорректный ID соревнования")
+	}
+	return s.repo.ListClasses(ctx, competitionID)
+}
+
+// CreateClass проверяет и добавляет весовую категорию.
+func (s *WeighInService) CreateClass(ctx context.Context, wc *repository.WeightClass) error {
+	var v validator
+	v.Required("name", wc.Name)
+	v.MaxLen("name", wc.Name, 100)
+	v.Required("gender", wc.Gender)
+	v.OneOf("gender", wc.Gender, genders...)
+	v.NotNegative("min_weight", wc.MinWeight)
+	if wc.MaxWeight != nil {
+		v.Check(*wc.MaxWeight > wc.MinWeight, "max_weight", CodeOutOfRange, "верхняя граница веса должна быть больше нижней")
+	}
+	if err := v.Err(); err != nil {
+		return err
+	}
+	if _, err := s.competitionRepo.GetByID(ctx, wc.CompetitionID); err != nil {
+		return err
+	}
+	return s.repo.CreateClass(ctx, wc)
+}
+
+// DeleteClass удаляет весовую категорию.
+func (s *WeighInService) DeleteClass(ctx context.Context, competitionID, id int) error {
+	if competitionID <= 0 || id <= 0 {
+		return invalidField("id", CodeInvalid, "некорректный ID для удаления")
+	}
+	return s.repo.DeleteClass(ctx, competitionID, id)
+}
+
+// --- ПРАВИЛА ---
+
+// GetRules возвращает правила взвешивания соревнования.
+func (s *WeighInService) GetRules(ctx context.Context, competitionID int) (*repository.WeighInRules, error) {
+	if competitionID <= 0 {
+		return nil, invalidField("id", CodeInvalid, "некорректный ID соревнования")
+	}
+	return s.repo.GetRules(ctx, competitionID)
+}
+
+// SaveRules проверяет и сохраняет правила взвешивания.
+func (s *WeighInService) SaveRules(ctx context.Context, rules *repository.WeighInRules) error {
+	var v validator
+	v.OneOf("overweight_action", rules.OverweightAction, "move", "disqualify")
+	v.NotNegative("tolerance_kg", rules.ToleranceKg)
+	v.NotNegative("reweigh_window_minutes", float64(rules.ReweighWindowMinutes))
+	if err := v.Err(); err != nil {
+		return err
+	}
+	if _, err := s.competitionRepo.GetByID(ctx, rules.CompetitionID); err != nil {
+		return err
+	}
+	return s.repo.SaveRules(ctx, rules)
+}
+
+// --- ВЗВЕШИВАНИЕ ---
+
+// History возвращает протокол взвешиваний участника.
+func (s *WeighInService) History(ctx context.Context, participationID int) ([]repository.WeighIn, error) {
+	if participationID <= 0 {
+		return nil, invalidField("id", CodeInvalid, "некорректный ID записи об участии")
+	}
+	return s.repo.ListWeighIns(ctx, participationID)
+}
+
+// Record фиксирует результат взвешивания и решает судьбу участника по правилам соревнования:
+//   - вес в пределах категории (с учетом допуска) — участник допущен;
+//   - первый перевес при открытом окне перевзвешивания — ожидается повторное взвешивание;
+//   - иначе участник переводится в подходящую категорию ('move') или дисквалифицируется.
+func (s *WeighInService) Record(ctx context.Context, participationID int, weight float64, official, notes string) (*repository.WeighIn, error) {
+	if participationID <= 0 {
+		return nil, invalidField("id", CodeInvalid, "некорректный ID записи об участии")
+	}
+	if weight <= 0 {
+		return nil, invalidField("weight", CodeOutOfRange, "вес должен быть положительным")
+	}
+
+	// 1. Загружаем участника, его категорию, правила и предыдущие попытки
+	p, err := s.participationRepo.GetByID(ctx, participationID)
+	if err != nil {
+		return nil, err
+	}
+	if p.WeightClassID == 0 {
+		return nil, repository.Conflict("участник не заявлен в весовую категорию")
+	}
+	switch p.WeighInStatus {
+	case "passed", "moved", "disqualified":
+		return nil, repository.Conflict("взвешивание участника уже завершено (%s)", p.WeighInStatus)
+	}
+
+	class, err := s.repo.GetClass(ctx, p.WeightClassID)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := s.repo.GetRules(ctx, p.CompetitionID)
+	if err != nil {
+		return nil, err
+	}
+	history, err := s.repo.ListWeighIns(ctx, participationID)
+	if err != nil {
+		return nil, err
+	}
+
+	wi := &repository.WeighIn{
+		ParticipationID: participationID,
+		MeasuredWeight:  weight,
+		Official:        official,
+		Notes:           notes,
+	}
+
+	// 2. Окно перевзвешивания отсчитывается от первой попытки
+	window := time.Duration(rules.ReweighWindowMinutes) * time.Minute
+	late := len(history) > 0 && time.Now().After(history[0].MeasuredAt.Add(window))
+
+	switch {
+	case fitsClass(class, weight, rules.ToleranceKg) && !late:
+		wi.Outcome = "passed"
+
+	case window > 0 && len(history) == 0:
+		wi.Outcome = "reweigh"
+
+	default:
+		// 3. Окончательный перевес: перевод в другую категорию или дисквалификация
+		wi.Outcome = "disqualified"
+		if rules.OverweightAction == "move" && !late {
+			target, err := s.repo.FindClassForWeight(ctx, p.CompetitionID, class.Gender, weight)
+			if err != nil {
+				return nil, err
+			}
+			if target != nil && target.ID != class.ID {
+				wi.Outcome = "moved"
+				wi.WeightClassID = target.ID
+			}
+		}
+	}
+
+	if err := s.repo.RecordWeighIn(ctx, wi, class.ID, len(history)); err != nil {
+		return nil, err
+	}
+	if wi.WeightClassID == 0 {
+		wi.WeightClassID = class.ID
+	}
+	return wi, nil
+}
+
+// fitsClass проверяет попадание веса в категорию; допуск применяется только к верхней границе.
+func fitsClass(class *repository.WeightClass, weight, tolerance float64) bool {
+	if weight <= class.MinWeight {
+		return false
+	}
+	return class.MaxWeight == nil || weight <= *class.MaxWeight+tolerance
+}
@@ -0,0 +1,134 @@
+// Package translit переводит имена между кириллицей и латиницей, чтобы поиск находил
+// «Иванов» по запросу «Ivanov» и наоборот.
+//
+// Кириллица переводится в латиницу по ICAO Doc 9303 (он же ГОСТ Р 52535.1-2006 —
+// так имена пишутся в загранпаспортах). Обратное преобразование неоднозначно, поэтому
+// оно понимает сочетания и ICAO, и ГОСТ 7.79-2000 (система Б), и распространенные
+// бытовые варианты («ya», «yu», «y» в конце слова). Результат нужен для нечеткого поиска,
+// а не для записи в документы: точное совпадение с исходным написанием не гарантируется.
+package translit
+
+import (
+	"strings"
+	"unicode"
+)
+
+// icao — латинские соответствия русских букв по ICAO Doc 9303.
+var icao = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "i", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "ie", 'ы': "y", 'ь': "", 'э': "e", 'ю': "iu",
+	'я': "ia",
+}
+
+// latinDigraphs — сочетания латинских букв, которые читаются одной русской буквой.
+// Проверяются по порядку, поэтому более длинные идут раньше.
+var latinDigraphs = []struct {
+	latin    string
+	cyrillic string
+}{
+	{"shch", "щ"}, {"shh", "щ"},
+	{"zh", "ж"}, {"kh", "х"}, {"ts", "ц"}, {"cz", "ц"}, {"ch", "ч"}, {"sh", "ш"},
+	{"yu", "ю"}, {"iu", "ю"}, {"ya", "я"}, {"ia", "я"}, {"yo", "ё"}, {"ye", "е"},
+}
+
+// latinLetters — соответствия одиночных латинских букв.
+var latinLetters = map[rune]string{
+	'a': "а", 'b': "б", 'c': "к", 'd': "д", 'e': "е", 'f': "ф", 'g': "г", 'h': "х",
+	'i': "и", 'j': "й", 'k': "к", 'l': "л", 'm': "м", 'n': "н", 'o': "о", 'p': "п",
+	'q': "к", 'r': "р", 's': "с", 't': "т", 'u': "у", 'v': "в", 'w': "в", 'x': "кс",
+	'y': "ы", 'z': "з",
+}
+
+// IsCyrillic сообщает, есть ли в строке кириллические буквы.
+func IsCyrillic(s string) bool {
+	for _, r := range s {
+		if unicode.Is(unicode.Cyrillic, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsLatin сообщает, есть ли в строке латинские буквы.
+func IsLatin(s string) bool {
+	for _, r := range s {
+		if r < unicode.MaxASCII && unicode.IsLetter(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// ToLatin переводит кириллицу в латиницу по ICAO; остальные символы не меняются.
+// Регистр первой буквы сохраняется («Щукин» → «Shchukin»).
+func ToLatin(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		latin, ok := icao[unicode.ToLower(r)]
+		if !ok {
+			b.WriteRune(r)
+			continue
+		}
+		if unicode.IsUpper(r) && latin != "" {
+			latin = strings.ToUpper(latin[:1]) + latin[1:]
+		}
+		b.WriteString(latin)
+	}
+	return b.String()
+}
+
+// ToCyrillic переводит латиницу в кириллицу (результат в нижнем регистре).
+// «y» в конце слова или после гласной читается как «й» («Sergey» → «сергей»), иначе как «ы».
+func ToCyrillic(s string) string {
+	lower := []rune(strings.ToLower(s))
+	var b strings.Builder
+	for i := 0; i < len(lower); {
+		if n, cyr := matchDigraph(lower[i:]); n > 0 {
+			b.WriteString(cyr)
+			i += n
+			continue
+		}
+		r := lower[i]
+		cyr, ok := latinLetters[r]
+		switch {
+		case !ok:
+			b.WriteRune(r)
+		case r == 'y' && (i+1 == len(lower) || !unicode.IsLetter(lower[i+1]) || (i > 0 && isVowel(lower[i-1]))):
+			b.WriteString("й")
+		default:
+			b.WriteString(cyr)
+		}
+		i++
+	}
+	return b.String()
+}
+
+// matchDigraph ищет сочетание букв в начале s и возвращает его длину и русскую букву.
+func matchDigraph(s []rune) (int, string) {
+	for _, d := range latinDigraphs {
+		n := len(d.latin)
+		if len(s) >= n && string(s[:n]) == d.latin {
+			return n, d.cyrillic
+		}
+	}
+	return 0, ""
+}
+
+func isVowel(r rune) bool {
+	return strings.ContainsRune("aeiouy", r)
+}
+
+// Variants возвращает варианты написания строки для поиска: саму строку
+// и ее транслитерацию в другой алфавит (если в строке есть буквы, которые можно перевести).
+func Variants(s string) []string {
+	variants := []string{s}
+	if IsCyrillic(s) {
+		variants = append(variants, ToLatin(s))
+	}
+	if IsLatin(s) {
+		variants = append(variants, ToCyrillic(s))
+	}
+	return variants
+}
@@ -0,0 +1,60 @@
+package translit
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestToLatin(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"Щукин", "Shchukin"},
+		{"Юлия", "Iuliia"},
+		{"Ёлкин", "Elkin"},
+		{"Объедков", "Obieedkov"},
+		{"Цой Виктор", "Tsoi Viktor"},
+		{"Ivanov", "Ivanov"},
+	}
+	for _, tt := range tests {
+		if got := ToLatin(tt.in); got != tt.want {
+			t.Errorf("ToLatin(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestToCyrillic(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"Ivanov", "иванов"},
+		{"Shchukin", "щукин"},
+		{"Iuliia", "юлия"},
+		{"Yulia", "юля"},
+		{"Zhukov", "жуков"},
+		{"Khabib", "хабиб"},
+		{"Maxim", "максим"},
+		{"Sergey", "сергей"}, // «y» в конце слова
+		{"Maykop", "майкоп"}, // «y» после гласной
+		{"Ryzhov", "рыжов"},  // «y» между согласными
+		{"Ivanov Petr", "иванов петр"},
+	}
+	for _, tt := range tests {
+		if got := ToCyrillic(tt.in); got != tt.want {
+			t.Errorf("ToCyrillic(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestVariants(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"Иванов", []string{"Иванов", "Ivanov"}},
+		{"Ivanov", []string{"Ivanov", "иванов"}},
+		{"Иванов Ivan", []string{"Иванов Ivan", "Ivanov Ivan", "иванов иван"}},
+		{"2024", []string{"2024"}},
+	}
+	for _, tt := range tests {
+		if got := Variants(tt.in); !slices.Equal(got, tt.want) {
+			t.Errorf("Variants(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+-- Уровень соревнования и вид спорта (нужны для проверки разрядных нормативов)
+ALTER TABLE competitions ADD COLUMN IF NOT EXISTS level VARCHAR(50);
+ALTER TABLE competitions ADD COLUMN IF NOT EXISTS sport_id INT REFERENCES sports(id) ON DELETE SET NULL;
+
+-- Дисциплина, в которой показан результат (например, '100m', 'до 60 кг')
+ALTER TABLE results ADD COLUMN IF NOT EXISTS event VARCHAR(100);
+
+-- Старшинство разряда (чем больше, тем выше) и срок его действия
+ALTER TABLE ranks ADD COLUMN IF NOT EXISTS priority INT NOT NULL DEFAULT 0;
+ALTER TABLE ranks ADD COLUMN IF NOT EXISTS validity_months INT;
+
+-- Таблица: Разрядные нормативы
+-- Норматив выполняется по результату (порог) и/или по занятому месту на соревновании нужного уровня
+CREATE TABLE IF NOT EXISTS rank_standards (
+    id SERIAL PRIMARY KEY,
+    sport_id INT NOT NULL REFERENCES sports(id) ON DELETE CASCADE,
+    event VARCHAR(100) NOT NULL,
+    gender VARCHAR(10) NOT NULL,
+    rank_id INT NOT NULL REFERENCES ranks(id) ON DELETE CASCADE,
+    result_threshold NUMERIC(10, 2),
+    lower_is_better BOOLEAN NOT NULL DEFAULT TRUE, -- TRUE для времени, FALSE для очков/метров
+    max_place INT,
+    competition_level VARCHAR(50),
+
+    CHECK (result_threshold IS NOT NULL OR max_place IS NOT NULL),
+    CHECK (max_place IS NULL OR max_place > 0)
+);
+
+-- Таблица: Предложения о присвоении разряда (ожидают решения администратора)
+CREATE TABLE IF NOT EXISTS rank_proposals (
+    id SERIAL PRIMARY KEY,
+    athlete_id INT NOT NULL REFERENCES athletes(id) ON DELETE CASCADE,
+    rank_id INT NOT NULL REFERENCES ranks(id) ON DELETE CASCADE,
+    standard_id INT REFERENCES rank_standards(id) ON DELETE SET NULL,
+    result_id INT REFERENCES results(id) ON DELETE SET NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending', -- 'pending', 'approved', 'rejected'
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    decided_by VARCHAR(50),
+    decided_at TIMESTAMP WITH TIME ZONE,
+
+    UNIQUE (athlete_id, rank_id, result_id)
+);
+
+-- Таблица: История разрядов спортсмена
+CREATE TABLE IF NOT EXISTS athlete_ranks (
+    id SERIAL PRIMARY KEY,
+    athlete_id INT NOT NULL REFERENCES athletes(id) ON DELETE CASCADE,
+    rank_id INT NOT NULL REFERENCES ranks(id) ON DELETE CASCADE,
+    proposal_id INT REFERENCES rank_proposals(id) ON DELETE SET NULL,
+    result_id INT REFERENCES results(id) ON DELETE SET NULL,
+    awarded_at DATE NOT NULL DEFAULT CURRENT_DATE,
+    expires_at DATE,
+    approved_by VARCHAR(50)
+);
+
+CREATE INDEX IF NOT EXISTS idx_athlete_ranks_athlete ON athlete_ranks (athlete_id, awarded_at DESC);
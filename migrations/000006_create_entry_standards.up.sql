@@ -0,0 +1,21 @@
+-- Таблица: Отборочные (заявочные) нормативы соревнования
+-- Если gender не указан, норматив действует для всех участников дисциплины
+CREATE TABLE IF NOT EXISTS entry_standards (
+    id SERIAL PRIMARY KEY,
+    competition_id INT NOT NULL REFERENCES competitions(id) ON DELETE CASCADE,
+    event VARCHAR(100) NOT NULL,
+    gender VARCHAR(10),
+    result_threshold NUMERIC(10, 2) NOT NULL,
+    lower_is_better BOOLEAN NOT NULL DEFAULT TRUE,
+    qualifying_period_days INT NOT NULL DEFAULT 365, -- Результат должен быть показан за этот период до заявки
+
+    CHECK (qualifying_period_days > 0)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_entry_standards_unique
+    ON entry_standards (competition_id, event, COALESCE(gender, ''));
+
+-- Заявочный результат участника: дисциплина, заявленное значение и признак подтверждения
+ALTER TABLE participations ADD COLUMN IF NOT EXISTS event VARCHAR(100);
+ALTER TABLE participations ADD COLUMN IF NOT EXISTS seed_result NUMERIC(10, 2);
+ALTER TABLE participations ADD COLUMN IF NOT EXISTS seed_verified BOOLEAN NOT NULL DEFAULT FALSE;
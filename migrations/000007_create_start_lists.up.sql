@@ -0,0 +1,39 @@
+-- Таблица-справочник: Клубы (спортивные организации)
+CREATE TABLE IF NOT EXISTS clubs (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) UNIQUE NOT NULL,
+    city VARCHAR(150)
+);
+
+ALTER TABLE athletes ADD COLUMN IF NOT EXISTS club_id INT REFERENCES clubs(id) ON DELETE SET NULL;
+
+-- Категория участника (возрастная группа, весовая категория и т.п.), стартовый номер и порядок старта
+ALTER TABLE participations ADD COLUMN IF NOT EXISTS category VARCHAR(100);
+ALTER TABLE participations ADD COLUMN IF NOT EXISTS bib INT;
+ALTER TABLE participations ADD COLUMN IF NOT EXISTS start_order INT;
+ALTER TABLE participations ADD COLUMN IF NOT EXISTS start_time TIMESTAMP WITH TIME ZONE;
+
+-- Стартовый номер уникален в пределах соревнования
+CREATE UNIQUE INDEX IF NOT EXISTS idx_participations_bib
+    ON participations (competition_id, bib) WHERE bib IS NOT NULL;
+
+-- Таблица: Настройки выдачи стартовых номеров
+CREATE TABLE IF NOT EXISTS bib_settings (
+    competition_id INT PRIMARY KEY REFERENCES competitions(id) ON DELETE CASCADE,
+    mode VARCHAR(20) NOT NULL DEFAULT 'sequential', -- 'sequential', 'random', 'ranges'
+    start_number INT NOT NULL DEFAULT 1,
+
+    CHECK (start_number > 0)
+);
+
+-- Таблица: Диапазоны номеров по категориям (для режима 'ranges')
+CREATE TABLE IF NOT EXISTS bib_ranges (
+    id SERIAL PRIMARY KEY,
+    competition_id INT NOT NULL REFERENCES competitions(id) ON DELETE CASCADE,
+    category VARCHAR(100) NOT NULL,
+    range_start INT NOT NULL,
+    range_end INT NOT NULL,
+
+    UNIQUE (competition_id, category),
+    CHECK (range_start > 0 AND range_end >= range_start)
+);
@@ -0,0 +1,18 @@
+-- Таблица: Жеребьевки (порядок старта, стартовые номера и т.п.)
+-- Хеш зерна публикуется при создании, само зерно раскрывается только после проведения жеребьевки
+CREATE TABLE IF NOT EXISTS draws (
+    id SERIAL PRIMARY KEY,
+    competition_id INT NOT NULL REFERENCES competitions(id) ON DELETE CASCADE,
+    kind VARCHAR(30) NOT NULL, -- 'start_order', 'bib'
+    seed_hash CHAR(64) NOT NULL,
+    seed CHAR(64) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'committed', -- 'committed', 'revealed'
+    items JSONB,    -- Входные данные в каноническом порядке
+    subjects JSONB, -- Кому достаются элементы результата (например, ID записей об участии)
+    result JSONB,   -- Перестановка items
+    created_by VARCHAR(50),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    revealed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_draws_competition ON draws (competition_id, created_at DESC);
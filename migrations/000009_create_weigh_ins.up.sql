@@ -0,0 +1,41 @@
+-- Таблица: Весовые категории соревнования
+CREATE TABLE IF NOT EXISTS weight_classes (
+    id SERIAL PRIMARY KEY,
+    competition_id INT NOT NULL REFERENCES competitions(id) ON DELETE CASCADE,
+    gender VARCHAR(10) NOT NULL,
+    name VARCHAR(100) NOT NULL,          -- Например, 'до 60 кг'
+    min_weight NUMERIC(5, 2) NOT NULL DEFAULT 0,
+    max_weight NUMERIC(5, 2),            -- NULL — абсолютная категория (без верхней границы)
+
+    UNIQUE (competition_id, gender, name),
+    CHECK (max_weight IS NULL OR max_weight > min_weight)
+);
+
+-- Таблица: Правила взвешивания соревнования
+CREATE TABLE IF NOT EXISTS weigh_in_rules (
+    competition_id INT PRIMARY KEY REFERENCES competitions(id) ON DELETE CASCADE,
+    tolerance_kg NUMERIC(4, 2) NOT NULL DEFAULT 0,     -- Допустимое превышение веса
+    reweigh_window_minutes INT NOT NULL DEFAULT 0,      -- 0 — повторное взвешивание не допускается
+    overweight_action VARCHAR(20) NOT NULL DEFAULT 'disqualify', -- 'move' или 'disqualify'
+
+    CHECK (tolerance_kg >= 0 AND reweigh_window_minutes >= 0)
+);
+
+-- Весовая категория участника и итог взвешивания
+ALTER TABLE participations ADD COLUMN IF NOT EXISTS weight_class_id INT REFERENCES weight_classes(id) ON DELETE SET NULL;
+ALTER TABLE participations ADD COLUMN IF NOT EXISTS weigh_in_status VARCHAR(20) NOT NULL DEFAULT 'pending';
+-- 'pending', 'reweigh', 'passed', 'moved', 'disqualified'
+
+-- Таблица: Протокол взвешиваний (каждая попытка сохраняется)
+CREATE TABLE IF NOT EXISTS weigh_ins (
+    id SERIAL PRIMARY KEY,
+    participation_id INT NOT NULL REFERENCES participations(id) ON DELETE CASCADE,
+    weight_class_id INT REFERENCES weight_classes(id) ON DELETE SET NULL,
+    measured_weight NUMERIC(5, 2) NOT NULL,
+    measured_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    official VARCHAR(50) NOT NULL,
+    outcome VARCHAR(20) NOT NULL,
+    notes TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_weigh_ins_participation ON weigh_ins (participation_id, measured_at);
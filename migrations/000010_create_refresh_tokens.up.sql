@@ -0,0 +1,22 @@
+-- Таблица: Refresh-токены (хранится только SHA-256 хеш)
+-- Все токены, полученные ротацией от одного входа, образуют семейство (family_id).
+-- Повторное использование уже обмененного токена отзывает все семейство.
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    family_id CHAR(32) NOT NULL,
+    token_hash CHAR(64) UNIQUE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,    -- Токен обменян на новую пару
+    revoked_at TIMESTAMP WITH TIME ZONE  -- Токен отозван (выход или обнаружено повторное использование)
+);
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family ON refresh_tokens (family_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user ON refresh_tokens (user_id);
+
+-- Таблица: Отозванные access-токены (denylist по jti до истечения их срока)
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    jti CHAR(32) PRIMARY KEY,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
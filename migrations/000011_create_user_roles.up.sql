@@ -0,0 +1,19 @@
+-- Глобальная роль пользователя (код читает users.role, в исходной схеме столбца не было)
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'viewer';
+UPDATE users SET role = 'admin' WHERE username = 'admin';
+-- Роль 'user' заменена ролью 'viewer' с теми же правами
+UPDATE users SET role = 'viewer' WHERE role = 'user';
+ALTER TABLE users ALTER COLUMN role SET DEFAULT 'viewer';
+
+-- Таблица: Роли с областью действия (судья соревнования, тренер клуба и т.д.)
+-- scope_type: '' — глобально, 'competition' или 'club'; scope_id — ID соответствующей записи
+CREATE TABLE IF NOT EXISTS user_roles (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL,
+    scope_type VARCHAR(20) NOT NULL DEFAULT '',
+    scope_id INT NOT NULL DEFAULT 0,
+    granted_by VARCHAR(50),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, role, scope_type, scope_id)
+);
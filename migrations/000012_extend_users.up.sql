@@ -0,0 +1,9 @@
+-- Управление пользователями: блокировка аккаунта и принудительная смена пароля
+ALTER TABLE users ADD COLUMN IF NOT EXISTS is_active BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS must_change_password BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP;
+
+-- Администратор из миграции 000001 со стандартным паролем обязан сменить его при первом входе
+UPDATE users SET must_change_password = TRUE
+WHERE username = 'admin'
+  AND password_hash = '$2a$10$sjFtm14QpMa8F8K3aOt0veJsdCVSfzJG1oy1z9VanAU0JDFxeSSIi';
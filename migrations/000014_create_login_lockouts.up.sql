@@ -0,0 +1,24 @@
+-- Таблица: Счетчики неудачных входов по ключу 'user:<логин>' или 'ip:<адрес>'
+-- Счетчик ведется и для несуществующих логинов, чтобы блокировка не выдавала, кто зарегистрирован.
+CREATE TABLE IF NOT EXISTS login_failures (
+    key VARCHAR(120) PRIMARY KEY,
+    failures INT NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    locked_until TIMESTAMP WITH TIME ZONE
+);
+
+-- Таблица: Журнал аудита (только добавление записей)
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    username VARCHAR(50),        -- Кто выполнил действие (NULL — система или аноним)
+    ip VARCHAR(45),
+    entity VARCHAR(30) NOT NULL, -- Тип сущности: 'user', 'login', ...
+    entity_id INT,
+    action VARCHAR(30) NOT NULL, -- 'lockout', 'unlock', ...
+    before_data JSONB,
+    after_data JSONB
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_entity ON audit_log (entity, entity_id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_created ON audit_log (created_at);
@@ -0,0 +1,17 @@
+-- Таблица: API-ключи для систем хронометража и табло
+-- Ключ показывается один раз при создании; хранится только SHA-256 хеш.
+-- permissions — права ключа, scope_type/scope_id — область их действия (как в user_roles).
+CREATE TABLE IF NOT EXISTS api_keys (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(16) NOT NULL,        -- Начало ключа, чтобы узнать его в списке
+    key_hash CHAR(64) UNIQUE NOT NULL,
+    permissions TEXT[] NOT NULL,
+    scope_type VARCHAR(20) NOT NULL DEFAULT '',
+    scope_id INT NOT NULL DEFAULT 0,
+    created_by VARCHAR(50),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE
+);
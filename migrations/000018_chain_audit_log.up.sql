@@ -0,0 +1,67 @@
+-- Цепочка хешей журнала аудита: каждая запись хранит хеш предыдущей,
+-- поэтому изменение или удаление любой записи в середине журнала обнаруживается проверкой цепочки.
+ALTER TABLE audit_log ADD COLUMN IF NOT EXISTS prev_hash CHAR(64);
+ALTER TABLE audit_log ADD COLUMN IF NOT EXISTS hash CHAR(64);
+
+-- Хеш записи: SHA-256 от канонического текста (время в микросекундах эпохи, JSONB в нормализованном виде)
+CREATE OR REPLACE FUNCTION audit_entry_hash(
+    prev TEXT, created TIMESTAMP WITH TIME ZONE, username TEXT, ip TEXT,
+    entity TEXT, entity_id INT, action TEXT, before_data JSONB, after_data JSONB
+) RETURNS CHAR(64) AS $$
+    SELECT encode(sha256(convert_to(concat_ws('|',
+        COALESCE(prev, ''),
+        (EXTRACT(EPOCH FROM created) * 1000000)::BIGINT,
+        COALESCE(username, ''), COALESCE(ip, ''),
+        entity, COALESCE(entity_id, 0), action,
+        COALESCE(before_data::TEXT, ''), COALESCE(after_data::TEXT, '')
+    ), 'UTF8')), 'hex')
+$$ LANGUAGE SQL STABLE;
+
+-- Хешируем записи, сделанные до появления цепочки
+DO $$
+DECLARE
+    rec RECORD;
+    last_hash CHAR(64);
+BEGIN
+    FOR rec IN SELECT * FROM audit_log WHERE hash IS NULL ORDER BY id LOOP
+        SELECT hash INTO last_hash FROM audit_log WHERE id < rec.id ORDER BY id DESC LIMIT 1;
+        UPDATE audit_log
+        SET prev_hash = last_hash,
+            hash = audit_entry_hash(last_hash, rec.created_at, rec.username, rec.ip,
+                rec.entity, rec.entity_id, rec.action, rec.before_data, rec.after_data)
+        WHERE id = rec.id;
+    END LOOP;
+END $$;
+
+ALTER TABLE audit_log ALTER COLUMN hash SET NOT NULL;
+
+-- Новая запись получает ID и хеш под блокировкой, чтобы параллельные транзакции
+-- выстраивались в одну цепочку в порядке ID. Блокировка держится до конца транзакции.
+CREATE OR REPLACE FUNCTION audit_log_chain() RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_advisory_xact_lock(hashtext('audit_log'));
+    NEW.id := nextval(pg_get_serial_sequence('audit_log', 'id'));
+    NEW.created_at := COALESCE(NEW.created_at, CURRENT_TIMESTAMP);
+    SELECT hash INTO NEW.prev_hash FROM audit_log ORDER BY id DESC LIMIT 1;
+    NEW.hash := audit_entry_hash(NEW.prev_hash, NEW.created_at, NEW.username, NEW.ip,
+        NEW.entity, NEW.entity_id, NEW.action, NEW.before_data, NEW.after_data);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_log_chain ON audit_log;
+CREATE TRIGGER audit_log_chain BEFORE INSERT ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_chain();
+
+-- Журнал только дополняется: изменение и удаление записей запрещены
+CREATE OR REPLACE FUNCTION audit_log_immutable() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'журнал аудита доступен только для добавления записей';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_log_immutable ON audit_log;
+CREATE TRIGGER audit_log_immutable BEFORE UPDATE OR DELETE OR TRUNCATE ON audit_log
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_log_immutable();
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_username ON audit_log (username);
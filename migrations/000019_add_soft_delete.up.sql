@@ -0,0 +1,23 @@
+-- Мягкое удаление: запись помечается временем удаления и скрывается из выборок,
+-- окончательно ее удаляет очистка по истечении срока хранения или явная команда администратора.
+ALTER TABLE athletes ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE competitions ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE participations ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_athletes_deleted ON athletes (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_competitions_deleted ON competitions (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_participations_deleted ON participations (deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- Удаление спортсмена или соревнования больше не уносит участия каскадом:
+-- окончательное удаление сначала явно удаляет участия (вместе с их результатами)
+ALTER TABLE participations DROP CONSTRAINT IF EXISTS participations_athlete_id_fkey;
+ALTER TABLE participations ADD CONSTRAINT participations_athlete_id_fkey
+    FOREIGN KEY (athlete_id) REFERENCES athletes(id) ON DELETE RESTRICT;
+ALTER TABLE participations DROP CONSTRAINT IF EXISTS participations_competition_id_fkey;
+ALTER TABLE participations ADD CONSTRAINT participations_competition_id_fkey
+    FOREIGN KEY (competition_id) REFERENCES competitions(id) ON DELETE RESTRICT;
+
+-- Аннулированная регистрация не мешает заявить спортсмена на соревнование повторно
+ALTER TABLE participations DROP CONSTRAINT IF EXISTS participations_athlete_id_competition_id_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_participations_active
+    ON participations (athlete_id, competition_id) WHERE deleted_at IS NULL;
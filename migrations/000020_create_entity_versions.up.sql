@@ -0,0 +1,25 @@
+-- Таблица: Версии записей (спортсмены и соревнования).
+-- Каждое изменение закрывает текущую версию (valid_to) и добавляет новую со снимком строки целиком,
+-- поэтому можно восстановить состояние записи на любой момент времени.
+CREATE TABLE IF NOT EXISTS entity_versions (
+    entity VARCHAR(30) NOT NULL, -- 'athlete', 'competition'
+    entity_id INT NOT NULL,
+    version INT NOT NULL,
+    data JSONB NOT NULL,         -- Снимок строки таблицы (to_jsonb)
+    changed_by VARCHAR(50),      -- NULL — система или изменение до ведения истории
+    valid_from TIMESTAMP WITH TIME ZONE, -- NULL — версия существовала до начала ведения истории
+    valid_to TIMESTAMP WITH TIME ZONE,   -- NULL — действующая версия
+    PRIMARY KEY (entity, entity_id, version)
+);
+
+CREATE INDEX IF NOT EXISTS idx_entity_versions_current
+    ON entity_versions (entity, entity_id) WHERE valid_to IS NULL;
+
+-- Начальные версии для уже существующих записей
+INSERT INTO entity_versions (entity, entity_id, version, data)
+SELECT 'athlete', a.id, 1, to_jsonb(a) FROM athletes a
+ON CONFLICT DO NOTHING;
+
+INSERT INTO entity_versions (entity, entity_id, version, data)
+SELECT 'competition', c.id, 1, to_jsonb(c) FROM competitions c
+ON CONFLICT DO NOTHING;
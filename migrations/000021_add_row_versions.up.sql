@@ -0,0 +1,24 @@
+-- Номер версии строки для оптимистичной блокировки: ETag ответа и проверка If-Match при изменении.
+-- Триггер увеличивает номер при любом UPDATE, в том числе сделанном в обход репозиториев.
+ALTER TABLE athletes ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
+ALTER TABLE competitions ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
+ALTER TABLE results ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
+
+CREATE OR REPLACE FUNCTION bump_row_version() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.version := OLD.version + 1;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS athletes_version ON athletes;
+CREATE TRIGGER athletes_version BEFORE UPDATE ON athletes
+    FOR EACH ROW EXECUTE FUNCTION bump_row_version();
+
+DROP TRIGGER IF EXISTS competitions_version ON competitions;
+CREATE TRIGGER competitions_version BEFORE UPDATE ON competitions
+    FOR EACH ROW EXECUTE FUNCTION bump_row_version();
+
+DROP TRIGGER IF EXISTS results_version ON results;
+CREATE TRIGGER results_version BEFORE UPDATE ON results
+    FOR EACH ROW EXECUTE FUNCTION bump_row_version();
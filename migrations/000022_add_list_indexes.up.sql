@@ -0,0 +1,10 @@
+-- Индексы для постраничных списков: порядок (ключ сортировки, id) совпадает с ORDER BY выборки,
+-- поэтому продолжение с курсора читает только следующую страницу, а не весь список.
+CREATE INDEX IF NOT EXISTS idx_athletes_full_name_id ON athletes (full_name, id);
+CREATE INDEX IF NOT EXISTS idx_athletes_birth_date_id ON athletes ((COALESCE(birth_date, DATE '0001-01-01')), id);
+CREATE INDEX IF NOT EXISTS idx_competitions_start_date_id ON competitions (start_date, id);
+CREATE INDEX IF NOT EXISTS idx_competitions_name_id ON competitions (name, id);
+
+-- Фильтры списка участий по соревнованию и спортсмену
+CREATE INDEX IF NOT EXISTS idx_participations_competition ON participations (competition_id, id);
+CREATE INDEX IF NOT EXISTS idx_participations_athlete ON participations (athlete_id, id);
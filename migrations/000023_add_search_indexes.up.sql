@@ -0,0 +1,19 @@
+-- Поиск спортсменов, соревнований и клубов: полнотекстовый (префиксы слов для подсказок при вводе)
+-- и нечеткий по триграммам (опечатки, «Иваноф» вместо «Иванов»).
+-- Словарь 'simple' не приводит слова к основе: имена и названия не склоняются в запросах.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_athletes_full_name_trgm ON athletes USING GIN (full_name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_athletes_full_name_fts ON athletes USING GIN (to_tsvector('simple', full_name));
+CREATE INDEX IF NOT EXISTS idx_competitions_name_trgm ON competitions USING GIN (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_competitions_name_fts ON competitions USING GIN (to_tsvector('simple', name));
+CREATE INDEX IF NOT EXISTS idx_clubs_name_trgm ON clubs USING GIN (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_clubs_name_fts ON clubs USING GIN (to_tsvector('simple', name));
+
+-- search_score — релевантность названия для варианта запроса: совпадение префиксов всех слов
+-- весит больше всего, дальше — похожесть запроса на отдельные слова и на название целиком.
+CREATE OR REPLACE FUNCTION search_score(name TEXT, term TEXT, prefixes TSQUERY) RETURNS REAL AS $$
+    SELECT (CASE WHEN to_tsvector('simple', name) @@ prefixes THEN 1 ELSE 0 END)
+         + word_similarity(term, name)
+         + similarity(term, name) / 2
+$$ LANGUAGE SQL IMMUTABLE PARALLEL SAFE;
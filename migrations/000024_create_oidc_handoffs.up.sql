@@ -0,0 +1,10 @@
+-- Таблица: Одноразовые коды завершенного входа через OIDC (хранится только SHA-256 хеш).
+-- Страница входа обменивает код на токены POST-запросом, поэтому токены не попадают в адрес,
+-- историю браузера и заголовок Referer.
+CREATE TABLE IF NOT EXISTS oidc_handoffs (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash CHAR(64) UNIQUE NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE
+);
@@ -21,8 +21,56 @@ type Config struct {
 	HTTPServerPort int `mapstructure:"HTTP_SERVER_PORT"`
 
 	// Параметры безопасности JWT (JSON Web Token)
-	JWTSecret             string        `mapstructure:"JWT_SECRET"`
+	// JWTKeysDir — каталог с PEM-ключами подписи (имя файла — kid), JWTActiveKID — ключ для новых токенов.
+	// JWTExpirationDuration — время жизни короткоживущего access-токена,
+	// RefreshTokenDuration — время жизни refresh-токена, которым access-токен продлевается.
+	JWTKeysDir            string        `mapstructure:"JWT_KEYS_DIR"`
+	JWTActiveKID          string        `mapstructure:"JWT_ACTIVE_KID"`
 	JWTExpirationDuration time.Duration `mapstructure:"JWT_EXPIRATION_DURATION"`
+	RefreshTokenDuration  time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+
+	// Режим сессии веб-интерфейса: 'bearer' — токены возвращаются в теле ответа и хранятся клиентом,
+	// 'cookie' — токены выдаются в HttpOnly-cookie (SameSite=Strict), изменяющие запросы требуют CSRF-токен.
+	// CookieSecure добавляет cookie атрибут Secure (обязательно при работе через HTTPS).
+	SessionMode  string `mapstructure:"SESSION_MODE"`
+	CookieSecure bool   `mapstructure:"COOKIE_SECURE"`
+
+	// Защита от перебора паролей: пороги неудачных входов по логину и по IP,
+	// начальная и максимальная длительность блокировки (каждая следующая неудача удваивает ее)
+	LoginMaxFailures   int           `mapstructure:"LOGIN_MAX_FAILURES"`
+	LoginIPMaxFailures int           `mapstructure:"LOGIN_IP_MAX_FAILURES"`
+	LoginLockoutBase   time.Duration `mapstructure:"LOGIN_LOCKOUT_BASE"`
+	LoginLockoutMax    time.Duration `mapstructure:"LOGIN_LOCKOUT_MAX"`
+
+	// Мягкое удаление: удаленные спортсмены, соревнования и участия хранятся SoftDeleteRetention,
+	// после чего их окончательно удаляет очистка, запускаемая каждые PurgeInterval (0 — очистка отключена)
+	SoftDeleteRetention time.Duration `mapstructure:"SOFT_DELETE_RETENTION"`
+	PurgeInterval       time.Duration `mapstructure:"PURGE_INTERVAL"`
+
+	// Публичный адрес приложения — из него строятся ссылки в письмах
+	AppBaseURL string `mapstructure:"APP_BASE_URL"`
+
+	// Почта: MailDriver — 'smtp' или 'log' (письма пишутся в лог и каталог MailDir)
+	MailDriver   string `mapstructure:"MAIL_DRIVER"`
+	MailDir      string `mapstructure:"MAIL_DIR"`
+	MailFrom     string `mapstructure:"MAIL_FROM"`
+	SMTPHost     string `mapstructure:"SMTP_HOST"`
+	SMTPPort     int    `mapstructure:"SMTP_PORT"`
+	SMTPUsername string `mapstructure:"SMTP_USERNAME"`
+	SMTPPassword string `mapstructure:"SMTP_PASSWORD"`
+
+	// Вход через OpenID Connect (провайдер федерации). Пустой OIDCIssuer отключает вход через OIDC.
+	// OIDCRoleMapping сопоставляет группы провайдера локальным ролям: "federation-admins=admin,judges=judge".
+	// OIDCAutoProvision — создавать учетную запись при первом входе (иначе вход только после привязки).
+	OIDCIssuer        string `mapstructure:"OIDC_ISSUER"`
+	OIDCClientID      string `mapstructure:"OIDC_CLIENT_ID"`
+	OIDCClientSecret  string `mapstructure:"OIDC_CLIENT_SECRET"`
+	OIDCRedirectURL   string `mapstructure:"OIDC_REDIRECT_URL"`
+	OIDCScopes        string `mapstructure:"OIDC_SCOPES"`
+	OIDCGroupsClaim   string `mapstructure:"OIDC_GROUPS_CLAIM"`
+	OIDCRoleMapping   string `mapstructure:"OIDC_ROLE_MAPPING"`
+	OIDCDefaultRole   string `mapstructure:"OIDC_DEFAULT_ROLE"`
+	OIDCAutoProvision bool   `mapstructure:"OIDC_AUTO_PROVISION"`
 }
 
 // LoadConfig инициализирует конфигурацию, соблюдая строгую иерархию приоритетов:
@@ -43,7 +91,33 @@ func LoadConfig() (*Config, error) {
 	viper.SetDefault("DB_HOST", "localhost")
 	viper.SetDefault("DB_PORT", 5432)
 	viper.SetDefault("HTTP_SERVER_PORT", 8080)
-	viper.SetDefault("JWT_EXPIRATION_DURATION", time.Hour*24)
+	viper.SetDefault("JWT_EXPIRATION_DURATION", time.Minute*15)
+	viper.SetDefault("REFRESH_TOKEN_DURATION", time.Hour*24*30)
+	viper.SetDefault("SESSION_MODE", "bearer")
+	viper.SetDefault("COOKIE_SECURE", false)
+	viper.SetDefault("LOGIN_MAX_FAILURES", 5)
+	viper.SetDefault("LOGIN_IP_MAX_FAILURES", 20)
+	viper.SetDefault("LOGIN_LOCKOUT_BASE", time.Minute)
+	viper.SetDefault("LOGIN_LOCKOUT_MAX", time.Hour)
+	viper.SetDefault("SOFT_DELETE_RETENTION", time.Hour*24*90)
+	viper.SetDefault("PURGE_INTERVAL", time.Hour*24)
+	viper.SetDefault("APP_BASE_URL", "http://localhost:8080")
+	viper.SetDefault("MAIL_DRIVER", "log")
+	viper.SetDefault("MAIL_DIR", "mail")
+	viper.SetDefault("MAIL_FROM", "Sport Manager <no-reply@sportmanager.local>")
+	viper.SetDefault("SMTP_PORT", 587)
+	// Пустые значения по умолчанию регистрируют ключи, иначе Unmarshal не увидит их в переменных окружения
+	for _, key := range []string{
+		"JWT_KEYS_DIR", "JWT_ACTIVE_KID",
+		"SMTP_HOST", "SMTP_USERNAME", "SMTP_PASSWORD",
+		"OIDC_ISSUER", "OIDC_CLIENT_ID", "OIDC_CLIENT_SECRET", "OIDC_REDIRECT_URL", "OIDC_ROLE_MAPPING",
+	} {
+		viper.SetDefault(key, "")
+	}
+	viper.SetDefault("OIDC_SCOPES", "openid email profile")
+	viper.SetDefault("OIDC_GROUPS_CLAIM", "groups")
+	viper.SetDefault("OIDC_DEFAULT_ROLE", "viewer")
+	viper.SetDefault("OIDC_AUTO_PROVISION", true)
 
 	// Попытка чтения файла app.env
 	if err := viper.ReadInConfig(); err != nil {
@@ -0,0 +1,44 @@
+// Общие функции авторизации для страниц веб-интерфейса.
+// Режим bearer: access-токен хранится в localStorage и передается в заголовке Authorization.
+// Режим cookie: токены лежат в HttpOnly-cookie (JavaScript их не видит), а изменяющие запросы
+// дублируют CSRF-токен из cookie sm_csrf в заголовке X-CSRF-Token.
+
+function csrfToken() {
+    const cookie = document.cookie.split('; ').find(c => c.startsWith('sm_csrf='));
+    return cookie ? decodeURIComponent(cookie.substring('sm_csrf='.length)) : '';
+}
+
+function isLoggedIn() {
+    return !!localStorage.getItem('token') || !!csrfToken();
+}
+
+function authHeaders(extra) {
+    const headers = Object.assign({ 'Content-Type': 'application/json' }, extra);
+    const token = localStorage.getItem('token');
+    if (token) headers['Authorization'] = 'Bearer ' + token;
+    const csrf = csrfToken();
+    if (csrf) headers['X-CSRF-Token'] = csrf;
+    return headers;
+}
+
+// errorText возвращает текст ошибки из ответа API (RFC 7807: поле detail); для 422 — нарушения по полям построчно
+async function errorText(res, fallback) {
+    try {
+        const data = await res.json();
+        if (data.fields) return data.fields.map(f => `${f.field}: ${f.message}`).join('\n');
+        return data.detail || fallback;
+    } catch (e) {
+        return fallback;
+    }
+}
+
+// saveSession сохраняет результат входа: в режиме cookie токенов в ответе нет
+function saveSession(data) {
+    if (data.access_token) {
+        localStorage.setItem('token', data.access_token);
+        if (data.refresh_token) localStorage.setItem('refresh_token', data.refresh_token);
+    } else {
+        localStorage.removeItem('token');
+        localStorage.removeItem('refresh_token');
+    }
+}